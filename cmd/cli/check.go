@@ -0,0 +1,84 @@
+package main
+
+import (
+	"booster/internal/task"
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+type CheckCmd struct {
+	Profile  string   `help:"Profile to use (required when profiles defined in config)"`
+	Tags     []string `help:"Only check tasks with any of these tags"`
+	SkipTags []string `help:"Skip tasks with any of these tags"`
+}
+
+func (c *CheckCmd) Run(cli *CLI) error {
+	graph, redactor, err := buildGraph(cli.Config, selection{
+		Profile:  c.Profile,
+		Tags:     c.Tags,
+		SkipTags: c.SkipTags,
+	}, variableOptions{Identity: cli.Identity, NonInteractive: !isTerminal(os.Stdin)})
+	if err != nil {
+		return err
+	}
+
+	tasks := graph.Tasks()
+	if len(tasks) == 0 {
+		fmt.Println("No tasks to check")
+		return nil
+	}
+
+	out := redactor.Writer(os.Stdout)
+	report := checkTasks(context.Background(), out, tasks)
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	return report.err()
+}
+
+type checkReport struct {
+	inSync  int
+	drifted int
+	errored int
+}
+
+func (r checkReport) err() error {
+	switch {
+	case r.drifted > 0 && r.errored > 0:
+		return fmt.Errorf("%d task(s) out of sync, %d could not be checked", r.drifted, r.errored)
+	case r.drifted > 0:
+		return fmt.Errorf("%d task(s) out of sync", r.drifted)
+	case r.errored > 0:
+		return fmt.Errorf("%d task(s) could not be checked", r.errored)
+	}
+	return nil
+}
+
+func checkTasks(ctx context.Context, w io.Writer, tasks []task.Task) checkReport {
+	var report checkReport
+	for _, t := range tasks {
+		result := task.Check(ctx, t)
+		switch {
+		case result.Error != nil:
+			report.errored++
+			fmt.Fprintf(w, "✗ %s\n    error: %v\n", t.Name(), result.Error)
+		case result.Drifted():
+			report.drifted++
+			fmt.Fprintf(w, "~ %s\n", t.Name())
+			for _, change := range result.Changes {
+				fmt.Fprintf(w, "    %s\n", change)
+			}
+		case result.Message != "":
+			report.inSync++
+			fmt.Fprintf(w, "- %s (%s)\n", t.Name(), result.Message)
+		default:
+			report.inSync++
+			fmt.Fprintf(w, "✓ %s\n", t.Name())
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d in sync, %d out of sync, %d failed to check\n", report.inSync, report.drifted, report.errored)
+	return report
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"booster/internal/task"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTasks_ReportsDrift(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing")
+	require.NoError(t, os.Mkdir(existing, 0o755))
+	missing := filepath.Join(dir, "missing")
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	var out bytes.Buffer
+	report := checkTasks(context.Background(), &out, []task.Task{
+		&task.DirCreate{Path: existing},
+		&task.DirCreate{Path: missing},
+		&task.DirCreate{Path: file},
+	})
+
+	assert.Equal(t, checkReport{inSync: 1, drifted: 1, errored: 1}, report)
+	assert.Contains(t, out.String(), "✓ create "+existing)
+	assert.Contains(t, out.String(), "~ create "+missing+"\n    create directory "+missing)
+	assert.Contains(t, out.String(), "✗ create "+file+"\n    error: path exists but is not a directory")
+	assert.Contains(t, out.String(), "1 in sync, 1 out of sync, 1 failed to check")
+	assert.NoDirExists(t, missing)
+}
+
+func TestCheckCmd_ExitStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    func(dir string) string
+		wantErr string
+	}{
+		{
+			name: "in sync",
+			path: func(dir string) string { return dir },
+		},
+		{
+			name:    "drift returns an error",
+			path:    func(dir string) string { return filepath.Join(dir, "missing") },
+			wantErr: "1 task(s) out of sync",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			content := "version: \"1\"\ntasks:\n  - action: dir.create\n    args:\n      - " + tt.path(dir) + "\n"
+			cli, _ := setupTestConfig(t, content)
+
+			err := (&CheckCmd{}).Run(cli)
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func TestCheckReport_Err(t *testing.T) {
+	assert.NoError(t, checkReport{inSync: 3}.err())
+	assert.EqualError(t, checkReport{drifted: 2}.err(), "2 task(s) out of sync")
+	assert.EqualError(t, checkReport{errored: 1}.err(), "1 task(s) could not be checked")
+	assert.EqualError(t, checkReport{drifted: 1, errored: 1}.err(), "1 task(s) out of sync, 1 could not be checked")
+}
+
+func TestCheckCmd_TagsLeaveOutFilteredTasks(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	content := "version: \"1\"\ntasks:\n  - action: dir.create\n    tags: [dotfiles]\n    args:\n      - " + missing + "\n"
+	cli, _ := setupTestConfig(t, content)
+
+	assert.NoError(t, (&CheckCmd{Tags: []string{"packages"}}).Run(cli))
+	assert.NoError(t, (&CheckCmd{SkipTags: []string{"dotfiles"}}).Run(cli))
+	assert.Error(t, (&CheckCmd{Tags: []string{"dotfiles"}}).Run(cli))
+}
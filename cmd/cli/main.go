@@ -1,21 +1,36 @@
 package main
 
 import (
+	"booster/internal/backup"
 	"booster/internal/cmdexec"
 	"booster/internal/condition"
 	"booster/internal/config"
+	"booster/internal/executor"
+	"booster/internal/expr"
+	"booster/internal/journal"
+	"booster/internal/pathutil"
+	"booster/internal/redact"
 	"booster/internal/task"
 	"booster/internal/tui"
 	"booster/internal/variable"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -25,125 +40,447 @@ var (
 )
 
 type CLI struct {
-	Config  string     `help:"Path to config file" default:"./bootstrap.yaml" type:"path"`
-	Run     RunCmd     `cmd:"" default:"withargs" help:"Run bootstrap tasks (default)"`
-	Version VersionCmd `cmd:"" help:"Show version information"`
+	Config   string      `help:"Path to config file" default:"./bootstrap.yaml" type:"path"`
+	Identity string      `help:"age identity file to encrypt secret variables with, instead of a passphrase" type:"path" env:"BOOSTER_IDENTITY"`
+	Run      RunCmd      `cmd:"" default:"withargs" help:"Run bootstrap tasks (default)"`
+	Check    CheckCmd    `cmd:"" help:"Report tasks that would change something, without changing anything"`
+	Rollback RollbackCmd `cmd:"" help:"Restore the files changed by a run"`
+	Validate ValidateCmd `cmd:"" help:"Check the config for errors without running anything"`
+	Schema   SchemaCmd   `cmd:"" help:"Print the JSON Schema of the config file"`
+	Migrate  MigrateCmd  `cmd:"" help:"Rewrite a version 1 config as version 2"`
+	Vars     VarsCmd     `cmd:"" help:"List and change the stored values of variables"`
+	Version  VersionCmd  `cmd:"" help:"Show version information"`
 }
 
 type RunCmd struct {
-	DryRun  bool   `help:"Show what would be done without executing"`
-	Profile string `help:"Profile to use (required when profiles defined in config)"`
+	DryRun    bool          `help:"Show what would be done without executing"`
+	Profile   string        `help:"Profile to use (required when profiles defined in config)"`
+	Jobs      int           `help:"Number of independent tasks to run in parallel" short:"j" default:"1"`
+	KeepGoing bool          `help:"Keep running tasks that do not depend on a failed task"`
+	Resume    bool          `help:"Skip tasks that succeeded in the previous run and have not changed since"`
+	Timeout   time.Duration `help:"Default timeout for tasks that do not set their own (e.g. 10m)"`
+	Diff      bool          `help:"Show a unified diff of every file a task would change"`
+	Tags      []string      `help:"Only run tasks with any of these tags"`
+	SkipTags  []string      `help:"Skip tasks with any of these tags"`
+
+	Set            map[string]string `help:"Set a variable for this run, without storing it" placeholder:"NAME=VALUE" mapsep:"none"`
+	VarsFile       string            `help:"YAML file of variable values for this run, overridden by --set" type:"existingfile"`
+	NonInteractive bool              `help:"Fail with every missing value instead of prompting; implied when stdin is not a terminal" env:"BOOSTER_NON_INTERACTIVE"`
 }
 
 func (c *RunCmd) Run(cli *CLI) error {
-	cfg, err := config.Load(cli.Config)
-	if err != nil {
-		return fmt.Errorf("load config: %w", err)
+	if c.Jobs < 0 {
+		return fmt.Errorf("--jobs must not be negative, got %d", c.Jobs)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative, got %s", c.Timeout)
+	}
+
+	opts := variableOptions{
+		Identity:       cli.Identity,
+		Set:            c.Set,
+		NonInteractive: c.NonInteractive || !isTerminal(os.Stdin),
+	}
+	if c.VarsFile != "" {
+		values, err := loadVarsFile(c.VarsFile)
+		if err != nil {
+			return err
+		}
+		opts.File = values
 	}
 
-	profile, err := validateProfile(cfg.Profiles, c.Profile)
+	graph, redactor, err := buildGraph(cli.Config, selection{
+		Profile:  c.Profile,
+		Tags:     c.Tags,
+		SkipTags: c.SkipTags,
+	}, opts)
 	if err != nil {
 		return err
 	}
+	tasks := graph.Tasks()
+
+	if len(tasks) == 0 {
+		fmt.Println("No tasks to run")
+		return nil
+	}
+
+	if c.DryRun {
+		out := redactor.Writer(os.Stdout)
+		printPlan(out, tasks, c.Diff)
+		return out.Flush()
+	}
+
+	if task.AnyNeedsSudo(tasks) {
+		if sudoErr := ensureSudo(); sudoErr != nil {
+			return fmt.Errorf("sudo required: %w", sudoErr)
+		}
+	}
+
+	journalPath := defaultJournalPath(cli.Config)
+	var previous *journal.Run
+	if c.Resume {
+		previous, err = journal.Load(journalPath)
+		if err != nil {
+			return fmt.Errorf("load run journal: %w", err)
+		}
+	}
+	runJournal := journal.New(journalPath, time.Now())
+
+	runner := executor.NewGraph(graph,
+		executor.WithJobs(c.Jobs),
+		executor.WithKeepGoing(c.KeepGoing),
+		executor.WithTimeout(c.Timeout),
+		executor.WithDiff(c.Diff),
+		executor.WithJournal(runJournal),
+		executor.WithRedactor(redactor),
+		executor.WithBackup(backup.New(defaultBackupRoot(cli.Config), runJournal.ID())),
+		executor.WithResume(previous),
+	)
+	model := tui.NewWithExecutor(runner).WithRedactor(redactor)
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
-	vars, err := resolveVariables(cfg.Variables)
+	final, err := p.Run()
+	if m, ok := final.(tui.Model); ok {
+		m.Close()
+	} else {
+		model.Close()
+	}
+	// Closing the model cancels tasks still running when the UI was quit;
+	// wait for them so their processes are gone before we exit.
+	runner.Wait()
 	if err != nil {
-		return fmt.Errorf("resolve variables: %w", err)
+		return fmt.Errorf("TUI error: %w", err)
+	}
+
+	if err := runJournal.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: write run journal: %v\n", err)
 	}
 
+	return nil
+}
+
+func printPlan(w io.Writer, tasks []task.Task, showDiff bool) {
+	fmt.Fprintf(w, "Would execute %d task(s):\n\n", len(tasks))
+	for i, t := range tasks {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, t.Name())
+		if !showDiff {
+			continue
+		}
+		diff, err := task.Diff(t)
+		if err != nil {
+			fmt.Fprintf(w, "     diff unavailable: %v\n", err)
+		} else if diff != "" {
+			fmt.Fprintf(w, "\n%s\n", diff)
+		}
+	}
+}
+
+type selection struct {
+	Profile  string
+	Tags     []string
+	SkipTags []string
+}
+
+type variableOptions struct {
+	Identity string
+	// Every name in Set must be a variable of the config; File may hold values
+	// for other configs too.
+	Set            map[string]string
+	File           map[string]any
+	NonInteractive bool
+}
+
+func (o variableOptions) values() map[string]any {
+	values := maps.Clone(o.File)
+	if values == nil {
+		values = make(map[string]any)
+	}
+	for name, value := range o.Set {
+		values[name] = value
+	}
+	return values
+}
+
+// buildGraph also returns a redactor for the values of secret variables.
+func buildGraph(configPath string, sel selection, opts variableOptions) (*task.Graph, *redact.Redactor, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+
+	profile, err := validateProfile(cfg.Profiles, sel.Profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(opts.Set)) {
+		if _, ok := cfg.Variables[name]; !ok {
+			return nil, nil, fmt.Errorf("--set %s: variable is not defined in the config", name)
+		}
+	}
+
+	defs := variableDefinitions(cfg.Variables)
+	vars, err := resolveVariables(defs, configStores(cfg, configPath), opts)
+	var missing *variable.MissingError
+	if err != nil && !errors.As(err, &missing) {
+		return nil, nil, fmt.Errorf("resolve variables: %w", err)
+	}
+	if missing != nil {
+		err = fmt.Errorf("resolve variables: %w; set them with --set or --vars-file, or in the environment", err)
+		// The tasks are still built, to report the git config keys they lack
+		// along with the variables.
+		for _, def := range defs {
+			if _, ok := vars[def.Name]; !ok {
+				vars[def.Name], _ = def.DefaultValue()
+			}
+		}
+	}
+	redactor := secretRedactor(defs, vars)
+
 	detector := &condition.SystemDetector{}
 	sysCtx := detector.Detect()
 	sysCtx.Profile = profile
+	sysCtx.Tags = sel.Tags
+	sysCtx.SkipTags = sel.SkipTags
+
+	var prompter task.Prompter
+	if !opts.NonInteractive {
+		prompter = tui.NewHuhPrompter()
+	}
+	builder := newBuilder(sysCtx, vars, filepath.Dir(configPath), prompter)
+	graph, buildErr := builder.BuildGraph(cfg.Tasks)
+	if missing != nil {
+		if buildErr == nil && prompter == nil {
+			err = errors.Join(err, unsetGitConfig(graph))
+		}
+		return nil, nil, redactor.Error(err)
+	}
+	if buildErr != nil {
+		return nil, nil, redactor.Error(fmt.Errorf("build tasks: %w", buildErr))
+	}
+	if prompter == nil {
+		if err := unsetGitConfig(graph); err != nil {
+			return nil, nil, redactor.Error(err)
+		}
+	}
+	return graph, redactor, nil
+}
 
-	configDir := filepath.Dir(cli.Config)
+// Without a prompter, git config keys with no value fail before anything runs
+// rather than when their task does.
+func unsetGitConfig(graph *task.Graph) error {
+	keys := task.UnsetPrompts(context.Background(), graph.Tasks())
+	if len(keys) == 0 {
+		return nil
+	}
+	return fmt.Errorf("no value for git config %s and cannot prompt; set them with git config --global", strings.Join(keys, ", "))
+}
 
-	builder := task.DefaultBuilder(sysCtx)
+// Tasks that ask for values use prompter, which is nil when they may not.
+func newBuilder(sysCtx condition.Context, vars map[string]any, configDir string, prompter task.Prompter) *task.Builder {
+	builder := task.DefaultBuilder(sysCtx).WithExprContext(exprContext(sysCtx, vars))
 	builder.Register("template.render", task.NewTemplateRenderFactory(task.TemplateRenderConfig{
 		Vars:    vars,
 		OS:      sysCtx.OS,
 		Profile: sysCtx.Profile,
-	}))
-	builder.Register("pkg-manager.install", task.NewPkgManagerInstallFactory(nil))
-	builder.Register("pkg.install", task.NewPkgInstallFactory(task.PkgInstallConfig{
-		OS: sysCtx.OS,
-	}))
-	builder.Register("mise.use", task.NewMiseUseFactory(task.MiseUseConfig{}))
+	})).Describe("template.render", task.TemplateRenderSchema())
+	builder.Register("pkg-manager.install", task.NewPkgManagerInstallFactory(nil)).
+		Describe("pkg-manager.install", task.PkgManagerInstallSchema()).
+		Declare("pkg-manager.install", task.PkgManagerInstallNeeds())
+	pkgInstall := task.PkgInstallConfig{OS: sysCtx.OS}
+	builder.Register("pkg.install", task.NewPkgInstallFactory(pkgInstall)).
+		Describe("pkg.install", task.PkgInstallSchema()).
+		Declare("pkg.install", task.PkgInstallNeeds(pkgInstall))
+	builder.Register("mise.use", task.NewMiseUseFactory(task.MiseUseConfig{})).
+		Describe("mise.use", task.MiseUseSchema()).
+		Declare("mise.use", task.MiseUseNeeds())
 	builder.Register("git.config", task.NewGitConfig(
 		cmdexec.DefaultRunner(),
-		tui.NewHuhPrompter(),
-	))
+		prompter,
+	)).Describe("git.config", task.GitConfigSchema()).
+		Declare("git.config", task.GitConfigNeeds())
 	builder.Register("set.darwin.defaults", task.NewDarwinDefaultsFactory(task.DarwinDefaultsConfig{
 		OS:        sysCtx.OS,
 		ConfigDir: configDir,
-	}))
+	})).Describe("set.darwin.defaults", task.DarwinDefaultsSchema())
+	return builder
+}
 
-	tasks, err := builder.Build(cfg.Tasks)
-	if err != nil {
-		return fmt.Errorf("build tasks: %w", err)
+func exprContext(sysCtx condition.Context, vars map[string]any) *expr.Context {
+	ctx := expr.NewContext().WithProfile(sysCtx.Profile).WithVars(maps.Clone(vars))
+	ctx.OS = sysCtx.OS
+	return ctx
+}
+
+func resolveVariables(defs []variable.Definition, stores variableStores, opts variableOptions) (map[string]any, error) {
+	if len(defs) == 0 {
+		return make(map[string]any), nil
 	}
 
-	if len(tasks) == 0 {
-		fmt.Println("No tasks to run")
-		return nil
+	return newResolver(stores, opts).Resolve(defs)
+}
+
+func newResolver(stores variableStores, opts variableOptions) *variable.Resolver {
+	resolverOpts := []variable.ResolverOption{
+		variable.WithValues(opts.values()),
+		variable.WithSecretStore(variable.NewSecretStore(stores.Secrets, secretKey(opts))),
+		variable.WithLegacyStore(variable.NewFileStore(legacyValuesPath())),
 	}
+	if !opts.NonInteractive {
+		resolverOpts = append(resolverOpts, variable.WithCollector(tui.NewPromptCollector()))
+	}
+	return variable.NewResolver(variable.NewFileStore(stores.Values), resolverOpts...)
+}
 
-	if c.DryRun {
-		fmt.Printf("Would execute %d task(s):\n\n", len(tasks))
-		for i, t := range tasks {
-			fmt.Printf("  %d. %s\n", i+1, t.Name())
-		}
-		return nil
+func loadVarsFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read vars file: %w", err)
 	}
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parse vars file %s: %w", path, err)
+	}
+	return values, nil
+}
 
-	if task.AnyNeedsSudo(tasks) {
-		if sudoErr := ensureSudo(); sudoErr != nil {
-			return fmt.Errorf("sudo required: %w", sudoErr)
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+type variableStores struct {
+	Values  string
+	Secrets string
+}
+
+// configStores uses the file named by the config's store field, relative to
+// the config, or else files under the data home named after the config's
+// name, or its path if it has none.
+func configStores(cfg *config.Config, configPath string) variableStores {
+	if cfg.Store != "" {
+		path := pathutil.Expand(cfg.Store)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(cfg.Files[0].Path), path)
+		}
+		return variableStores{
+			Values:  path,
+			Secrets: strings.TrimSuffix(path, filepath.Ext(path)) + ".age",
 		}
 	}
 
-	model := tui.New(tasks)
-	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	key := cfg.Name
+	if key == "" {
+		key = configKey(configPath)
+	}
+	return variableStores{
+		Values:  filepath.Join(dataHome(), "cli", "values", key+".yaml"),
+		Secrets: filepath.Join(dataHome(), "cli", "secrets", key+".age"),
+	}
+}
 
-	if _, err := p.Run(); err != nil {
-		return fmt.Errorf("TUI error: %w", err)
+func secretKey(opts variableOptions) variable.SecretKey {
+	if opts.Identity != "" {
+		return variable.NewIdentityKey(opts.Identity)
 	}
+	return variable.NewPassphraseKey(func() (string, error) {
+		if passphrase := os.Getenv("BOOSTER_PASSPHRASE"); passphrase != "" {
+			return passphrase, nil
+		}
+		if opts.NonInteractive {
+			return "", errors.New("no passphrase for secret variables: set BOOSTER_PASSPHRASE or use --identity")
+		}
+		return tui.NewHuhPrompter().PromptSecret(context.Background(), "Passphrase for secret variables")
+	})
+}
 
-	return nil
+func secretRedactor(defs []variable.Definition, vars map[string]any) *redact.Redactor {
+	var secrets []string
+	for _, def := range defs {
+		if !def.Secret {
+			continue
+		}
+		secrets = appendSecrets(secrets, vars[def.Name])
+	}
+	return redact.New(secrets...)
 }
 
-func resolveVariables(cfgVars map[string]config.VariableDef) (map[string]string, error) {
-	if len(cfgVars) == 0 {
-		return make(map[string]string), nil
+func appendSecrets(secrets []string, v any) []string {
+	switch v := v.(type) {
+	case string:
+		return append(secrets, v)
+	case []string:
+		return append(secrets, v...)
+	case []any:
+		for _, item := range v {
+			secrets = appendSecrets(secrets, item)
+		}
 	}
+	return secrets
+}
 
-	var defs []variable.Definition
-	for name, v := range cfgVars {
+// Variables are sorted by name, so they are prompted for in a stable order.
+func variableDefinitions(cfgVars map[string]config.VariableDef) []variable.Definition {
+	defs := make([]variable.Definition, 0, len(cfgVars))
+	for _, name := range slices.Sorted(maps.Keys(cfgVars)) {
+		v := cfgVars[name]
 		defs = append(defs, variable.Definition{
 			Name:    name,
 			Prompt:  v.Prompt,
+			Type:    variable.Type(v.Type),
 			Default: v.Default,
+			Options: v.Options,
+			Pattern: v.Pattern,
+			Min:     v.Min,
+			Max:     v.Max,
+			Secret:  v.Secret,
 		})
 	}
+	return defs
+}
 
-	storePath := defaultValuesPath()
-	store := variable.NewFileStore(storePath)
+func defaultJournalPath(configPath string) string {
+	return filepath.Join(stateHome(), "cli", "journal", configKey(configPath)+".yaml")
+}
 
-	collector := tui.NewPromptCollector()
-	resolver := variable.NewResolver(store, variable.WithCollector(collector))
+func defaultBackupRoot(configPath string) string {
+	return filepath.Join(stateHome(), "cli", "backups", configKey(configPath))
+}
 
-	return resolver.Resolve(defs)
+func configKey(configPath string) string {
+	abs, err := filepath.Abs(configPath)
+	if err != nil {
+		abs = configPath
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return hex.EncodeToString(sum[:8])
 }
 
-func defaultValuesPath() string {
-	dataHome := os.Getenv("XDG_DATA_HOME")
-	if dataHome == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			home = "."
-		}
-		dataHome = filepath.Join(home, ".local", "share")
+func stateHome() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".local", "state")
+}
+
+// legacyValuesPath is the store shared by every config before values were
+// kept per config.
+func legacyValuesPath() string {
+	return filepath.Join(dataHome(), "cli", "values.yaml")
+}
+
+func dataHome() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
 	}
-	return filepath.Join(dataHome, "cli", "values.yaml")
+	return filepath.Join(home, ".local", "share")
 }
 
 type VersionCmd struct{}
@@ -203,7 +540,7 @@ func main() {
 	cli := CLI{}
 	ctx := kong.Parse(&cli,
 		kong.Name("cli"),
-		kong.Description("Bootstrap your machine from YAML config"),
+		kong.Description("Bootstrap your machine from a YAML, TOML or JSON config"),
 		kong.UsageOnError(),
 	)
 
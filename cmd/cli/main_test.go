@@ -1,8 +1,14 @@
 package main
 
 import (
+	"booster/internal/config"
+	"booster/internal/redact"
+	"booster/internal/task"
+	"booster/internal/variable"
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -73,7 +79,7 @@ tasks:
 		},
 		{
 			name: "unsupported version",
-			content: `version: "2"
+			content: `version: "3"
 tasks: []
 `,
 			errContain: "load config",
@@ -516,3 +522,286 @@ tasks:
 
 	require.NoError(t, err)
 }
+
+func TestBuildGraph_ResolvesArgExpressions(t *testing.T) {
+	content := `version: "1"
+profiles:
+  - work
+tasks:
+  - action: dir.create
+    args:
+      - /tmp/${ profile }/${ os }
+`
+	cli, _ := setupTestConfig(t, content)
+
+	graph, _, err := buildGraph(cli.Config, selection{Profile: "work"}, variableOptions{})
+
+	require.NoError(t, err)
+	require.Equal(t, 1, graph.Len())
+	assert.Contains(t, graph.Tasks()[0].Name(), "/tmp/work/")
+	assert.NotContains(t, graph.Tasks()[0].Name(), "${")
+}
+
+func TestBuildGraph_InvalidArgExpression(t *testing.T) {
+	content := `version: "1"
+tasks:
+  - action: dir.create
+    args:
+      - ~/ok
+  - action: dir.create
+    args:
+      - ~/${ 1 + }
+`
+	cli, _ := setupTestConfig(t, content)
+
+	_, _, err := buildGraph(cli.Config, selection{}, variableOptions{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task 2 (dir.create)")
+	assert.Contains(t, err.Error(), "invalid expression")
+}
+
+func TestDefaultJournalPath_PerConfigUnderStateHome(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+
+	a := defaultJournalPath("/configs/a/bootstrap.yaml")
+	b := defaultJournalPath("/configs/b/bootstrap.yaml")
+
+	assert.Equal(t, filepath.Join(state, "cli", "journal"), filepath.Dir(a))
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, a, defaultJournalPath("/configs/a/bootstrap.yaml"))
+}
+
+func TestPrintPlan_ShowsDiffForFileWritingTasks(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "gitconfig.tmpl")
+	target := filepath.Join(dir, "gitconfig")
+	require.NoError(t, os.WriteFile(source, []byte("[user]\n  name = New\n"), 0o644))
+	require.NoError(t, os.WriteFile(target, []byte("[user]\n  name = Old\n"), 0o644))
+	tasks := []task.Task{
+		&task.DirCreate{Path: dir},
+		&task.TemplateRender{Source: source, Target: target},
+	}
+
+	var plain, withDiff bytes.Buffer
+	printPlan(&plain, tasks, false)
+	printPlan(&withDiff, tasks, true)
+
+	assert.NotContains(t, plain.String(), "name = Old")
+	assert.Contains(t, withDiff.String(), "  2. render gitconfig.tmpl → gitconfig")
+	assert.Contains(t, withDiff.String(), "-  name = Old\n+  name = New\n")
+}
+
+func TestBuildGraph_TypedVariablesInExpressions(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("Debug", "true")
+	t.Setenv("Workers", "3")
+
+	content := `version: "1"
+variables:
+  Debug:
+    type: bool
+  Workers:
+    type: int
+    max: 8
+tasks:
+  - action: dir.create
+    when: "${ vars.Debug && vars.Workers > 2 }"
+    args:
+      - /tmp/workers-${ vars.Workers + 1 }
+`
+	cli, _ := setupTestConfig(t, content)
+
+	graph, _, err := buildGraph(cli.Config, selection{}, variableOptions{})
+
+	require.NoError(t, err)
+	require.Equal(t, 1, graph.Len())
+	assert.Contains(t, graph.Tasks()[0].Name(), "/tmp/workers-4")
+}
+
+func TestBuildGraph_RedactsSecretVariables(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("Token", "hunter2")
+
+	content := `version: "1"
+variables:
+  Token:
+    secret: true
+tasks:
+  - action: dir.create
+    args:
+      - /tmp/${ vars.Token }
+`
+	cli, _ := setupTestConfig(t, content)
+
+	graph, redactor, err := buildGraph(cli.Config, selection{}, variableOptions{})
+
+	require.NoError(t, err)
+	name := graph.Tasks()[0].Name()
+	assert.Contains(t, name, "/tmp/hunter2", "tasks get the real value")
+	assert.Equal(t, strings.ReplaceAll(name, "hunter2", redact.Mask), redactor.String(name))
+}
+
+func TestSecretRedactor(t *testing.T) {
+	defs := []variable.Definition{
+		{Name: "Name"},
+		{Name: "Token", Secret: true},
+		{Name: "Keys", Type: variable.TypeList, Secret: true},
+		{Name: "Hosts", Type: variable.TypeList, Secret: true},
+	}
+	vars := map[string]any{"Name": "alice", "Token": "t0ken", "Keys": []any{"k1", "k2"}, "Hosts": []string{"h1"}}
+
+	r := secretRedactor(defs, vars)
+
+	mask := redact.Mask
+	assert.Equal(t, "alice "+mask+" "+mask+" "+mask+" "+mask, r.String("alice t0ken k1 k2 h1"))
+	assert.Nil(t, secretRedactor(defs[:1], vars), "no secrets needs no redactor")
+}
+
+func TestConfigStores(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "bootstrap.yaml")
+	root := config.Position{Path: configPath}
+
+	byPath := configStores(&config.Config{Files: []config.Position{root}}, configPath)
+	assert.Equal(t, variableStores{
+		Values:  filepath.Join(data, "cli", "values", configKey(configPath)+".yaml"),
+		Secrets: filepath.Join(data, "cli", "secrets", configKey(configPath)+".age"),
+	}, byPath)
+
+	other := configStores(&config.Config{}, filepath.Join(t.TempDir(), "bootstrap.yaml"))
+	assert.NotEqual(t, byPath, other, "configs at different paths do not share values")
+
+	byName := configStores(&config.Config{Name: "dotfiles", Files: []config.Position{root}}, configPath)
+	assert.Equal(t, variableStores{
+		Values:  filepath.Join(data, "cli", "values", "dotfiles.yaml"),
+		Secrets: filepath.Join(data, "cli", "secrets", "dotfiles.age"),
+	}, byName)
+
+	explicit := configStores(&config.Config{Name: "dotfiles", Store: ".booster/values.yaml", Files: []config.Position{root}}, configPath)
+	assert.Equal(t, variableStores{
+		Values:  filepath.Join(dir, ".booster", "values.yaml"),
+		Secrets: filepath.Join(dir, ".booster", "values.age"),
+	}, explicit)
+}
+
+func TestBuildGraph_MigratesLegacyValues(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	legacy := variable.NewFileStore(filepath.Join(data, "cli", "values.yaml"))
+	require.NoError(t, legacy.Save(map[string]any{"Dir": "from-legacy"}))
+
+	content := `version: "1"
+name: dotfiles
+variables:
+  Dir:
+    prompt: Directory
+tasks:
+  - action: dir.create
+    args: ["/tmp/${ vars.Dir }"]
+`
+	cli, _ := setupTestConfig(t, content)
+
+	graph, _, err := buildGraph(cli.Config, selection{}, variableOptions{})
+
+	require.NoError(t, err)
+	assert.Contains(t, graph.Tasks()[0].Name(), "/tmp/from-legacy")
+	migrated, err := variable.NewFileStore(filepath.Join(data, "cli", "values", "dotfiles.yaml")).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Dir": "from-legacy"}, migrated)
+}
+
+func TestBuildGraph_NonInteractive(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	content := `version: "1"
+variables:
+  Name:
+    prompt: Name
+  Email:
+    prompt: Email
+  Editor:
+    default: vim
+  Workers:
+    type: int
+tasks:
+  - action: dir.create
+    args: ["/tmp/${ vars.Name }-${ vars.Email }-${ vars.Editor }-${ vars.Workers }"]
+`
+	cli, _ := setupTestConfig(t, content)
+	varsFile := filepath.Join(t.TempDir(), "vars.yaml")
+	require.NoError(t, os.WriteFile(varsFile, []byte("Name: file\nWorkers: 2\nOther: x\n"), 0o644))
+	fileValues, err := loadVarsFile(varsFile)
+	require.NoError(t, err)
+
+	_, _, err = buildGraph(cli.Config, selection{}, variableOptions{NonInteractive: true})
+	assert.EqualError(t, err, "resolve variables: no value for Email, Name, Workers; set them with --set or --vars-file, or in the environment")
+
+	_, _, err = buildGraph(cli.Config, selection{}, variableOptions{NonInteractive: true, Set: map[string]string{"Emial": "x"}})
+	assert.EqualError(t, err, "--set Emial: variable is not defined in the config")
+
+	graph, _, err := buildGraph(cli.Config, selection{}, variableOptions{
+		NonInteractive: true,
+		File:           fileValues,
+		Set:            map[string]string{"Name": "set", "Email": "a@b.c"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, graph.Tasks()[0].Name(), "/tmp/set-a@b.c-vim-2")
+}
+
+func TestSecretKey_NonInteractiveNeedsPassphrase(t *testing.T) {
+	t.Setenv("BOOSTER_PASSPHRASE", "")
+
+	_, err := secretKey(variableOptions{NonInteractive: true}).Identity()
+
+	assert.EqualError(t, err, "no passphrase for secret variables: set BOOSTER_PASSPHRASE or use --identity")
+}
+
+func TestBuildGraph_NonInteractiveReportsUnsetGitConfig(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	globalConfig := filepath.Join(t.TempDir(), "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", globalConfig)
+	cli, _ := setupTestConfig(t, `version: "1"
+variables:
+  Email:
+    prompt: Email
+tasks:
+  - action: git.config
+    args:
+      - key: user.name
+        prompt: Name?
+      - key: user.email
+        value: ${ vars.Email }
+`)
+
+	_, _, err := buildGraph(cli.Config, selection{}, variableOptions{NonInteractive: true})
+	assert.EqualError(t, err, "resolve variables: no value for Email; set them with --set or --vars-file, or in the environment\n"+
+		"no value for git config user.name and cannot prompt; set them with git config --global")
+
+	opts := variableOptions{NonInteractive: true, Set: map[string]string{"Email": "a@b.c"}}
+	_, _, err = buildGraph(cli.Config, selection{}, opts)
+	assert.EqualError(t, err, "no value for git config user.name and cannot prompt; set them with git config --global")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("[user]\n\tname = Alice\n"), 0o644))
+	graph, _, err := buildGraph(cli.Config, selection{}, opts)
+
+	require.NoError(t, err)
+	gitConfig, ok := graph.Tasks()[0].(*task.GitConfig)
+	require.True(t, ok)
+	assert.Nil(t, gitConfig.Prompter)
+}
+
+func TestBuildGraph_EscapedExpressionIsLiteral(t *testing.T) {
+	cli, _ := setupTestConfig(t, `version: "1"
+tasks:
+  - action: dir.create
+    args: ["/tmp/$${HOME}"]
+`)
+
+	graph, _, err := buildGraph(cli.Config, selection{}, variableOptions{})
+
+	require.NoError(t, err)
+	assert.Contains(t, graph.Tasks()[0].Name(), "/tmp/${HOME}")
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"booster/internal/config"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type MigrateCmd struct {
+	Write bool `help:"Rewrite the files in place instead of printing them"`
+}
+
+func (c *MigrateCmd) Run(cli *CLI) error {
+	cfg, err := config.Load(cli.Config)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	return migrateFiles(os.Stdout, cfg.Files, c.Write)
+}
+
+// migrateFiles migrates every file before writing any, so a file that fails
+// to migrate leaves them all untouched.
+func migrateFiles(w io.Writer, files []config.Position, write bool) error {
+	migrated := make([][]byte, len(files))
+	for i, file := range files {
+		if ext := strings.ToLower(filepath.Ext(file.Path)); ext == ".toml" || ext == ".json" {
+			return fmt.Errorf("migrate %s: only YAML files can be migrated", file.File)
+		}
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file.File, err)
+		}
+
+		migrated[i], err = config.Migrate(data)
+		if i == 0 && errors.Is(err, config.ErrAlreadyMigrated) {
+			// Included files without a version are version 2 as well.
+			fmt.Fprintf(w, "# %s is already version 2\n", file.File)
+			return nil
+		}
+		if err != nil && !errors.Is(err, config.ErrAlreadyMigrated) {
+			return fmt.Errorf("migrate %s: %w", file.File, err)
+		}
+	}
+
+	for i, file := range files {
+		switch {
+		case migrated[i] == nil:
+			fmt.Fprintf(w, "# %s is already version 2\n", file.File)
+		case !write:
+			fmt.Fprintf(w, "# %s\n%s", file.File, migrated[i])
+		default:
+			info, err := os.Stat(file.Path)
+			if err != nil {
+				return fmt.Errorf("write %s: %w", file.File, err)
+			}
+			if err := os.WriteFile(file.Path, migrated[i], info.Mode().Perm()); err != nil {
+				return fmt.Errorf("write %s: %w", file.File, err)
+			}
+			fmt.Fprintf(w, "migrated %s\n", file.File)
+		}
+	}
+	return nil
+}
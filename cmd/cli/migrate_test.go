@@ -0,0 +1,82 @@
+package main
+
+import (
+	"booster/internal/config"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateCmd_WritesEveryFile(t *testing.T) {
+	cli, _ := setupTestConfig(t, "version: \"1\"\ninclude: more.yaml\ntasks:\n  - action: dir.create\n    args: [a]\n")
+	more := filepath.Join(filepath.Dir(cli.Config), "more.yaml")
+	require.NoError(t, os.WriteFile(more, []byte("tasks:\n  - action: dir.create\n    args: [b]\n"), 0o600))
+
+	require.NoError(t, (&MigrateCmd{Write: true}).Run(cli))
+
+	root, err := os.ReadFile(cli.Config)
+	require.NoError(t, err)
+	assert.Equal(t, "version: \"2\"\ninclude: more.yaml\ntasks:\n  - dir.create: [a]\n", string(root))
+
+	included, err := os.ReadFile(more)
+	require.NoError(t, err)
+	assert.Equal(t, "tasks:\n  - dir.create: [b]\n", string(included))
+
+	info, err := os.Stat(more)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// The migrated config builds the same tasks.
+	graph, _, err := buildGraph(cli.Config, selection{}, variableOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create a", "create b"}, []string{graph.Tasks()[0].Name(), graph.Tasks()[1].Name()})
+}
+
+func TestMigrateFiles_PrintsWithoutWrite(t *testing.T) {
+	content := "version: \"1\"\ntasks:\n  - action: dir.create\n    args: [a]\n"
+	cli, _ := setupTestConfig(t, content)
+
+	cfgFiles := loadFiles(t, cli.Config)
+	var out bytes.Buffer
+	require.NoError(t, migrateFiles(&out, cfgFiles, false))
+
+	assert.Equal(t, "# bootstrap.yaml\nversion: \"2\"\ntasks:\n  - dir.create: [a]\n", out.String())
+	unchanged, err := os.ReadFile(cli.Config)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(unchanged))
+}
+
+func TestMigrateFiles_AlreadyMigrated(t *testing.T) {
+	cli, _ := setupTestConfig(t, "version: \"2\"\ninclude: more.yaml\ntasks: []\n")
+	more := filepath.Join(filepath.Dir(cli.Config), "more.yaml")
+	require.NoError(t, os.WriteFile(more, []byte("tasks:\n  - dir.create: [b]\n"), 0o644))
+
+	var out bytes.Buffer
+	require.NoError(t, migrateFiles(&out, loadFiles(t, cli.Config), true))
+
+	assert.Equal(t, "# bootstrap.yaml is already version 2\n", out.String())
+}
+
+func loadFiles(t *testing.T, path string) []config.Position {
+	t.Helper()
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	return cfg.Files
+}
+
+func TestMigrateFiles_RejectsOtherFormats(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bootstrap.toml")
+	require.NoError(t, os.WriteFile(path, []byte("version = \"1\"\n"), 0o644))
+
+	var out bytes.Buffer
+	err := migrateFiles(&out, []config.Position{{Path: path, File: "bootstrap.toml"}}, true)
+
+	assert.EqualError(t, err, "migrate bootstrap.toml: only YAML files can be migrated")
+	assert.Empty(t, out.String())
+}
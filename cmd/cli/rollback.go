@@ -0,0 +1,44 @@
+package main
+
+import (
+	"booster/internal/backup"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type RollbackCmd struct {
+	RunID string `arg:"" optional:"" help:"Run to roll back (defaults to the most recent run)"`
+	Force bool   `help:"Roll back a run again even if it was already rolled back"`
+}
+
+func (c *RollbackCmd) Run(cli *CLI) error {
+	root := defaultBackupRoot(cli.Config)
+
+	runID := c.RunID
+	if runID == "" {
+		latest, err := backup.Latest(root)
+		if err != nil {
+			return err
+		}
+		runID = latest
+	}
+
+	restored, err := backup.Rollback(root, runID, time.Now(), c.Force)
+	for _, e := range restored {
+		if e.Kind == backup.KindMissing {
+			fmt.Printf("removed  %s\n", e.Path)
+		} else {
+			fmt.Printf("restored %s\n", e.Path)
+		}
+	}
+	if errors.Is(err, backup.ErrRolledBack) {
+		return fmt.Errorf("%w; use --force to restore its backups again", err)
+	}
+	if err != nil {
+		return fmt.Errorf("roll back run %s: %w", runID, err)
+	}
+
+	fmt.Printf("\nRolled back %d change(s) from run %s\n", len(restored), runID)
+	return nil
+}
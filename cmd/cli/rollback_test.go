@@ -0,0 +1,72 @@
+package main
+
+import (
+	"booster/internal/backup"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackCmd_RestoresLatestRun(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cli, _ := setupTestConfig(t, "version: \"1\"\ntasks: []\n")
+	target := filepath.Join(t.TempDir(), "gitconfig")
+	require.NoError(t, os.WriteFile(target, []byte("before"), 0o644))
+
+	b := backup.New(defaultBackupRoot(cli.Config), "20260301T100000.000Z")
+	require.NoError(t, b.Save(target))
+	require.NoError(t, os.WriteFile(target, []byte("after"), 0o644))
+
+	require.NoError(t, (&RollbackCmd{}).Run(cli))
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "before", string(content))
+
+	err = (&RollbackCmd{}).Run(cli)
+	assert.EqualError(t, err, "no run to roll back")
+}
+
+func TestRollbackCmd_RolledBackRunNeedsForce(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cli, _ := setupTestConfig(t, "version: \"1\"\ntasks: []\n")
+	target := filepath.Join(t.TempDir(), "gitconfig")
+	require.NoError(t, os.WriteFile(target, []byte("before"), 0o644))
+
+	runID := "20260301T100000.000Z"
+	require.NoError(t, backup.New(defaultBackupRoot(cli.Config), runID).Save(target))
+	require.NoError(t, (&RollbackCmd{RunID: runID}).Run(cli))
+	require.NoError(t, os.WriteFile(target, []byte("edited"), 0o644))
+
+	err := (&RollbackCmd{RunID: runID}).Run(cli)
+	require.ErrorIs(t, err, backup.ErrRolledBack)
+	assert.Contains(t, err.Error(), "--force")
+
+	require.NoError(t, (&RollbackCmd{RunID: runID, Force: true}).Run(cli))
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "before", string(content))
+}
+
+func TestRollbackCmd_UnknownRun(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cli, _ := setupTestConfig(t, "version: \"1\"\ntasks: []\n")
+
+	err := (&RollbackCmd{RunID: "20260101T000000.000Z"}).Run(cli)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no backups for run 20260101T000000.000Z")
+}
+
+func TestDefaultBackupRoot_PerConfigUnderStateHome(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+
+	a := defaultBackupRoot("/configs/a/bootstrap.yaml")
+
+	assert.Equal(t, filepath.Join(state, "cli", "backups"), filepath.Dir(a))
+	assert.NotEqual(t, a, defaultBackupRoot("/configs/b/bootstrap.yaml"))
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"booster/internal/condition"
+	"booster/internal/config"
+	"encoding/json"
+	"fmt"
+)
+
+type SchemaCmd struct{}
+
+func (c *SchemaCmd) Run(cli *CLI) error {
+	data, err := configSchema()
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+func configSchema() ([]byte, error) {
+	builder := newBuilder(condition.Context{}, nil, ".", nil)
+	data, err := json.MarshalIndent(config.JSONSchema(builder.ArgSchemas()), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"booster/internal/condition"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaJSON_UpToDate(t *testing.T) {
+	want, err := configSchema()
+	require.NoError(t, err)
+
+	got, err := os.ReadFile("../../schema.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(want), string(got), "schema.json is stale, run: make schema")
+}
+
+func TestNewBuilder_DescribesEveryAction(t *testing.T) {
+	for action, args := range newBuilder(condition.Context{}, nil, ".", nil).ArgSchemas() {
+		assert.NotNil(t, args, "action %s has no args schema", action)
+	}
+}
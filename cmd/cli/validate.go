@@ -0,0 +1,148 @@
+package main
+
+import (
+	"booster/internal/condition"
+	"booster/internal/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+type ValidateCmd struct {
+	Format string `help:"Output format: text or json" enum:"text,json" default:"text"`
+}
+
+func (c *ValidateCmd) Run(cli *CLI) error {
+	data, err := configSchema()
+	if err != nil {
+		return err
+	}
+	schema, err := config.CompileSchema(data)
+	if err != nil {
+		return err
+	}
+
+	problems := validateConfig(cli.Config, schema)
+	if c.Format == "json" {
+		if err := printProblemsJSON(os.Stdout, problems); err != nil {
+			return err
+		}
+	} else {
+		printProblems(os.Stdout, problems)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("config has %d problem(s)", len(problems))
+	}
+	return nil
+}
+
+func validateConfig(path string, schema *config.Schema) []error {
+	cfg, err := config.Load(path)
+	if err != nil {
+		// A file that does not decode usually breaks the schema too, which
+		// tells more precisely what is wrong and where.
+		root := config.Position{Path: path, File: filepath.Base(path)}
+		if errs, serr := schema.Validate(root); serr == nil && len(errs) > 0 {
+			return errs
+		}
+		return []error{err}
+	}
+
+	var problems []error
+	for _, file := range cfg.Files {
+		errs, err := schema.Validate(file)
+		if err != nil {
+			return append(problems, err)
+		}
+		problems = append(problems, errs...)
+	}
+
+	for _, ct := range cfg.Tasks {
+		if ct.When == nil {
+			continue
+		}
+		for _, p := range ct.When.Profile {
+			if !slices.Contains(cfg.Profiles, p) {
+				problems = append(problems, config.ErrorAt(ct.Pos("when"), fmt.Errorf("task %d: when references undeclared profile %q", ct.Origin.Index, p)))
+			}
+		}
+	}
+
+	// Variables are not resolved, so nothing is prompted for; tasks only
+	// need to know which variables exist and their types.
+	vars := make(map[string]any, len(cfg.Variables))
+	for _, def := range variableDefinitions(cfg.Variables) {
+		if err := def.Check(); err != nil {
+			problems = append(problems, fmt.Errorf("variable %s: %w", def.Name, err))
+			continue
+		}
+		vars[def.Name], _ = def.DefaultValue()
+	}
+	sysCtx := (&condition.SystemDetector{}).Detect()
+	builder := newBuilder(sysCtx, vars, filepath.Dir(path), nil)
+
+	// Bad args often break both the schema and the factory; report each
+	// spot once, with the schema's message.
+	for _, err := range builder.Validate(cfg.Tasks) {
+		var perr *config.PositionError
+		if errors.As(err, &perr) && slices.ContainsFunc(problems, func(p error) bool {
+			var seen *config.PositionError
+			return errors.As(p, &seen) && seen.Pos == perr.Pos
+		}) {
+			continue
+		}
+		problems = append(problems, err)
+	}
+	return problems
+}
+
+func printProblems(w io.Writer, problems []error) {
+	if len(problems) == 0 {
+		fmt.Fprintln(w, "✓ config is valid")
+		return
+	}
+	for _, p := range problems {
+		fmt.Fprintf(w, "✗ %v\n\n", p)
+	}
+	fmt.Fprintf(w, "%d problem(s) found\n", len(problems))
+}
+
+type problem struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func printProblemsJSON(w io.Writer, problems []error) error {
+	report := struct {
+		Valid    bool      `json:"valid"`
+		Problems []problem `json:"problems"`
+	}{
+		Valid:    len(problems) == 0,
+		Problems: make([]problem, 0, len(problems)),
+	}
+
+	for _, err := range problems {
+		var perr *config.PositionError
+		if errors.As(err, &perr) {
+			report.Problems = append(report.Problems, problem{
+				File:    perr.Pos.Path,
+				Line:    perr.Pos.Line,
+				Column:  perr.Pos.Column,
+				Message: perr.Err.Error(),
+			})
+			continue
+		}
+		report.Problems = append(report.Problems, problem{Message: err.Error()})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
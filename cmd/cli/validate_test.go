@@ -0,0 +1,245 @@
+package main
+
+import (
+	"booster/internal/config"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSchema(t *testing.T) *config.Schema {
+	t.Helper()
+
+	data, err := configSchema()
+	require.NoError(t, err)
+	schema, err := config.CompileSchema(data)
+	require.NoError(t, err)
+	return schema
+}
+
+func TestValidateConfig_Valid(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "gitconfig.tmpl")
+	require.NoError(t, os.WriteFile(source, []byte("{{ .Vars.Email }}"), 0o644))
+
+	content := `version: "1"
+profiles: [work]
+variables:
+  Email:
+    prompt: "Email?"
+tasks:
+  - action: template.render
+    when:
+      profile: work
+    args:
+      - source: ` + source + `
+        target: ` + filepath.Join(dir, "out") + `
+`
+	cli, _ := setupTestConfig(t, content)
+
+	assert.Empty(t, validateConfig(cli.Config, testSchema(t)))
+}
+
+func TestValidateConfig_ReportsEveryProblem(t *testing.T) {
+	content := `version: "1"
+profiles: [work]
+tasks:
+  - action: symlink.create
+    when:
+      profile: home
+    args:
+      - source: /nonexistent/source
+        target: /tmp/target
+  - action: dir.create
+    retry: 2
+    args: [a]
+  - action: mise.use
+    args: [go]
+`
+	cli, _ := setupTestConfig(t, content)
+
+	problems := validateConfig(cli.Config, testSchema(t))
+
+	var messages []string
+	for _, p := range problems {
+		var perr *config.PositionError
+		require.ErrorAs(t, p, &perr)
+		messages = append(messages, perr.Pos.String()+": "+perr.Err.Error())
+	}
+	assert.ElementsMatch(t, []string{
+		`bootstrap.yaml:11:5: unknown field "retry"`,
+		`bootstrap.yaml:14:12: 'go' does not match pattern '^[^@]+@.+$'`,
+		`bootstrap.yaml:6:7: task 1: when references undeclared profile "home"`,
+		`bootstrap.yaml:8:7: task 1 (symlink.create): source does not exist: /nonexistent/source`,
+	}, messages)
+}
+
+func TestValidateConfig_LoadErrorFallsBackToSchema(t *testing.T) {
+	cli, _ := setupTestConfig(t, "version: \"1\"\ntasks:\n  - action: dir.create\n    retries: many\n    args: [a]\n")
+
+	problems := validateConfig(cli.Config, testSchema(t))
+
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0].Error(), "bootstrap.yaml:4:14: got string, want integer")
+}
+
+func TestValidateCmd_ExitStatus(t *testing.T) {
+	cli, _ := setupTestConfig(t, "version: \"1\"\ntasks:\n  - action: nope\n")
+
+	err := (&ValidateCmd{Format: "text"}).Run(cli)
+
+	assert.EqualError(t, err, "config has 1 problem(s)")
+}
+
+func TestPrintProblemsJSON(t *testing.T) {
+	cli, _ := setupTestConfig(t, "version: \"1\"\ntasks:\n  - action: dir.create\n    args: [1]\n")
+	problems := validateConfig(cli.Config, testSchema(t))
+
+	var out bytes.Buffer
+	require.NoError(t, printProblemsJSON(&out, problems))
+
+	var report struct {
+		Valid    bool      `json:"valid"`
+		Problems []problem `json:"problems"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.False(t, report.Valid)
+	require.Len(t, report.Problems, 1)
+	assert.Equal(t, problem{
+		File:    cli.Config,
+		Line:    4,
+		Column:  12,
+		Message: "got number, want string",
+	}, report.Problems[0])
+}
+
+func TestPrintProblems_Valid(t *testing.T) {
+	var out bytes.Buffer
+	printProblems(&out, nil)
+
+	assert.Equal(t, "✓ config is valid\n", out.String())
+}
+
+func TestValidateConfig_Version2(t *testing.T) {
+	content := `version: "2"
+tasks:
+  - name: dirs
+    dir.create: [a]
+  - mise.use: [go]
+    dir.create: [b]
+  - symlink.create: oops
+    when: "${ true }"
+`
+	cli, _ := setupTestConfig(t, content)
+	schema := testSchema(t)
+
+	// Load stops at the string when, so the schema reports what it can.
+	problems := validateConfig(cli.Config, schema)
+
+	var messages []string
+	for _, p := range problems {
+		var perr *config.PositionError
+		require.ErrorAs(t, p, &perr)
+		messages = append(messages, perr.Pos.String()+": "+perr.Err.Error())
+	}
+	assert.ElementsMatch(t, []string{
+		"bootstrap.yaml:5:5: value does not match any allowed form",
+		"bootstrap.yaml:5:16: 'go' does not match pattern '^[^@]+@.+$'",
+		"bootstrap.yaml:7:21: got string, want array",
+		"bootstrap.yaml:8:11: got string, want object",
+	}, messages)
+}
+
+func TestValidateConfig_ArgsGivenAsExpression(t *testing.T) {
+	variables := `variables:
+  Dirs:
+    type: list
+    default: [a, b]
+  Tool:
+    default: go@1.22
+`
+	configs := map[string]string{
+		"version 1": `version: "1"
+` + variables + `tasks:
+  - action: dir.create
+    args: "${ map(vars.Dirs, '/tmp/x/' + #) }"
+  - action: mise.use
+    args: ["${ vars.Tool }"]
+`,
+		"version 2": `version: "2"
+` + variables + `tasks:
+  - dir.create: "${ map(vars.Dirs, '/tmp/x/' + #) }"
+  - mise.use: "${ [vars.Tool] }"
+`,
+	}
+
+	for name, content := range configs {
+		t.Run(name, func(t *testing.T) {
+			cli, _ := setupTestConfig(t, content)
+
+			assert.Empty(t, validateConfig(cli.Config, testSchema(t)))
+		})
+	}
+}
+
+func TestValidateConfig_TOML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bootstrap.toml")
+	content := `version = "1"
+
+[[tasks]]
+action = "dir.create"
+args = ["a"]
+retry = 2
+
+[[tasks]]
+action = "mise.use"
+args = ["go"]
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	problems := validateConfig(path, testSchema(t))
+
+	var messages []string
+	for _, p := range problems {
+		var perr *config.PositionError
+		require.ErrorAs(t, p, &perr)
+		messages = append(messages, perr.Pos.String()+": "+perr.Err.Error())
+	}
+	assert.ElementsMatch(t, []string{
+		`bootstrap.toml:6:1: unknown field "retry"`,
+		`bootstrap.toml:10:9: 'go' does not match pattern '^[^@]+@.+$'`,
+	}, messages)
+}
+
+func TestValidateConfig_VariableDefinitions(t *testing.T) {
+	content := `version: "1"
+variables:
+  Shell:
+    type: choice
+    options: [zsh, fish]
+    default: bash
+  Workers:
+    type: int
+    min: 4
+    max: 2
+tasks: []
+`
+	cli, _ := setupTestConfig(t, content)
+
+	problems := validateConfig(cli.Config, testSchema(t))
+
+	var messages []string
+	for _, p := range problems {
+		messages = append(messages, p.Error())
+	}
+	assert.Equal(t, []string{
+		`variable Shell: default: "bash" is not one of zsh, fish`,
+		"variable Workers: min 4 is greater than max 2",
+	}, messages)
+}
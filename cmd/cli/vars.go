@@ -0,0 +1,196 @@
+package main
+
+import (
+	"booster/internal/config"
+	"booster/internal/redact"
+	"booster/internal/variable"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+type VarsCmd struct {
+	List     VarsListCmd     `cmd:"" default:"1" help:"List the variables of the config, their values and where they come from"`
+	Get      VarsGetCmd      `cmd:"" help:"Print the value of a variable"`
+	Set      VarsSetCmd      `cmd:"" help:"Store the value of a variable"`
+	Unset    VarsUnsetCmd    `cmd:"" help:"Remove the stored values of variables, so they are asked for on the next run"`
+	Reprompt VarsRepromptCmd `cmd:"" help:"Ask for the values of variables again and store them"`
+}
+
+type VarsListCmd struct{}
+
+func (c *VarsListCmd) Run(cli *CLI) error {
+	defs, resolver, err := configVariables(cli)
+	if err != nil {
+		return err
+	}
+	values, err := resolver.Lookup(defs)
+	if err != nil {
+		return err
+	}
+	return printVariables(os.Stdout, values)
+}
+
+type VarsGetCmd struct {
+	Name   string `arg:"" help:"Variable to print"`
+	Reveal bool   `help:"Print the value of a secret variable instead of masking it"`
+}
+
+func (c *VarsGetCmd) Run(cli *CLI) error {
+	defs, resolver, err := configVariables(cli)
+	if err != nil {
+		return err
+	}
+	def, err := findVariable(defs, c.Name)
+	if err != nil {
+		return err
+	}
+	values, err := resolver.Lookup([]variable.Definition{def})
+	if err != nil {
+		return err
+	}
+	return printVariable(os.Stdout, values[0], c.Reveal)
+}
+
+type VarsSetCmd struct {
+	Name  string `arg:"" help:"Variable to set"`
+	Value string `arg:"" help:"Value to store; lists are comma-separated"`
+}
+
+func (c *VarsSetCmd) Run(cli *CLI) error {
+	defs, resolver, err := configVariables(cli)
+	if err != nil {
+		return err
+	}
+	def, err := findVariable(defs, c.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := resolver.Set(def, c.Value); err != nil {
+		return err
+	}
+
+	fmt.Printf("stored %s\n", def.Name)
+	if os.Getenv(def.Name) != "" {
+		fmt.Fprintf(os.Stderr, "warning: %s is set in the environment, which takes precedence over the stored value\n", def.Name)
+	}
+	return nil
+}
+
+type VarsUnsetCmd struct {
+	Names []string `arg:"" help:"Variables to remove the stored values of"`
+}
+
+func (c *VarsUnsetCmd) Run(cli *CLI) error {
+	defs, resolver, err := configVariables(cli)
+	if err != nil {
+		return err
+	}
+	for _, name := range c.Names {
+		def, err := findVariable(defs, name)
+		if err != nil {
+			return err
+		}
+		removed, err := resolver.Unset(def)
+		if err != nil {
+			return err
+		}
+		if removed {
+			fmt.Printf("removed %s\n", def.Name)
+		} else {
+			fmt.Printf("%s has no stored value\n", def.Name)
+		}
+	}
+	return nil
+}
+
+type VarsRepromptCmd struct {
+	Names []string `arg:"" optional:"" help:"Variables to ask for (defaults to every variable)"`
+}
+
+func (c *VarsRepromptCmd) Run(cli *CLI) error {
+	defs, resolver, err := configVariables(cli)
+	if err != nil {
+		return err
+	}
+	if len(c.Names) > 0 {
+		selected := make([]variable.Definition, len(c.Names))
+		for i, name := range c.Names {
+			if selected[i], err = findVariable(defs, name); err != nil {
+				return err
+			}
+		}
+		defs = selected
+	}
+	if len(defs) == 0 {
+		return fmt.Errorf("%s defines no variables", cli.Config)
+	}
+
+	if _, err := resolver.Reprompt(defs); err != nil {
+		return err
+	}
+	for _, def := range defs {
+		fmt.Printf("stored %s\n", def.Name)
+	}
+	return nil
+}
+
+func configVariables(cli *CLI) ([]variable.Definition, *variable.Resolver, error) {
+	cfg, err := config.Load(cli.Config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+	stores := configStores(cfg, cli.Config)
+	return variableDefinitions(cfg.Variables), newResolver(stores, variableOptions{Identity: cli.Identity}), nil
+}
+
+func findVariable(defs []variable.Definition, name string) (variable.Definition, error) {
+	for _, def := range defs {
+		if def.Name == name {
+			return def, nil
+		}
+	}
+	return variable.Definition{}, fmt.Errorf("variable %s is not defined in the config", name)
+}
+
+func printVariables(w io.Writer, values []variable.Value) error {
+	if len(values) == 0 {
+		_, err := fmt.Fprintln(w, "The config defines no variables")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVALUE\tSOURCE")
+	for _, v := range values {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", v.Name, formatValue(v, false), v.Source)
+	}
+	return tw.Flush()
+}
+
+func printVariable(w io.Writer, v variable.Value, reveal bool) error {
+	if v.Source == variable.SourceNone {
+		return fmt.Errorf("variable %s has no value", v.Name)
+	}
+	_, err := fmt.Fprintln(w, formatValue(v, reveal))
+	return err
+}
+
+// formatValue formats a value as it would be typed at a prompt.
+func formatValue(v variable.Value, reveal bool) string {
+	if v.Source == variable.SourceNone {
+		return ""
+	}
+	if v.Secret && !reveal {
+		return redact.Mask
+	}
+	if items, ok := v.Value.([]any); ok {
+		s := make([]string, len(items))
+		for i, item := range items {
+			s[i] = fmt.Sprint(item)
+		}
+		return strings.Join(s, ", ")
+	}
+	return fmt.Sprint(v.Value)
+}
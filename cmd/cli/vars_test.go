@@ -0,0 +1,87 @@
+package main
+
+import (
+	"booster/internal/redact"
+	"booster/internal/variable"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const varsConfig = `version: "1"
+name: dotfiles
+variables:
+  Editor:
+    prompt: Editor
+    default: nvim
+  Email:
+    prompt: Email
+  Langs:
+    type: list
+  Workers:
+    type: int
+tasks: []
+`
+
+func TestVarsCmd_SetGetUnset(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	cli, _ := setupTestConfig(t, varsConfig)
+
+	require.NoError(t, (&VarsSetCmd{Name: "Langs", Value: "go, rust"}).Run(cli))
+	require.NoError(t, (&VarsSetCmd{Name: "Workers", Value: "3"}).Run(cli))
+	err := (&VarsSetCmd{Name: "Workers", Value: "three"}).Run(cli)
+	assert.EqualError(t, err, `variable Workers: "three" is not an int`)
+	err = (&VarsSetCmd{Name: "Shell", Value: "zsh"}).Run(cli)
+	assert.EqualError(t, err, "variable Shell is not defined in the config")
+
+	defs, resolver, err := configVariables(cli)
+	require.NoError(t, err)
+	values, err := resolver.Lookup(defs)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, printVariables(&out, values))
+	assert.Equal(t, `NAME     VALUE     SOURCE
+Editor   nvim      default
+Email              none
+Langs    go, rust  store
+Workers  3         store
+`, out.String())
+
+	require.NoError(t, (&VarsUnsetCmd{Names: []string{"Workers"}}).Run(cli))
+	values, err = resolver.Lookup(defs)
+	require.NoError(t, err)
+	assert.Equal(t, variable.SourceNone, values[3].Source)
+}
+
+func TestPrintVariable(t *testing.T) {
+	secret := variable.Value{
+		Definition: variable.Definition{Name: "Token", Secret: true},
+		Value:      "hunter2",
+		Source:     variable.SourceEnv,
+	}
+
+	var masked, revealed bytes.Buffer
+	require.NoError(t, printVariable(&masked, secret, false))
+	require.NoError(t, printVariable(&revealed, secret, true))
+
+	assert.Equal(t, redact.Mask+"\n", masked.String())
+	assert.Equal(t, "hunter2\n", revealed.String())
+
+	unset := variable.Value{Definition: variable.Definition{Name: "Email"}, Source: variable.SourceNone}
+	assert.EqualError(t, printVariable(&masked, unset, false), "variable Email has no value")
+}
+
+func TestPrintVariables_MasksSecrets(t *testing.T) {
+	var out bytes.Buffer
+
+	err := printVariables(&out, []variable.Value{
+		{Definition: variable.Definition{Name: "Token", Secret: true}, Value: "hunter2", Source: variable.SourceStore},
+	})
+
+	require.NoError(t, err)
+	assert.NotContains(t, out.String(), "hunter2")
+	assert.Contains(t, out.String(), "Token  "+redact.Mask+"  store")
+}
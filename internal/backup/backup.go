@@ -0,0 +1,259 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const manifestName = "manifest.yaml"
+
+var ErrRolledBack = errors.New("already rolled back")
+
+const (
+	KindFile    = "file"
+	KindSymlink = "symlink"
+	KindMissing = "missing"
+)
+
+// Paths that were missing are removed again on rollback.
+type Entry struct {
+	Path    string      `yaml:"path"`
+	Kind    string      `yaml:"kind"`
+	File    string      `yaml:"file,omitempty"`
+	Link    string      `yaml:"link,omitempty"`
+	Mode    os.FileMode `yaml:"mode,omitempty"`
+	Created time.Time   `yaml:"created"`
+}
+
+type Manifest struct {
+	RunID      string     `yaml:"run_id"`
+	RolledBack *time.Time `yaml:"rolled_back,omitempty"`
+	Entries    []Entry    `yaml:"entries"`
+}
+
+// Nothing is written until the first snapshot.
+type Backup struct {
+	mu       sync.Mutex
+	dir      string
+	manifest Manifest
+	saved    map[string]bool
+	now      func() time.Time
+}
+
+func New(root, runID string) *Backup {
+	return &Backup{
+		dir:      filepath.Join(root, runID),
+		manifest: Manifest{RunID: runID},
+		saved:    make(map[string]bool),
+		now:      time.Now,
+	}
+}
+
+func (b *Backup) Dir() string {
+	return b.dir
+}
+
+// Only the first snapshot of a path in a run is kept, so rollback restores the
+// content from before the run.
+func (b *Backup) Save(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.saved[abs] {
+		return nil
+	}
+
+	entry := Entry{Path: abs, Created: b.now()}
+	info, err := os.Lstat(abs)
+	switch {
+	case os.IsNotExist(err):
+		entry.Kind = KindMissing
+	case err != nil:
+		return err
+	case info.Mode()&os.ModeSymlink != 0:
+		entry.Kind = KindSymlink
+		if entry.Link, err = os.Readlink(abs); err != nil {
+			return err
+		}
+	case info.Mode().IsRegular():
+		entry.Kind = KindFile
+		entry.Mode = info.Mode().Perm()
+		entry.File = fmt.Sprintf("%03d-%s", len(b.manifest.Entries)+1, filepath.Base(abs))
+		if err := b.copyIn(abs, entry.File); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot back up %s: not a regular file or symlink", abs)
+	}
+
+	b.manifest.Entries = append(b.manifest.Entries, entry)
+	if err := writeManifest(b.dir, b.manifest); err != nil {
+		return err
+	}
+	b.saved[abs] = true
+	return nil
+}
+
+func (b *Backup) copyIn(path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(b.dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(b.dir, name), data, 0o600)
+}
+
+type ctxKey struct{}
+
+func WithBackup(ctx context.Context, b *Backup) context.Context {
+	return context.WithValue(ctx, ctxKey{}, b)
+}
+
+func Save(ctx context.Context, path string) error {
+	b, _ := ctx.Value(ctxKey{}).(*Backup)
+	if b == nil {
+		return nil
+	}
+	return b.Save(path)
+}
+
+func Load(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func Runs(root string) ([]string, error) {
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ids []string
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, d.Name(), manifestName)); err == nil {
+			ids = append(ids, d.Name())
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func Latest(root string) (string, error) {
+	ids, err := Runs(root)
+	if err != nil {
+		return "", err
+	}
+	for _, id := range slices.Backward(ids) {
+		m, err := Load(filepath.Join(root, id))
+		if err != nil {
+			return "", err
+		}
+		if m.RolledBack == nil {
+			return id, nil
+		}
+	}
+	return "", errors.New("no run to roll back")
+}
+
+// Rollback keeps going after a failed restore and returns the entries that
+// were restored along with all errors.
+func Rollback(root, runID string, now time.Time, force bool) ([]Entry, error) {
+	dir := filepath.Join(root, runID)
+	m, err := Load(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no backups for run %s", runID)
+		}
+		return nil, err
+	}
+	if m.RolledBack != nil && !force {
+		return nil, fmt.Errorf("run %s: %w at %s", runID, ErrRolledBack, m.RolledBack.Format(time.RFC3339))
+	}
+
+	var restored []Entry
+	var errs []error
+	for _, e := range slices.Backward(m.Entries) {
+		if err := restore(dir, e); err != nil {
+			errs = append(errs, fmt.Errorf("restore %s: %w", e.Path, err))
+			continue
+		}
+		restored = append(restored, e)
+	}
+	if len(errs) > 0 {
+		return restored, errors.Join(errs...)
+	}
+
+	m.RolledBack = &now
+	return restored, writeManifest(dir, *m)
+}
+
+func restore(dir string, e Entry) error {
+	if err := os.Remove(e.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	switch e.Kind {
+	case KindMissing:
+		return nil
+	case KindSymlink:
+		return os.Symlink(e.Link, e.Path)
+	case KindFile:
+		data, err := os.ReadFile(filepath.Join(dir, e.File))
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(e.Path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(e.Path, data, e.Mode)
+	default:
+		return fmt.Errorf("unknown backup kind %q", e.Kind)
+	}
+}
+
+func writeManifest(dir string, m Manifest) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, manifestName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,148 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackup_SaveAndRollback(t *testing.T) {
+	root := t.TempDir()
+	work := t.TempDir()
+
+	file := filepath.Join(work, "gitconfig")
+	require.NoError(t, os.WriteFile(file, []byte("original"), 0o640))
+	link := filepath.Join(work, "link")
+	require.NoError(t, os.Symlink("/old/target", link))
+	created := filepath.Join(work, "new")
+
+	b := New(root, "run1")
+	require.NoError(t, b.Save(file))
+	require.NoError(t, b.Save(link))
+	require.NoError(t, b.Save(created))
+
+	require.NoError(t, os.WriteFile(file, []byte("rendered"), 0o644))
+	require.NoError(t, b.Save(file), "second snapshot of a path is ignored")
+	require.NoError(t, os.WriteFile(file, []byte("rendered twice"), 0o644))
+	require.NoError(t, os.Remove(link))
+	require.NoError(t, os.Symlink("/new/target", link))
+	require.NoError(t, os.WriteFile(created, []byte("created"), 0o644))
+
+	restored, err := Rollback(root, "run1", time.Now(), false)
+
+	require.NoError(t, err)
+	require.Len(t, restored, 3)
+	assert.Equal(t, created, restored[0].Path, "restores in reverse order")
+	assert.Equal(t, file, restored[2].Path)
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content))
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	dest, err := os.Readlink(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/old/target", dest)
+
+	assert.NoFileExists(t, created)
+}
+
+func TestBackup_NothingWrittenWithoutSnapshots(t *testing.T) {
+	root := t.TempDir()
+
+	b := New(root, "run1")
+
+	assert.NoDirExists(t, b.Dir())
+	ids, err := Runs(root)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestBackup_SaveRejectsDirectories(t *testing.T) {
+	b := New(t.TempDir(), "run1")
+
+	err := b.Save(t.TempDir())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a regular file or symlink")
+}
+
+func TestSave_WithoutBackupInContext(t *testing.T) {
+	assert.NoError(t, Save(context.Background(), "/does/not/matter"))
+}
+
+func TestSave_UsesBackupFromContext(t *testing.T) {
+	root := t.TempDir()
+	b := New(root, "run1")
+	ctx := WithBackup(context.Background(), b)
+
+	require.NoError(t, Save(ctx, filepath.Join(t.TempDir(), "missing")))
+
+	m, err := Load(b.Dir())
+	require.NoError(t, err)
+	require.Len(t, m.Entries, 1)
+	assert.Equal(t, KindMissing, m.Entries[0].Kind)
+}
+
+func TestLatest(t *testing.T) {
+	root := t.TempDir()
+	work := t.TempDir()
+	for _, id := range []string{"20260101T000000.000Z", "20260102T000000.000Z"} {
+		require.NoError(t, New(root, id).Save(filepath.Join(work, id)))
+	}
+
+	latest, err := Latest(root)
+	require.NoError(t, err)
+	assert.Equal(t, "20260102T000000.000Z", latest)
+
+	_, err = Rollback(root, latest, time.Now(), false)
+	require.NoError(t, err)
+
+	latest, err = Latest(root)
+	require.NoError(t, err)
+	assert.Equal(t, "20260101T000000.000Z", latest, "rolled back runs are skipped")
+
+	_, err = Rollback(root, latest, time.Now(), false)
+	require.NoError(t, err)
+
+	_, err = Latest(root)
+	assert.EqualError(t, err, "no run to roll back")
+}
+
+func TestRollback_UnknownRun(t *testing.T) {
+	_, err := Rollback(t.TempDir(), "nope", time.Now(), false)
+
+	assert.EqualError(t, err, "no backups for run nope")
+}
+
+func TestRollback_RefusesRolledBackRun(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(t.TempDir(), "gitconfig")
+	require.NoError(t, os.WriteFile(file, []byte("original"), 0o644))
+	require.NoError(t, New(root, "run1").Save(file))
+
+	_, err := Rollback(root, "run1", time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, []byte("changed since"), 0o644))
+
+	_, err = Rollback(root, "run1", time.Now(), false)
+	require.ErrorIs(t, err, ErrRolledBack)
+	assert.EqualError(t, err, "run run1: already rolled back at 2026-03-01T10:00:00Z")
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "changed since", string(content), "a refused rollback changes nothing")
+
+	restored, err := Rollback(root, "run1", time.Now(), true)
+	require.NoError(t, err)
+	assert.Len(t, restored, 1)
+	content, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content))
+}
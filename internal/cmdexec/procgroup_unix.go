@@ -0,0 +1,23 @@
+//go:build unix
+
+package cmdexec
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// waitDelay bounds how long a cancelled command may keep its output pipes
+// open after the process group has been signalled.
+const waitDelay = 5 * time.Second
+
+// setProcessGroup runs cmd in its own process group so cancelling the context
+// also stops any children it spawned.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = waitDelay
+}
@@ -18,6 +18,7 @@ type RealRunner struct{}
 
 func (r *RealRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
 	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
 	var out bytes.Buffer
 
 	var w io.Writer = &out
@@ -6,12 +6,19 @@ type Context struct {
 	OS string
 
 	Profile string
+
+	// With Tags set, only tasks carrying at least one of them run; tasks carrying
+	// any of SkipTags never run.
+	Tags     []string
+	SkipTags []string
 }
 
 type Condition struct {
 	OS []string
 
 	Profile []string
+
+	Tags []string
 }
 
 type Evaluator struct {
@@ -27,6 +34,10 @@ func (e *Evaluator) Matches(c *Condition) bool {
 		return true
 	}
 
+	if e.FilteredByTag(c) {
+		return false
+	}
+
 	if len(c.OS) > 0 && !contains(c.OS, e.ctx.OS) {
 		return false
 	}
@@ -54,6 +65,29 @@ func (e *Evaluator) FailureReason(c *Condition) string {
 	return ""
 }
 
+func (e *Evaluator) HasTagFilter() bool {
+	return len(e.ctx.Tags) > 0 || len(e.ctx.SkipTags) > 0
+}
+
+func (e *Evaluator) FilteredByTag(c *Condition) bool {
+	if c == nil {
+		return false
+	}
+	if len(e.ctx.Tags) > 0 && !containsAny(c.Tags, e.ctx.Tags) {
+		return true
+	}
+	return containsAny(c.Tags, e.ctx.SkipTags)
+}
+
+func containsAny(slice, vals []string) bool {
+	for _, v := range vals {
+		if contains(slice, v) {
+			return true
+		}
+	}
+	return false
+}
+
 func contains(slice []string, val string) bool {
 	return slices.Contains(slice, val)
 }
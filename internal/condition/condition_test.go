@@ -215,3 +215,37 @@ func TestEvaluator_FailureReason(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluator_FilteredByTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      Context
+		tags     []string
+		filtered bool
+	}{
+		{name: "no selection", ctx: Context{}, tags: []string{"dotfiles"}, filtered: false},
+		{name: "tagged task selected", ctx: Context{Tags: []string{"dotfiles"}}, tags: []string{"dotfiles", "git"}, filtered: false},
+		{name: "any selected tag is enough", ctx: Context{Tags: []string{"packages", "git"}}, tags: []string{"git"}, filtered: false},
+		{name: "task without selected tag", ctx: Context{Tags: []string{"dotfiles"}}, tags: []string{"packages"}, filtered: true},
+		{name: "untagged task with selection", ctx: Context{Tags: []string{"dotfiles"}}, tags: nil, filtered: true},
+		{name: "skipped tag", ctx: Context{SkipTags: []string{"slow"}}, tags: []string{"packages", "slow"}, filtered: true},
+		{name: "untagged task with skip tags", ctx: Context{SkipTags: []string{"slow"}}, tags: nil, filtered: false},
+		{name: "skip wins over selection", ctx: Context{Tags: []string{"packages"}, SkipTags: []string{"slow"}}, tags: []string{"packages", "slow"}, filtered: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval := NewEvaluator(tt.ctx)
+			cond := &Condition{Tags: tt.tags}
+
+			assert.Equal(t, tt.filtered, eval.FilteredByTag(cond))
+			assert.Equal(t, !tt.filtered, eval.Matches(cond))
+		})
+	}
+}
+
+func TestEvaluator_HasTagFilter(t *testing.T) {
+	assert.False(t, NewEvaluator(Context{OS: "arch"}).HasTagFilter())
+	assert.True(t, NewEvaluator(Context{Tags: []string{"a"}}).HasTagFilter())
+	assert.True(t, NewEvaluator(Context{SkipTags: []string{"a"}}).HasTagFilter())
+}
@@ -4,32 +4,160 @@ import (
 	"booster/internal/pathutil"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Name and Store are only read from the root file.
 type Config struct {
 	Version   string                 `yaml:"version"`
+	Name      string                 `yaml:"name,omitempty"`
+	Store     string                 `yaml:"store,omitempty"`
+	Include   StringOrSlice          `yaml:"include,omitempty"`
 	Profiles  []string               `yaml:"profiles,omitempty"`
 	Variables map[string]VariableDef `yaml:"variables,omitempty"`
 	Tasks     []Task                 `yaml:"tasks"`
+
+	// Files lists every loaded file in load order. Only the root config has it
+	// set.
+	Files []Position `yaml:"-"`
 }
 
 type VariableDef struct {
-	Prompt  string `yaml:"prompt"`
-	Default string `yaml:"default,omitempty"`
+	Prompt  string   `yaml:"prompt"`
+	Type    string   `yaml:"type,omitempty"`
+	Default any      `yaml:"default,omitempty"`
+	Options []string `yaml:"options,omitempty"`
+	Pattern string   `yaml:"pattern,omitempty"`
+	Min     *int     `yaml:"min,omitempty"`
+	Max     *int     `yaml:"max,omitempty"`
+	Secret  bool     `yaml:"secret,omitempty"`
 }
 
 type Task struct {
-	Args   any    `yaml:"args"`
-	When   *When  `yaml:"when,omitempty"`
-	Action string `yaml:"action"`
+	Args      any           `yaml:"args"`
+	When      *When         `yaml:"when,omitempty"`
+	Action    string        `yaml:"action"`
+	ID        string        `yaml:"id,omitempty"`
+	DependsOn StringOrSlice `yaml:"depends_on,omitempty"`
+	Tags      StringOrSlice `yaml:"tags,omitempty"`
+
+	ForEach any `yaml:"for_each,omitempty"`
+
+	IgnoreErrors bool          `yaml:"ignore_errors,omitempty"`
+	Retries      int           `yaml:"retries,omitempty"`
+	RetryDelay   time.Duration `yaml:"retry_delay,omitempty"`
+	Timeout      time.Duration `yaml:"timeout,omitempty"`
+
+	Origin Origin `yaml:"-"`
+
+	node *yaml.Node
+	// argsKey is "args" in version 1 and the action itself in version 2.
+	argsKey string
+}
+
+func (t *Task) UnmarshalYAML(node *yaml.Node) error {
+	type plain Task
+	if err := node.Decode((*plain)(t)); err != nil {
+		return err
+	}
+	t.node = node
+	t.argsKey = "args"
+	return nil
+}
+
+// Pos takes keys as named in version 1; in version 2 "args" and "action"
+// locate the action key's value and the key itself, and "id" locates the
+// name.
+func (t Task) Pos(key string) Position {
+	if t.argsKey != "args" && t.argsKey != "" {
+		switch key {
+		case "args":
+			key = t.argsKey
+		case "action":
+			return t.Origin.at(keyNode(t.node, t.argsKey))
+		case "id":
+			key = "name"
+		}
+	}
+	if v := mappingValue(t.node, key); v != nil {
+		return t.Origin.at(v)
+	}
+	return t.Origin.Position
+}
+
+// ArgPos locates the index-th element of an args list, or args itself if index
+// is negative. Parts that are missing from the file fall back to the closest
+// enclosing node.
+func (t Task) ArgPos(index int, key string) Position {
+	node := mappingValue(t.node, t.argsKey)
+	if node == nil {
+		return t.Origin.Position
+	}
+	if index >= 0 && node.Kind == yaml.SequenceNode && index < len(node.Content) {
+		node = node.Content[index]
+	}
+	if v := mappingValue(node, key); key != "" && v != nil {
+		node = v
+	}
+	return t.Origin.at(node)
+}
+
+func (t *Task) locate(file Position, index int) {
+	t.Origin = Origin{Position: file, Index: index + 1}
+	if t.node != nil {
+		t.Origin.Position = t.Origin.at(t.node)
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Origin.File is relative to the directory of the root config.
+type Origin struct {
+	Position
+	Index int
+}
+
+func (o Origin) String() string {
+	if o.File == "" {
+		return fmt.Sprintf("task %d", o.Index)
+	}
+	return fmt.Sprintf("%s: task %d", o.Position, o.Index)
 }
 
+// When is either a mapping or a string expression, which is shorthand for a
+// mapping with only expr set.
 type When struct {
 	OS      StringOrSlice `yaml:"os,omitempty"`
 	Profile StringOrSlice `yaml:"profile,omitempty"`
+	Expr    string        `yaml:"expr,omitempty"`
+}
+
+func (w *When) UnmarshalYAML(unmarshal func(any) error) error {
+	var expr string
+	if err := unmarshal(&expr); err == nil {
+		*w = When{Expr: expr}
+		return nil
+	}
+
+	type plain When
+	return unmarshal((*plain)(w))
 }
 
 type StringOrSlice []string
@@ -49,32 +177,162 @@ func (s *StringOrSlice) UnmarshalYAML(unmarshal func(any) error) error {
 	return nil
 }
 
+// Load appends the tasks of included files after the including file's tasks.
+// Variables of later files override earlier ones, and the including file
+// overrides its includes.
 func Load(path string) (*Config, error) {
-	expanded := pathutil.Expand(path)
-
-	data, err := os.ReadFile(expanded)
+	abs, err := filepath.Abs(pathutil.Expand(path))
 	if err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("parse config: %w", err)
+	l := &loader{rootDir: filepath.Dir(abs), loaded: make(map[string]bool)}
+	cfg, err := l.load(abs, nil, "")
+	if err != nil {
+		return nil, err
 	}
 
 	if cfg.Version == "" {
 		return nil, errors.New("config missing version field")
 	}
+	if cfg.Name == "." || cfg.Name == ".." || strings.ContainsAny(cfg.Name, `/\`) {
+		return nil, fmt.Errorf("config name %q cannot be used as a file name", cfg.Name)
+	}
+	cfg.Files = l.files
+	return cfg, nil
+}
+
+type loader struct {
+	rootDir string
+	loaded  map[string]bool
+	files   []Position
+}
+
+// A file without a version is read as version, the version of the file
+// including it.
+func (l *loader) load(path string, stack []string, version string) (*Config, error) {
+	if i := slices.Index(stack, path); i >= 0 {
+		cycle := append(slices.Clone(stack[i:]), path)
+		for j, p := range cycle {
+			cycle[j] = l.display(p)
+		}
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+	}
+	l.loaded[path] = true
+	stack = append(stack, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if len(stack) > 1 {
+			return nil, fmt.Errorf("%s: read config: %w", l.display(stack[len(stack)-2]), err)
+		}
+		return nil, fmt.Errorf("read config: %w", err)
+	}
 
-	if cfg.Version != "1" {
-		return nil, fmt.Errorf("unsupported config version: %s", cfg.Version)
+	name := l.display(path)
+	l.files = append(l.files, Position{Path: path, File: name})
+
+	file := Position{Path: path, File: name}
+	doc, err := parseFile(file, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var head struct {
+		Version string `yaml:"version"`
+	}
+	if err := doc.Decode(&head); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", name, err)
+	}
+	if head.Version != "" {
+		version = head.Version
+	}
+
+	var cfg Config
+	switch version {
+	case "", "1":
+		if err := doc.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", name, err)
+		}
+	case "2":
+		if err := decodeV2(doc, &cfg, file); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported config version: %s", name, head.Version)
 	}
 
-	for i, task := range cfg.Tasks {
+	for i := range cfg.Tasks {
+		task := &cfg.Tasks[i]
+		task.locate(file, i)
 		if task.Action == "" {
-			return nil, fmt.Errorf("task %d: action cannot be empty", i+1)
+			return nil, ErrorAt(task.Origin.Position, fmt.Errorf("task %d: action cannot be empty", task.Origin.Index))
 		}
+		if task.Retries < 0 {
+			return nil, ErrorAt(task.Pos("retries"), fmt.Errorf("task %d: retries cannot be negative", task.Origin.Index))
+		}
+		if task.Timeout < 0 {
+			return nil, ErrorAt(task.Pos("timeout"), fmt.Errorf("task %d: timeout cannot be negative", task.Origin.Index))
+		}
+	}
+
+	includes, err := l.resolveIncludes(path, cfg.Include)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	vars := make(map[string]VariableDef)
+	for _, inc := range includes {
+		if l.loaded[inc] && !slices.Contains(stack, inc) {
+			continue
+		}
+		child, err := l.load(inc, stack, version)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Tasks = append(cfg.Tasks, child.Tasks...)
+		for _, p := range child.Profiles {
+			if !slices.Contains(cfg.Profiles, p) {
+				cfg.Profiles = append(cfg.Profiles, p)
+			}
+		}
+		maps.Copy(vars, child.Variables)
+	}
+	if len(vars) > 0 {
+		maps.Copy(vars, cfg.Variables)
+		cfg.Variables = vars
 	}
 
 	return &cfg, nil
 }
+
+// A glob matching nothing is not an error; a missing file is.
+func (l *loader) resolveIncludes(path string, patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		p := pathutil.Expand(pattern)
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(path), p)
+		}
+
+		if !strings.ContainsAny(p, "*?[") {
+			files = append(files, p)
+			continue
+		}
+
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", pattern, err)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func (l *loader) display(path string) string {
+	rel, err := filepath.Rel(l.rootDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
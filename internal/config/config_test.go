@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,7 +42,7 @@ tasks:
 		},
 		{
 			name: "unsupported version",
-			content: `version: "2"
+			content: `version: "3"
 tasks: []
 `,
 			wantErr: "unsupported config version",
@@ -78,6 +79,144 @@ tasks:
 				assert.Len(t, cfg.Tasks, 2)
 			},
 		},
+		{
+			name: "task id and depends_on",
+			content: `version: "1"
+tasks:
+  - id: base
+    action: dir.create
+    args:
+      - ~/.config/base
+  - action: dir.create
+    depends_on: base
+    args:
+      - ~/.config/single
+  - action: dir.create
+    depends_on: [base, other]
+    args:
+      - ~/.config/list
+`,
+			checkValid: func(t *testing.T, cfg *Config) {
+				require.Len(t, cfg.Tasks, 3)
+				assert.Equal(t, "base", cfg.Tasks[0].ID)
+				assert.Empty(t, cfg.Tasks[0].DependsOn)
+				assert.Equal(t, StringOrSlice{"base"}, cfg.Tasks[1].DependsOn)
+				assert.Equal(t, StringOrSlice{"base", "other"}, cfg.Tasks[2].DependsOn)
+			},
+		},
+		{
+			name: "task tags",
+			content: `version: "1"
+tasks:
+  - action: dir.create
+    tags: [dotfiles, shell]
+    args: [~/.config/fish]
+  - action: pkg.install
+    tags: packages
+    args: [neovim]
+`,
+			checkValid: func(t *testing.T, cfg *Config) {
+				require.Len(t, cfg.Tasks, 2)
+				assert.Equal(t, StringOrSlice{"dotfiles", "shell"}, cfg.Tasks[0].Tags)
+				assert.Equal(t, StringOrSlice{"packages"}, cfg.Tasks[1].Tags)
+			},
+		},
+		{
+			name: "task for_each",
+			content: `version: "1"
+tasks:
+  - action: dir.create
+    for_each: [api, web]
+    args: ["~/src/${ item }"]
+  - action: template.render
+    for_each:
+      gitconfig: ~/.gitconfig
+    args: [{source: "${ key }.tmpl", target: "${ item }"}]
+  - action: dir.create
+    for_each: "${ vars.dirs }"
+    args: ["${ item }"]
+`,
+			checkValid: func(t *testing.T, cfg *Config) {
+				require.Len(t, cfg.Tasks, 3)
+				assert.Equal(t, []any{"api", "web"}, cfg.Tasks[0].ForEach)
+				assert.Equal(t, map[string]any{"gitconfig": "~/.gitconfig"}, cfg.Tasks[1].ForEach)
+				assert.Equal(t, "${ vars.dirs }", cfg.Tasks[2].ForEach)
+			},
+		},
+		{
+			name: "task ignore_errors",
+			content: `version: "1"
+tasks:
+  - action: pkg.install
+    ignore_errors: true
+    args: [flaky-package]
+  - action: dir.create
+    args: [~/.config/x]
+`,
+			checkValid: func(t *testing.T, cfg *Config) {
+				require.Len(t, cfg.Tasks, 2)
+				assert.True(t, cfg.Tasks[0].IgnoreErrors)
+				assert.False(t, cfg.Tasks[1].IgnoreErrors)
+			},
+		},
+		{
+			name: "task retries and retry_delay",
+			content: `version: "1"
+tasks:
+  - action: pkg.install
+    retries: 3
+    retry_delay: 500ms
+    args: [neovim]
+`,
+			checkValid: func(t *testing.T, cfg *Config) {
+				require.Len(t, cfg.Tasks, 1)
+				assert.Equal(t, 3, cfg.Tasks[0].Retries)
+				assert.Equal(t, 500*time.Millisecond, cfg.Tasks[0].RetryDelay)
+			},
+		},
+		{
+			name: "negative retries",
+			content: `version: "1"
+tasks:
+  - action: pkg.install
+    retries: -1
+    args: [neovim]
+`,
+			wantErr: "task 1: retries cannot be negative",
+		},
+		{
+			name: "invalid retry_delay",
+			content: `version: "1"
+tasks:
+  - action: pkg.install
+    retry_delay: soon
+    args: [neovim]
+`,
+			wantErr: "parse config",
+		},
+		{
+			name: "task timeout",
+			content: `version: "1"
+tasks:
+  - action: pkg.install
+    timeout: 10m
+    args: [neovim]
+`,
+			checkValid: func(t *testing.T, cfg *Config) {
+				require.Len(t, cfg.Tasks, 1)
+				assert.Equal(t, 10*time.Minute, cfg.Tasks[0].Timeout)
+			},
+		},
+		{
+			name: "negative timeout",
+			content: `version: "1"
+tasks:
+  - action: pkg.install
+    timeout: -1s
+    args: [neovim]
+`,
+			wantErr: "task 1: timeout cannot be negative",
+		},
 		{
 			name: "empty action string",
 			content: `version: "1"
@@ -222,6 +361,39 @@ tasks:
 				assert.Nil(t, cfg.Tasks[1].When)
 			},
 		},
+		{
+			name: "expression string",
+			content: `version: "1"
+tasks:
+  - action: dir.create
+    when: "${ !installed('nvim') && arch == 'arm64' }"
+    args:
+      - ~/test
+`,
+			check: func(t *testing.T, cfg *Config) {
+				require.Len(t, cfg.Tasks, 1)
+				require.NotNil(t, cfg.Tasks[0].When)
+				assert.Equal(t, When{Expr: "${ !installed('nvim') && arch == 'arm64' }"}, *cfg.Tasks[0].When)
+			},
+		},
+		{
+			name: "expression alongside os",
+			content: `version: "1"
+tasks:
+  - action: dir.create
+    when:
+      os: darwin
+      expr: "${ arch == 'arm64' }"
+    args:
+      - ~/test
+`,
+			check: func(t *testing.T, cfg *Config) {
+				require.Len(t, cfg.Tasks, 1)
+				require.NotNil(t, cfg.Tasks[0].When)
+				assert.Equal(t, StringOrSlice{"darwin"}, cfg.Tasks[0].When.OS)
+				assert.Equal(t, "${ arch == 'arm64' }", cfg.Tasks[0].When.Expr)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -483,3 +655,170 @@ tasks: []
 		})
 	}
 }
+
+func TestLoad_NameAndStore(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"v1.yaml":       "version: \"1\"\nname: dotfiles\nstore: .booster/values.yaml\ninclude: other.yaml\ntasks: []\n",
+		"v2.yaml":       "version: \"2\"\nname: dotfiles\nstore: .booster/values.yaml\ntasks: []\n",
+		"other.yaml":    "name: other\nstore: other.yaml\ntasks: []\n",
+		"bad-name.yaml": "version: \"1\"\nname: a/b\ntasks: []\n",
+	})
+
+	for _, name := range []string{"v1.yaml", "v2.yaml"} {
+		cfg, err := Load(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, "dotfiles", cfg.Name, "%s: the root file's name wins", name)
+		assert.Equal(t, ".booster/values.yaml", cfg.Store, name)
+	}
+
+	_, err := Load(filepath.Join(dir, "bad-name.yaml"))
+	assert.EqualError(t, err, `config name "a/b" cannot be used as a file name`)
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestLoad_Include(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": `version: "1"
+include:
+  - packages.yaml
+  - dotfiles/*.yaml
+profiles: [personal]
+variables:
+  editor:
+    prompt: Editor
+    default: nvim
+tasks:
+  - action: dir.create
+    args: [~/root]
+`,
+		"packages.yaml": `profiles: [work, personal]
+variables:
+  editor:
+    prompt: Editor
+    default: vim
+  shell:
+    prompt: Shell
+tasks:
+  - action: pkg.install
+    args: [git]
+`,
+		"dotfiles/git.yaml": `include: ../shared/common.yaml
+tasks:
+  - action: symlink.create
+    args: [{source: a, target: b}]
+`,
+		"dotfiles/zsh.yaml": `variables:
+  shell:
+    prompt: Shell
+    default: zsh
+tasks:
+  - action: dir.create
+    args: [~/zsh]
+  - action: dir.create
+    args: [~/zsh2]
+`,
+		"shared/common.yaml": `tasks:
+  - action: dir.create
+    args: [~/common]
+`,
+	})
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+
+	var origins []string
+	for _, task := range cfg.Tasks {
+		origins = append(origins, task.Origin.String())
+	}
+	assert.Equal(t, []string{
+		"config.yaml:11:5: task 1",
+		"packages.yaml:9:5: task 1",
+		"dotfiles/git.yaml:3:5: task 1",
+		"shared/common.yaml:2:5: task 1",
+		"dotfiles/zsh.yaml:6:5: task 1",
+		"dotfiles/zsh.yaml:8:5: task 2",
+	}, origins)
+	assert.Equal(t, filepath.Join(dir, "dotfiles", "zsh.yaml"), cfg.Tasks[5].Origin.Path)
+
+	assert.Equal(t, []string{"personal", "work"}, cfg.Profiles)
+	assert.Equal(t, "nvim", cfg.Variables["editor"].Default, "including file overrides its includes")
+	assert.Equal(t, "zsh", cfg.Variables["shell"].Default, "later includes override earlier ones")
+}
+
+func TestLoad_IncludeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"config.yaml": "version: \"1\"\ninclude: a.yaml\ntasks: []\n",
+				"a.yaml":      "include: b.yaml\n",
+				"b.yaml":      "include: a.yaml\n",
+			},
+			wantErr: "include cycle: a.yaml -> b.yaml -> a.yaml",
+		},
+		{
+			name: "missing file names the including file",
+			files: map[string]string{
+				"config.yaml": "version: \"1\"\ninclude: sub/a.yaml\ntasks: []\n",
+				"sub/a.yaml":  "include: missing.yaml\n",
+			},
+			wantErr: "sub/a.yaml: read config:",
+		},
+		{
+			name: "invalid task names the defining file",
+			files: map[string]string{
+				"config.yaml": "version: \"1\"\ninclude: a.yaml\ntasks: []\n",
+				"a.yaml":      "tasks:\n  - action: dir.create\n  - args: []\n",
+			},
+			wantErr: "a.yaml:3:5: task 2: action cannot be empty",
+		},
+		{
+			name: "unsupported version in include",
+			files: map[string]string{
+				"config.yaml": "version: \"1\"\ninclude: a.yaml\ntasks: []\n",
+				"a.yaml":      "version: \"9\"\n",
+			},
+			wantErr: "a.yaml: unsupported config version: 9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+
+			_, err := Load(filepath.Join(dir, "config.yaml"))
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoad_IncludedTwiceIsLoadedOnce(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "version: \"1\"\ninclude: [a.yaml, b.yaml]\ntasks: []\n",
+		"a.yaml":      "include: common.yaml\n",
+		"b.yaml":      "include: common.yaml\n",
+		"common.yaml": "tasks:\n  - action: dir.create\n    args: [~/x]\n",
+	})
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+
+	require.NoError(t, err)
+	assert.Len(t, cfg.Tasks, 1)
+}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// parseFile parses every format into a YAML node tree, so that they are
+// decoded and located the same way.
+func parseFile(file Position, data []byte) (*yaml.Node, error) {
+	switch strings.ToLower(filepath.Ext(file.Path)) {
+	case ".toml":
+		return parseTOML(file, data)
+	case ".json":
+		return parseJSON(file, data)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", file.File, err)
+	}
+	return &doc, nil
+}
+
+func document(root *yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.DocumentNode, Line: 1, Column: 1, Content: []*yaml.Node{root}}
+}
+
+func scalar(tag, value string, line, column int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value, Line: line, Column: column}
+}
+
+func lineColumn(data []byte, offset int) (int, int) {
+	offset = min(offset, len(data))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	return line, offset - bytes.LastIndexByte(before, '\n')
+}
+
+// parseJSON builds the node tree from the JSON tokens because the YAML parser
+// rejects some valid JSON, such as the \/ escape.
+func parseJSON(file Position, data []byte) (*yaml.Node, error) {
+	p := &jsonParser{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	p.dec.UseNumber()
+
+	root, err := p.value()
+	if err == nil {
+		if _, err = p.dec.Token(); err == io.EOF {
+			return document(root), nil
+		} else if err == nil {
+			err = errors.New("invalid character after top-level value")
+		}
+	}
+
+	var serr *json.SyntaxError
+	switch {
+	case errors.As(err, &serr):
+		// Offset is just past the offending character.
+		file.Line, file.Column = lineColumn(data, int(serr.Offset)-1)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		file.Line, file.Column = lineColumn(data, len(data))
+		err = errors.New("unexpected end of JSON input")
+	default:
+		file.Line, file.Column = lineColumn(data, int(p.dec.InputOffset()))
+	}
+	return nil, ErrorAt(file, fmt.Errorf("parse config: %w", err))
+}
+
+type jsonParser struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+func (p *jsonParser) next() (json.Token, int, int, error) {
+	// InputOffset is the end of the previous token; the next one starts
+	// after any whitespace and separators.
+	start := int(p.dec.InputOffset())
+	for start < len(p.data) && strings.IndexByte(" \t\r\n,:", p.data[start]) >= 0 {
+		start++
+	}
+	tok, err := p.dec.Token()
+	line, column := lineColumn(p.data, start)
+	return tok, line, column, err
+}
+
+func (p *jsonParser) value() (*yaml.Node, error) {
+	tok, line, column, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '{' {
+			node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line, Column: column}
+			for p.dec.More() {
+				key, keyLine, keyColumn, err := p.next()
+				if err != nil {
+					return nil, err
+				}
+				value, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, scalar("!!str", key.(string), keyLine, keyColumn), value)
+			}
+			_, err = p.dec.Token()
+			return node, err
+		}
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: line, Column: column}
+		for p.dec.More() {
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		_, err = p.dec.Token()
+		return node, err
+	case string:
+		return scalar("!!str", tok, line, column), nil
+	case json.Number:
+		if _, err := tok.Int64(); err == nil {
+			return scalar("!!int", tok.String(), line, column), nil
+		}
+		return scalar("!!float", tok.String(), line, column), nil
+	case bool:
+		return scalar("!!bool", strconv.FormatBool(tok), line, column), nil
+	default:
+		return scalar("!!null", "null", line, column), nil
+	}
+}
+
+// The file is decoded first, which reports redefined keys and tables that the
+// syntax tree does not.
+func parseTOML(file Position, data []byte) (*yaml.Node, error) {
+	var check map[string]any
+	if err := toml.Unmarshal(data, &check); err != nil {
+		var derr *toml.DecodeError
+		if errors.As(err, &derr) {
+			file.Line, file.Column = derr.Position()
+		}
+		return nil, ErrorAt(file, fmt.Errorf("parse config: %w", err))
+	}
+
+	b := &tomlBuilder{data: data}
+	b.p.Reset(data)
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	current := root
+	for b.p.NextExpression() {
+		expr := b.p.Expression()
+		switch expr.Kind {
+		case unstable.Table:
+			keys := b.keys(expr)
+			line, column := b.header(keys[0])
+			current = b.table(root, keys, line, column)
+		case unstable.ArrayTable:
+			keys := b.keys(expr)
+			line, column := b.header(keys[0])
+			parent := b.table(root, keys[:len(keys)-1], line, column)
+			last := keys[len(keys)-1]
+			seq := mappingValue(parent, string(last.Data))
+			if seq == nil {
+				seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+				seq.Line, seq.Column = b.pos(last.Raw)
+				parent.Content = append(parent.Content, b.key(last), seq)
+			}
+			current = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line, Column: column}
+			seq.Content = append(seq.Content, current)
+		case unstable.KeyValue:
+			b.keyValue(current, expr)
+		}
+	}
+	if err := b.p.Error(); err != nil {
+		return nil, ErrorAt(file, fmt.Errorf("parse config: %w", err))
+	}
+	return document(root), nil
+}
+
+type tomlBuilder struct {
+	p    unstable.Parser
+	data []byte
+}
+
+func (b *tomlBuilder) pos(r unstable.Range) (int, int) {
+	shape := b.p.Shape(r)
+	return shape.Start.Line, shape.Start.Column
+}
+
+func (b *tomlBuilder) header(key *unstable.Node) (int, int) {
+	offset := int(key.Raw.Offset)
+	for offset > 0 && strings.IndexByte("[ \t", b.data[offset-1]) >= 0 {
+		offset--
+	}
+	return b.pos(unstable.Range{Offset: uint32(offset), Length: 1})
+}
+
+func (b *tomlBuilder) keys(expr *unstable.Node) []*unstable.Node {
+	var keys []*unstable.Node
+	for it := expr.Key(); it.Next(); {
+		keys = append(keys, it.Node())
+	}
+	return keys
+}
+
+func (b *tomlBuilder) key(key *unstable.Node) *yaml.Node {
+	line, column := b.pos(key.Raw)
+	return scalar("!!str", string(key.Data), line, column)
+}
+
+// A key holding an array of tables leads to its last table.
+func (b *tomlBuilder) table(node *yaml.Node, keys []*unstable.Node, line, column int) *yaml.Node {
+	for _, key := range keys {
+		next := mappingValue(node, string(key.Data))
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line, Column: column}
+			node.Content = append(node.Content, b.key(key), next)
+		}
+		if next.Kind == yaml.SequenceNode && len(next.Content) > 0 {
+			next = next.Content[len(next.Content)-1]
+		}
+		node = next
+	}
+	return node
+}
+
+func (b *tomlBuilder) keyValue(node *yaml.Node, expr *unstable.Node) {
+	keys := b.keys(expr)
+	last := keys[len(keys)-1]
+	line, column := b.pos(last.Raw)
+	parent := b.table(node, keys[:len(keys)-1], line, column)
+
+	// Arrays have no range of their own, so values are located by the
+	// first character after the equals sign.
+	offset := int(last.Raw.Offset + last.Raw.Length)
+	offset += bytes.IndexByte(b.data[offset:], '=') + 1
+	for offset < len(b.data) && (b.data[offset] == ' ' || b.data[offset] == '\t') {
+		offset++
+	}
+	line, column = b.pos(unstable.Range{Offset: uint32(offset), Length: 1})
+
+	parent.Content = append(parent.Content, b.key(last), b.value(expr.Value(), line, column))
+}
+
+func (b *tomlBuilder) value(v *unstable.Node, line, column int) *yaml.Node {
+	if v.Raw.Length > 0 {
+		line, column = b.pos(v.Raw)
+	}
+	data := string(v.Data)
+
+	switch v.Kind {
+	case unstable.Array:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: line, Column: column}
+		for it := v.Children(); it.Next(); {
+			node.Content = append(node.Content, b.value(it.Node(), line, column))
+		}
+		return node
+	case unstable.InlineTable:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line, Column: column}
+		for it := v.Children(); it.Next(); {
+			b.keyValue(node, it.Node())
+		}
+		return node
+	case unstable.Bool:
+		return scalar("!!bool", data, line, column)
+	case unstable.Integer:
+		// The file was decoded already, so the integer is valid.
+		i, _ := strconv.ParseInt(data, 0, 64)
+		return scalar("!!int", strconv.FormatInt(i, 10), line, column)
+	case unstable.Float:
+		return scalar("!!float", tomlFloat(data), line, column)
+	default:
+		return scalar("!!str", data, line, column)
+	}
+}
+
+func tomlFloat(f string) string {
+	switch strings.TrimLeft(f, "+") {
+	case "inf":
+		return ".inf"
+	case "-inf":
+		return "-.inf"
+	case "nan", "-nan":
+		return ".nan"
+	}
+	v, _ := strconv.ParseFloat(strings.ReplaceAll(f, "_", ""), 64)
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,294 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Formats(t *testing.T) {
+	yamlConfig := `version: "1"
+profiles: [work]
+variables:
+  Email:
+    prompt: "Email?"
+tasks:
+  - action: dir.create
+    id: dirs
+    args: [~/a, ~/b]
+    tags: base
+    when: "${ arch == 'arm64' }"
+  - action: symlink.create
+    args:
+      - source: a
+        target: b
+    depends_on: [dirs]
+    retries: 2
+    timeout: 1m
+    when:
+      os: darwin
+`
+	tomlConfig := `version = "1"
+profiles = ["work"]
+
+[variables.Email]
+prompt = "Email?"
+
+[[tasks]]
+action = "dir.create"
+id = "dirs"
+args = ["~/a", "~/b"]
+tags = "base"
+when = "${ arch == 'arm64' }"
+
+[[tasks]]
+action = "symlink.create"
+args = [{ source = "a", target = "b" }]
+depends_on = ["dirs"]
+retries = 2
+timeout = "1m"
+when.os = "darwin"
+`
+	jsonConfig := `{
+	"version": "1",
+	"profiles": ["work"],
+	"variables": {"Email": {"prompt": "Email?"}},
+	"tasks": [
+		{
+			"action": "dir.create",
+			"id": "dirs",
+			"args": ["~/a", "~/b"],
+			"tags": "base",
+			"when": "${ arch == 'arm64' }"
+		},
+		{
+			"action": "symlink.create",
+			"args": [{"source": "a", "target": "b"}],
+			"depends_on": ["dirs"],
+			"retries": 2,
+			"timeout": "1m",
+			"when": {"os": "darwin"}
+		}
+	]
+}
+`
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": yamlConfig,
+		"config.toml": tomlConfig,
+		"config.json": jsonConfig,
+	})
+
+	want, err := Load(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+
+	for _, name := range []string{"config.toml", "config.json"} {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := Load(filepath.Join(dir, name))
+			require.NoError(t, err)
+
+			assert.Equal(t, want.Version, cfg.Version)
+			assert.Equal(t, want.Profiles, cfg.Profiles)
+			assert.Equal(t, want.Variables, cfg.Variables)
+			require.Len(t, cfg.Tasks, 2)
+			for i, task := range cfg.Tasks {
+				assert.Equal(t, want.Tasks[i].Action, task.Action)
+				assert.Equal(t, want.Tasks[i].ID, task.ID)
+				assert.Equal(t, want.Tasks[i].Args, task.Args)
+				assert.Equal(t, want.Tasks[i].Tags, task.Tags)
+				assert.Equal(t, want.Tasks[i].DependsOn, task.DependsOn)
+				assert.Equal(t, want.Tasks[i].When, task.When)
+				assert.Equal(t, want.Tasks[i].Retries, task.Retries)
+				assert.Equal(t, want.Tasks[i].Timeout, task.Timeout)
+			}
+			assert.Equal(t, time.Minute, cfg.Tasks[1].Timeout)
+		})
+	}
+}
+
+func TestLoad_FormatScalars(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.toml": `version = "1"
+[[tasks]]
+action = "x"
+args = [1_000, 0x10, 1.5, inf, true, "a\tb", [1, [2]], {}]
+`,
+		"config.json": `{"version": "1", "tasks": [{"action": "x", "args": [1000, 16, 1.5, true, "a\/bé", null, [1, [2]], {}]}]}`,
+	})
+
+	cfg, err := Load(filepath.Join(dir, "config.toml"))
+	require.NoError(t, err)
+	args := cfg.Tasks[0].Args.([]any)
+	assert.Equal(t, 1000, args[0])
+	assert.Equal(t, 16, args[1])
+	assert.Equal(t, 1.5, args[2])
+	assert.Equal(t, []any{true, "a\tb", []any{1, []any{2}}, map[string]any{}}, args[4:])
+
+	cfg, err = Load(filepath.Join(dir, "config.json"))
+	require.NoError(t, err)
+	assert.Equal(t, []any{1000, 16, 1.5, true, "a/bé", nil, []any{1, []any{2}}, map[string]any{}}, cfg.Tasks[0].Args)
+}
+
+func TestLoad_FormatPositions(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.toml": `version = "1"
+
+[[tasks]]
+action = "dir.create"
+args = ["~/a"]
+retries = 2
+
+[[tasks]]
+action = "symlink.create"
+args = [
+  { source = "a", target = "b" },
+]
+`,
+		"config.json": `{
+  "version": "1",
+  "tasks": [
+    {"action": "dir.create", "args": ["~/a"], "retries": 2},
+    {
+      "action": "symlink.create",
+      "args": [
+        {"source": "a", "target": "b"}
+      ]
+    }
+  ]
+}
+`,
+	})
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{
+			file: "config.toml",
+			want: []string{"config.toml:3:1", "config.toml:5:9", "config.toml:6:11", "config.toml:4:10", "config.toml:8:1", "config.toml:11:28"},
+		},
+		{
+			file: "config.json",
+			want: []string{"config.json:4:5", "config.json:4:39", "config.json:4:58", "config.json:4:16", "config.json:5:5", "config.json:8:35"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			cfg, err := Load(filepath.Join(dir, tt.file))
+			require.NoError(t, err)
+
+			first, second := cfg.Tasks[0], cfg.Tasks[1]
+			assert.Equal(t, tt.want, []string{
+				first.Origin.Position.String(),
+				first.ArgPos(0, "").String(),
+				first.Pos("retries").String(),
+				first.Pos("action").String(),
+				second.Origin.Position.String(),
+				second.ArgPos(0, "target").String(),
+			})
+		})
+	}
+}
+
+func TestLoad_FormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "config.toml",
+			content: "version = \"1\"\ntasks = [\n",
+			want:    "config.toml:2:10: parse config: toml: array is incomplete",
+		},
+		{
+			name:    "config.toml",
+			content: "version = \"1\"\nversion = \"2\"\n",
+			want:    "config.toml:2:1: parse config:",
+		},
+		{
+			name:    "config.json",
+			content: "{\n  \"version\": \"1\",\n  \"tasks\": [,]\n}\n",
+			want:    "config.json:3:13: parse config: invalid character ','",
+		},
+		{
+			name:    "config.json",
+			content: "{\"version\": \"1\"",
+			want:    "config.json:1:15: parse config: unexpected end of JSON input",
+		},
+		{
+			name:    "config.json",
+			content: "{\"version\": \"1\", \"tasks\": [{\"action\": \"x\", \"retries\": -1}]}",
+			want:    "config.json:1:55: task 1: retries cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{tt.name: tt.content})
+
+			_, err := Load(filepath.Join(dir, tt.name))
+
+			var perr *PositionError
+			require.ErrorAs(t, err, &perr)
+			assert.Contains(t, err.Error(), tt.want)
+			assert.NotEmpty(t, perr.Snippet)
+		})
+	}
+}
+
+func TestLoad_IncludeOtherFormats(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "version: \"2\"\ninclude: [tasks.toml, more.json]\ntasks:\n  - dir.create: [a]\n",
+		"tasks.toml":  "[[tasks]]\n\"dir.create\" = [\"b\"]\n",
+		"more.json":   `{"tasks": [{"dir.create": ["c"]}]}`,
+	})
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Tasks, 3)
+	for i, want := range []string{"a", "b", "c"} {
+		assert.Equal(t, "dir.create", cfg.Tasks[i].Action)
+		assert.Equal(t, []any{want}, cfg.Tasks[i].Args)
+	}
+	assert.Equal(t, "tasks.toml:2:16", cfg.Tasks[1].Pos("args").String())
+}
+
+func TestSchema_ValidateOtherFormats(t *testing.T) {
+	schema, err := CompileSchema([]byte(testSchema))
+	require.NoError(t, err)
+
+	dir := writeFiles(t, map[string]string{
+		"config.toml": "version = \"1\"\nverison = \"1\"\n[[tasks]]\nretries = \"two\"\n",
+		"config.json": "{\n  \"version\": \"1\",\n  \"tasks\": [{\"retries\": \"two\"}]\n}\n",
+	})
+
+	tests := map[string][]string{
+		"config.toml": {
+			`config.toml:2:1: unknown field "verison"`,
+			"config.toml:4:11: got string, want integer",
+		},
+		"config.json": {
+			"config.json:3:25: got string, want integer",
+		},
+	}
+
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs, err := schema.Validate(Position{Path: filepath.Join(dir, name), File: name})
+			require.NoError(t, err)
+
+			var got []string
+			for _, e := range errs {
+				var perr *PositionError
+				require.ErrorAs(t, e, &perr)
+				got = append(got, perr.Pos.String()+": "+perr.Err.Error())
+			}
+			assert.ElementsMatch(t, want, got)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"maps"
+	"slices"
+)
+
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// An action mapped to nil accepts any args.
+func JSONSchema(actions map[string]map[string]any) map[string]any {
+	names := slices.Sorted(maps.Keys(actions))
+
+	defs := map[string]any{
+		"variable": map[string]any{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"prompt": describe("string", "Question asked when the variable has no stored value"),
+				"type": map[string]any{
+					"type":        "string",
+					"enum":        []string{"string", "bool", "int", "choice", "list"},
+					"description": "Type of the value; string if not set",
+				},
+				"default": map[string]any{
+					"type":        []string{"string", "boolean", "integer", "array"},
+					"description": "Value suggested in the prompt, of the variable's type",
+				},
+				"options": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Values a choice may take, or a list may hold",
+				},
+				"pattern": describe("string", "Regular expression a string, or every item of a list, must match"),
+				"min":     describe("integer", "Minimum of an int, length of a string or number of items in a list"),
+				"max":     describe("integer", "Maximum of an int, length of a string or number of items in a list"),
+				"secret":  describe("boolean", "Store the value encrypted, mask it when prompting and hide it in output; not allowed for bool and int"),
+			},
+			"if": map[string]any{
+				"properties": map[string]any{"type": map[string]any{"const": "choice"}},
+				"required":   []any{"type"},
+			},
+			"then": map[string]any{"required": []any{"options"}},
+		},
+		"task":    taskSchema(names, actions),
+		"task-v2": taskV2Schema(names, actions),
+		"when": map[string]any{
+			"oneOf": []any{
+				describe("string", "Expression that must evaluate to true, e.g. ${ arch == 'arm64' }"),
+				map[string]any{"$ref": "#/$defs/when-conditions"},
+			},
+		},
+		"when-conditions": map[string]any{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"os": stringOrList("Operating system condition",
+					"Single OS to match", "List of OSes to match (any match = execute)"),
+				"profile": stringOrList("Profile condition",
+					"Single profile to match", "List of profiles to match (any match = execute)"),
+				"expr": describe("string", "Expression that must also evaluate to true"),
+			},
+		},
+	}
+	for _, name := range names {
+		if args := actions[name]; args != nil {
+			defs[argsDef(name)] = args
+		}
+	}
+
+	return map[string]any{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"$id":                  "https://github.com/lukeshiner/booster/bootstrap.schema.json",
+		"title":                "Booster Bootstrap Configuration",
+		"description":          "Schema for booster bootstrap.yaml configuration files",
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"version": map[string]any{
+				"type":        "string",
+				"enum":        []string{"1", "2"},
+				"description": `Configuration schema version; required in the root file, optional in included files, which default to the version of the file including them`,
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"pattern":     `^[^/\\]+$`,
+				"description": "Name of the config, under which the values of its variables are stored; defaults to the path of the config",
+			},
+			"store": map[string]any{
+				"type":        "string",
+				"description": "File to store the values of variables in, relative to this file; secret values are kept next to it with an .age extension",
+			},
+			"include": stringOrList("Files or globs to include, relative to this file. Their tasks are appended and their profiles and variables merged",
+				"File or glob to include", "Files or globs to include"),
+			"profiles": map[string]any{
+				"type":        "array",
+				"description": "List of available profile names for conditional task execution",
+				"items":       map[string]any{"type": "string"},
+				"examples":    []any{[]any{"personal", "work"}},
+			},
+			"variables": map[string]any{
+				"type":                 "object",
+				"description":          "Variable definitions for template rendering",
+				"additionalProperties": map[string]any{"$ref": "#/$defs/variable"},
+			},
+			"tasks": map[string]any{
+				"type":        "array",
+				"description": "List of tasks to execute",
+				// Version 1 tasks have an action field, version 2 tasks a
+				// key named after the action.
+				"items": map[string]any{
+					"if":   map[string]any{"required": []string{"action"}},
+					"then": map[string]any{"$ref": "#/$defs/task"},
+					"else": map[string]any{"$ref": "#/$defs/task-v2"},
+				},
+			},
+		},
+		"$defs": defs,
+	}
+}
+
+const exprDescription = "Strings may contain ${ } expressions; write $${ for a literal ${"
+
+func taskSchema(names []string, actions map[string]map[string]any) map[string]any {
+	var perAction []any
+	for _, name := range names {
+		if actions[name] == nil {
+			continue
+		}
+		perAction = append(perAction, map[string]any{
+			"if": map[string]any{
+				"properties": map[string]any{"action": map[string]any{"const": name}},
+				"required":   []any{"action"},
+			},
+			"then": map[string]any{
+				"properties": map[string]any{"args": map[string]any{"$ref": "#/$defs/" + argsDef(name)}},
+				"required":   []any{"args"},
+			},
+		})
+	}
+
+	properties := commonTaskProperties()
+	properties["action"] = map[string]any{
+		"type":        "string",
+		"enum":        names,
+		"description": "The action type to execute",
+	}
+	properties["id"] = describe("string", "Unique identifier other tasks can reference in depends_on")
+	properties["when"] = map[string]any{
+		"$ref":        "#/$defs/when",
+		"description": "Conditional execution based on OS, profile or an expression",
+	}
+	properties["args"] = map[string]any{"description": "Action-specific arguments. " + exprDescription}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"required":             []any{"action"},
+		"additionalProperties": false,
+		"properties":           properties,
+	}
+	if len(perAction) > 0 {
+		schema["allOf"] = perAction
+	}
+	return schema
+}
+
+func taskV2Schema(names []string, actions map[string]map[string]any) map[string]any {
+	properties := commonTaskProperties()
+	properties["name"] = describe("string", "Unique name other tasks can reference in depends_on and expressions")
+	properties["when"] = map[string]any{
+		"$ref":        "#/$defs/when-conditions",
+		"description": "Conditional execution based on OS, profile or an expression",
+	}
+
+	oneAction := make([]any, 0, len(names))
+	for _, name := range names {
+		args := map[string]any{"description": "Arguments of " + name + ". " + exprDescription}
+		if actions[name] != nil {
+			args["$ref"] = "#/$defs/" + argsDef(name)
+		}
+		properties[name] = args
+		oneAction = append(oneAction, map[string]any{"required": []string{name}})
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties":           properties,
+		"oneOf":                oneAction,
+	}
+}
+
+func commonTaskProperties() map[string]any {
+	return map[string]any{
+		"depends_on": stringOrList("Tasks that must run before this one",
+			"Id of a task that must complete first", "Ids of tasks that must complete first"),
+		"tags": stringOrList("Tags used to select tasks with --tags and --skip-tags",
+			"Tag", "Tags"),
+		"ignore_errors": describe("boolean", "Continue with dependent tasks even if this task fails"),
+		"retries": map[string]any{
+			"type":        "integer",
+			"minimum":     0,
+			"description": "Number of times to retry the task after a failure",
+		},
+		"retry_delay": map[string]any{
+			"type":        "string",
+			"pattern":     durationPattern,
+			"description": "Delay before the first retry, doubled after each attempt (e.g. 5s)",
+		},
+		"timeout": map[string]any{
+			"type":        "string",
+			"pattern":     durationPattern,
+			"description": "Maximum time a single attempt may run before it is cancelled (e.g. 10m)",
+		},
+		"for_each": map[string]any{
+			"oneOf": []any{
+				describe("array", "Run the task once per element"),
+				describe("object", "Run the task once per entry"),
+				describe("string", "Expression evaluating to a list or map"),
+			},
+			"description": "Expand the task once per element, available as item and key in expressions",
+		},
+	}
+}
+
+func argsDef(action string) string {
+	return "args-" + action
+}
+
+func describe(typ, description string) map[string]any {
+	return map[string]any{"type": typ, "description": description}
+}
+
+func stringOrList(description, single, list string) map[string]any {
+	return map[string]any{
+		"oneOf": []any{
+			describe("string", single),
+			map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": list,
+			},
+		},
+		"description": description,
+	}
+}
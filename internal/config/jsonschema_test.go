@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema_Actions(t *testing.T) {
+	doc := JSONSchema(map[string]map[string]any{
+		"dir.create": {"type": "array", "items": map[string]any{"type": "string"}},
+		"free.form":  nil,
+	})
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	schema, err := CompileSchema(data)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "described args",
+			content: "version: \"1\"\ntasks:\n  - action: dir.create\n    args: [a]\n",
+		},
+		{
+			name:    "described args are checked",
+			content: "version: \"1\"\ntasks:\n  - action: dir.create\n    args: [1]\n",
+			want:    []string{"config.yaml:4:12: got number, want string"},
+		},
+		{
+			name:    "undescribed action takes any args",
+			content: "version: \"1\"\ntasks:\n  - action: free.form\n    args: {x: 1}\n",
+		},
+		{
+			name:    "unregistered action",
+			content: "version: \"1\"\ntasks:\n  - action: other\n",
+			want:    []string{"config.yaml:3:13: value must be one of 'dir.create', 'free.form'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"config.yaml": tt.content})
+
+			errs, err := schema.Validate(Position{Path: filepath.Join(dir, "config.yaml"), File: "config.yaml"})
+			require.NoError(t, err)
+
+			var got []string
+			for _, e := range errs {
+				var perr *PositionError
+				require.ErrorAs(t, e, &perr)
+				got = append(got, perr.Pos.String()+": "+perr.Err.Error())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
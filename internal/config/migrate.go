@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrAlreadyMigrated = errors.New("config is already version 2")
+
+// Migrate works on the YAML nodes, so comments and the order of keys are kept.
+// A file without a version, such as an included file, does not gain one.
+func Migrate(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("config must be a mapping")
+	}
+	root := doc.Content[0]
+
+	if version := mappingValue(root, "version"); version != nil {
+		switch version.Value {
+		case "1":
+			version.Value = "2"
+			version.Style = yaml.DoubleQuotedStyle
+		case "2":
+			return nil, ErrAlreadyMigrated
+		default:
+			return nil, fmt.Errorf("unsupported config version: %s", version.Value)
+		}
+	}
+
+	if tasks := mappingValue(root, "tasks"); tasks != nil && tasks.Kind == yaml.SequenceNode {
+		for i, task := range tasks.Content {
+			if err := migrateTask(task); err != nil {
+				return nil, fmt.Errorf("line %d: task %d: %w", task.Line, i+1, err)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func migrateTask(task *yaml.Node) error {
+	if task.Kind != yaml.MappingNode {
+		return errors.New("must be a mapping")
+	}
+
+	var name, action, args []*yaml.Node
+	var rest []*yaml.Node
+	actionAt := -1
+	for i := 0; i+1 < len(task.Content); i += 2 {
+		key, value := task.Content[i], task.Content[i+1]
+		switch key.Value {
+		case "id":
+			key.Value = "name"
+			name = []*yaml.Node{key, value}
+		case "action":
+			action = []*yaml.Node{key, value}
+			actionAt = len(rest)
+		case "args":
+			args = []*yaml.Node{key, value}
+		case "when":
+			if value.Kind == yaml.ScalarNode {
+				expr := *value
+				*value = yaml.Node{
+					Kind: yaml.MappingNode,
+					Tag:  "!!map",
+					Content: []*yaml.Node{
+						{Kind: yaml.ScalarNode, Tag: "!!str", Value: "expr"},
+						&expr,
+					},
+				}
+			}
+			rest = append(rest, key, value)
+		default:
+			rest = append(rest, key, value)
+		}
+	}
+	if action == nil || action[1].Value == "" {
+		return errors.New("action cannot be empty")
+	}
+
+	// The action key takes over the comments of both keys it replaces.
+	actionKey := action[0]
+	actionKey.Value = action[1].Value
+	actionKey.LineComment = joinComments(actionKey.LineComment, action[1].LineComment)
+	argsValue := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	if args != nil {
+		actionKey.HeadComment = joinComments(actionKey.HeadComment, args[0].HeadComment)
+		actionKey.LineComment = joinComments(actionKey.LineComment, args[0].LineComment)
+		actionKey.FootComment = joinComments(actionKey.FootComment, args[0].FootComment)
+		argsValue = args[1]
+	}
+
+	content := append([]*yaml.Node{}, name...)
+	content = append(content, rest[:actionAt]...)
+	content = append(content, actionKey, argsValue)
+	content = append(content, rest[actionAt:]...)
+	task.Content = content
+	return nil
+}
+
+func joinComments(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + "\n" + b
+}
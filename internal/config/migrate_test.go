@@ -0,0 +1,113 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	v1 := `# Machine setup
+version: "1" # keep in sync
+profiles: [work]
+tasks:
+  # Directories first
+  - action: dir.create # base dirs
+    args:
+      - ~/a # first
+      - ~/b
+    id: dirs
+    when: "${ os == 'darwin' }"
+  - action: symlink.create
+    depends_on: dirs
+    args:
+      - source: a
+        target: b
+    when:
+      profile: work
+  - action: mise.use
+`
+
+	got, err := Migrate([]byte(v1))
+	require.NoError(t, err)
+
+	assert.Equal(t, `# Machine setup
+version: "2" # keep in sync
+profiles: [work]
+tasks:
+  # Directories first
+  - name: dirs
+    dir.create: # base dirs
+      - ~/a # first
+      - ~/b
+    when:
+      expr: "${ os == 'darwin' }"
+  - symlink.create:
+      - source: a
+        target: b
+    depends_on: dirs
+    when:
+      profile: work
+  - mise.use:
+`, string(got))
+}
+
+func TestMigrate_LoadsLikeTheOriginal(t *testing.T) {
+	v1 := `version: "1"
+tasks:
+  - action: dir.create
+    id: dirs
+    args: [a]
+    tags: [base]
+    retries: 1
+  - action: symlink.create
+    depends_on: dirs
+    args:
+      - source: a
+        target: b
+    when: "${ true }"
+`
+	v2, err := Migrate([]byte(v1))
+	require.NoError(t, err)
+
+	dir := writeFiles(t, map[string]string{"v1.yaml": v1, "v2.yaml": string(v2)})
+	want, err := Load(filepath.Join(dir, "v1.yaml"))
+	require.NoError(t, err)
+	got, err := Load(filepath.Join(dir, "v2.yaml"))
+	require.NoError(t, err)
+
+	require.Len(t, got.Tasks, len(want.Tasks))
+	for i := range want.Tasks {
+		w, g := want.Tasks[i], got.Tasks[i]
+		assert.Equal(t, w.Action, g.Action)
+		assert.Equal(t, w.Args, g.Args)
+		assert.Equal(t, w.ID, g.ID)
+		assert.Equal(t, w.DependsOn, g.DependsOn)
+		assert.Equal(t, w.Tags, g.Tags)
+		assert.Equal(t, w.Retries, g.Retries)
+		assert.Equal(t, w.When, g.When)
+	}
+}
+
+func TestMigrate_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "already version 2", content: "version: \"2\"\ntasks: []\n", wantErr: ErrAlreadyMigrated.Error()},
+		{name: "unsupported version", content: "version: \"7\"\n", wantErr: "unsupported config version: 7"},
+		{name: "not a mapping", content: "- a\n", wantErr: "config must be a mapping"},
+		{name: "task without action", content: "version: \"1\"\ntasks:\n  - args: [a]\n", wantErr: "line 3: task 1: action cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Migrate([]byte(tt.content))
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
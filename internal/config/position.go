@@ -0,0 +1,88 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position.Line and Column are 1-based and zero when unknown.
+type Position struct {
+	// Path is absolute; File is the path shown in messages.
+	Path   string
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	if p.Line == 0 {
+		return p.File
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+func (p Position) at(node *yaml.Node) Position {
+	p.Line, p.Column = node.Line, node.Column
+	return p
+}
+
+type PositionError struct {
+	Pos     Position
+	Err     error
+	Snippet string
+}
+
+// Without a known file ErrorAt returns err unchanged.
+func ErrorAt(pos Position, err error) error {
+	if pos.File == "" {
+		return err
+	}
+	return &PositionError{Pos: pos, Err: err, Snippet: snippet(pos)}
+}
+
+func (e *PositionError) Error() string {
+	msg := e.Pos.String() + ": " + e.Err.Error()
+	if e.Snippet != "" {
+		msg += "\n" + e.Snippet
+	}
+	return msg
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+func snippet(pos Position) string {
+	if pos.Path == "" || pos.Line == 0 {
+		return ""
+	}
+	data, err := os.ReadFile(pos.Path)
+	if err != nil {
+		return ""
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	if pos.Line > len(lines) {
+		return ""
+	}
+	line := strings.TrimRight(string(lines[pos.Line-1]), "\r")
+
+	// Keep tabs so the caret lines up with the source in a terminal.
+	var pad strings.Builder
+	for i, r := range []rune(line) {
+		if i >= pos.Column-1 {
+			break
+		}
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+
+	gutter := fmt.Sprintf("%d", pos.Line)
+	return fmt.Sprintf("  %s | %s\n  %s | %s^", gutter, line, strings.Repeat(" ", len(gutter)), pad.String())
+}
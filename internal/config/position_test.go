@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const positionConfig = `version: "1"
+tasks:
+  - action: symlink.create
+    retries: 2
+    args:
+      - source: a
+        target: b
+      - source: c
+        target: 3
+  - action: darwin.defaults
+    args:
+      file: macos.yaml
+  - action: dir.create
+`
+
+func loadPositionConfig(t *testing.T) *Config {
+	t.Helper()
+
+	dir := writeFiles(t, map[string]string{"config.yaml": positionConfig})
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestTask_Pos(t *testing.T) {
+	cfg := loadPositionConfig(t)
+
+	tests := []struct {
+		name string
+		task int
+		key  string
+		want string
+	}{
+		{name: "field value", task: 0, key: "retries", want: "config.yaml:4:14"},
+		{name: "args", task: 0, key: "args", want: "config.yaml:6:7"},
+		{name: "missing field falls back to the task", task: 2, key: "args", want: "config.yaml:13:5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cfg.Tasks[tt.task].Pos(tt.key).String())
+		})
+	}
+}
+
+func TestTask_ArgPos(t *testing.T) {
+	cfg := loadPositionConfig(t)
+
+	tests := []struct {
+		name  string
+		task  int
+		index int
+		key   string
+		want  string
+	}{
+		{name: "list element", task: 0, index: 1, want: "config.yaml:8:9"},
+		{name: "field of list element", task: 0, index: 1, key: "target", want: "config.yaml:9:17"},
+		{name: "field of args map", task: 1, index: -1, key: "file", want: "config.yaml:12:13"},
+		{name: "missing field falls back to the element", task: 0, index: 0, key: "mode", want: "config.yaml:6:9"},
+		{name: "index out of range falls back to args", task: 0, index: 5, want: "config.yaml:6:7"},
+		{name: "missing args falls back to the task", task: 2, index: 0, want: "config.yaml:13:5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cfg.Tasks[tt.task].ArgPos(tt.index, tt.key).String())
+		})
+	}
+}
+
+func TestErrorAt(t *testing.T) {
+	cfg := loadPositionConfig(t)
+	cause := errors.New("must be a string")
+
+	err := ErrorAt(cfg.Tasks[0].ArgPos(1, "target"), cause)
+
+	assert.Equal(t, "config.yaml:9:17: must be a string\n"+
+		"  9 |         target: 3\n"+
+		"    |                 ^", err.Error())
+	assert.ErrorIs(t, err, cause)
+
+	var perr *PositionError
+	require.ErrorAs(t, err, &perr)
+	assert.Equal(t, 9, perr.Pos.Line)
+}
+
+func TestErrorAt_WithoutFile(t *testing.T) {
+	cause := errors.New("boom")
+
+	assert.Same(t, cause, ErrorAt(Position{}, cause))
+}
+
+func TestErrorAt_UnreadableFileHasNoSnippet(t *testing.T) {
+	pos := Position{Path: filepath.Join(t.TempDir(), "gone.yaml"), File: "gone.yaml", Line: 3, Column: 1}
+
+	assert.Equal(t, "gone.yaml:3:1: boom", ErrorAt(pos, errors.New("boom")).Error())
+}
+
+func TestLoad_TaskErrorsShowPosition(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"config.yaml": `version: "1"
+tasks:
+  - action: dir.create
+    retries: -1
+`})
+
+	_, err := Load(filepath.Join(dir, "config.yaml"))
+
+	require.Error(t, err)
+	assert.Equal(t, "config.yaml:4:14: task 1: retries cannot be negative\n"+
+		"  4 |     retries: -1\n"+
+		"    |              ^", err.Error())
+}
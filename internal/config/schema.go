@@ -0,0 +1,156 @@
+package config
+
+import (
+	"booster/internal/expr"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+)
+
+const schemaURL = "booster://schema.json"
+
+type Schema struct {
+	schema *jsonschema.Schema
+}
+
+func CompileSchema(data []byte) (*Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("load schema: %w", err)
+	}
+	schema, err := c.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("compile schema: %w", err)
+	}
+	return &Schema{schema: schema}, nil
+}
+
+func (s *Schema) Validate(file Position) ([]error, error) {
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	doc, err := parseFile(file, data)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := doc.Decode(&value); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", file.File, err)
+	}
+
+	err = s.schema.Validate(value)
+	if err == nil {
+		return nil, nil
+	}
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	var errs []error
+	p := message.NewPrinter(language.English)
+	var collect func(e *jsonschema.ValidationError)
+	collect = func(e *jsonschema.ValidationError) {
+		if inArgs(e.InstanceLocation) && isExpression(nodeAt(root, e.InstanceLocation)) {
+			return
+		}
+		switch k := e.ErrorKind.(type) {
+		case *kind.OneOf, *kind.AnyOf:
+			// The causes are one failure per alternative, which says
+			// little about what the value was meant to be.
+			node := nodeAt(root, e.InstanceLocation)
+			errs = append(errs, ErrorAt(file.at(node), errors.New("value does not match any allowed form")))
+			return
+		case *kind.AdditionalProperties:
+			node := nodeAt(root, e.InstanceLocation)
+			for _, prop := range k.Properties {
+				errs = append(errs, ErrorAt(file.at(keyNode(node, prop)), fmt.Errorf("unknown field %q", prop)))
+			}
+			return
+		}
+		if len(e.Causes) == 0 {
+			node := nodeAt(root, e.InstanceLocation)
+			errs = append(errs, ErrorAt(file.at(node), errors.New(e.ErrorKind.LocalizedString(p))))
+			return
+		}
+		for _, cause := range e.Causes {
+			collect(cause)
+		}
+	}
+	collect(verr)
+	return errs, nil
+}
+
+// inArgs reports whether the JSON pointer tokens lead into the args of a
+// task: its args field in version 1, or its action key in version 2.
+func inArgs(tokens []string) bool {
+	if len(tokens) < 3 || tokens[0] != "tasks" {
+		return false
+	}
+	switch tokens[2] {
+	case "args":
+		return true
+	case "action", "id", "name", "when":
+		return false
+	}
+	_, common := commonTaskProperties()[tokens[2]]
+	return !common
+}
+
+// A single expression in args may evaluate to a value of any type, which is
+// only known when the task runs.
+func isExpression(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!str" && expr.IsExpression(node.Value)
+}
+
+// nodeAt stops at the deepest node of the JSON pointer that exists.
+func nodeAt(node *yaml.Node, tokens []string) *yaml.Node {
+	for _, tok := range tokens {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			next = mappingValue(node, tok)
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(tok); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return node
+}
+
+func keyNode(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return node
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return node
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "version": { "type": "string" },
+    "tasks": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "retries": { "type": "integer" },
+          "tags": { "oneOf": [{ "type": "string" }, { "type": "array" }] }
+        }
+      }
+    }
+  }
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := CompileSchema([]byte(testSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "valid",
+			content: "version: \"1\"\ntasks:\n  - retries: 2\n",
+		},
+		{
+			name:    "wrong type points at the value",
+			content: "version: \"1\"\ntasks:\n  - retries: two\n",
+			want:    []string{"config.yaml:3:14: got string, want integer"},
+		},
+		{
+			name:    "unknown field points at the key",
+			content: "version: \"1\"\nverison: \"1\"\n",
+			want:    []string{`config.yaml:2:1: unknown field "verison"`},
+		},
+		{
+			name:    "no alternative matches",
+			content: "version: \"1\"\ntasks:\n  - tags: 3\n",
+			want:    []string{"config.yaml:3:11: value does not match any allowed form"},
+		},
+		{
+			name:    "every violation is reported",
+			content: "version: 1\ntasks:\n  - retries: two\n",
+			want: []string{
+				"config.yaml:1:10: got number, want string",
+				"config.yaml:3:14: got string, want integer",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"config.yaml": tt.content})
+
+			errs, err := schema.Validate(Position{Path: filepath.Join(dir, "config.yaml"), File: "config.yaml"})
+			require.NoError(t, err)
+
+			var got []string
+			for _, e := range errs {
+				var perr *PositionError
+				require.ErrorAs(t, e, &perr)
+				got = append(got, perr.Pos.String()+": "+perr.Err.Error())
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompileSchema_InvalidJSON(t *testing.T) {
+	_, err := CompileSchema([]byte("{"))
+
+	assert.ErrorContains(t, err, "parse schema")
+}
+
+func TestLoad_ListsFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "version: \"1\"\ninclude: more.yaml\ntasks: []\n",
+		"more.yaml":   "tasks: []\n",
+	})
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Position{
+		{Path: filepath.Join(dir, "config.yaml"), File: "config.yaml"},
+		{Path: filepath.Join(dir, "more.yaml"), File: "more.yaml"},
+	}, cfg.Files)
+}
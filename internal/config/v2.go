@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type taskV2 struct {
+	Name         string        `yaml:"name,omitempty"`
+	DependsOn    StringOrSlice `yaml:"depends_on,omitempty"`
+	Tags         StringOrSlice `yaml:"tags,omitempty"`
+	When         *whenV2       `yaml:"when,omitempty"`
+	ForEach      any           `yaml:"for_each,omitempty"`
+	IgnoreErrors bool          `yaml:"ignore_errors,omitempty"`
+	Retries      int           `yaml:"retries,omitempty"`
+	RetryDelay   time.Duration `yaml:"retry_delay,omitempty"`
+	Timeout      time.Duration `yaml:"timeout,omitempty"`
+
+	// Actions holds every other key. Exactly one is expected.
+	Actions map[string]any `yaml:",inline"`
+}
+
+type whenV2 struct {
+	OS      StringOrSlice `yaml:"os,omitempty"`
+	Profile StringOrSlice `yaml:"profile,omitempty"`
+	Expr    string        `yaml:"expr,omitempty"`
+}
+
+func (w *whenV2) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.New("when must be a mapping of os, profile and expr")
+	}
+	type plain whenV2
+	return node.Decode((*plain)(w))
+}
+
+func decodeV2(doc *yaml.Node, cfg *Config, file Position) error {
+	var v struct {
+		Version   string                 `yaml:"version"`
+		Name      string                 `yaml:"name,omitempty"`
+		Store     string                 `yaml:"store,omitempty"`
+		Include   StringOrSlice          `yaml:"include,omitempty"`
+		Profiles  []string               `yaml:"profiles,omitempty"`
+		Variables map[string]VariableDef `yaml:"variables,omitempty"`
+		Tasks     []yaml.Node            `yaml:"tasks"`
+	}
+	if err := doc.Decode(&v); err != nil {
+		return fmt.Errorf("parse config %s: %w", file.File, err)
+	}
+
+	cfg.Version = v.Version
+	cfg.Name = v.Name
+	cfg.Store = v.Store
+	cfg.Include = v.Include
+	cfg.Profiles = v.Profiles
+	cfg.Variables = v.Variables
+	cfg.Tasks = make([]Task, len(v.Tasks))
+
+	for i := range v.Tasks {
+		node := &v.Tasks[i]
+		task := &cfg.Tasks[i]
+		task.node = node
+		task.locate(file, i)
+
+		var v2 taskV2
+		if err := node.Decode(&v2); err != nil {
+			return ErrorAt(task.Origin.Position, fmt.Errorf("task %d: %w", task.Origin.Index, err))
+		}
+
+		for _, key := range []string{"action", "args", "id"} {
+			if _, ok := v2.Actions[key]; ok {
+				return ErrorAt(task.Pos(key), fmt.Errorf("task %d: %q is a version 1 field, run booster migrate to convert the config", task.Origin.Index, key))
+			}
+		}
+
+		actions := slices.Sorted(maps.Keys(v2.Actions))
+		switch len(actions) {
+		case 0:
+			return ErrorAt(task.Origin.Position, fmt.Errorf("task %d: no action", task.Origin.Index))
+		case 1:
+		default:
+			return ErrorAt(task.Origin.Position, fmt.Errorf("task %d: more than one action: %s", task.Origin.Index, strings.Join(actions, ", ")))
+		}
+
+		task.Action = actions[0]
+		task.Args = v2.Actions[task.Action]
+		task.argsKey = task.Action
+		task.ID = v2.Name
+		task.DependsOn = v2.DependsOn
+		task.Tags = v2.Tags
+		task.ForEach = v2.ForEach
+		task.IgnoreErrors = v2.IgnoreErrors
+		task.Retries = v2.Retries
+		task.RetryDelay = v2.RetryDelay
+		task.Timeout = v2.Timeout
+		if v2.When != nil {
+			task.When = &When{OS: v2.When.OS, Profile: v2.When.Profile, Expr: v2.When.Expr}
+		}
+	}
+	return nil
+}
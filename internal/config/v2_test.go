@@ -0,0 +1,121 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Version2(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"config.yaml": `version: "2"
+profiles: [work]
+tasks:
+  - name: dirs
+    dir.create: [~/a, ~/b]
+    tags: base
+  - symlink.create:
+      - source: a
+        target: b
+    depends_on: dirs
+    retries: 2
+    timeout: 1m
+    when:
+      profile: work
+      expr: "${ arch == 'arm64' }"
+`})
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Tasks, 2)
+	assert.Equal(t, "dir.create", cfg.Tasks[0].Action)
+	assert.Equal(t, []any{"~/a", "~/b"}, cfg.Tasks[0].Args)
+	assert.Equal(t, "dirs", cfg.Tasks[0].ID)
+	assert.Equal(t, StringOrSlice{"base"}, cfg.Tasks[0].Tags)
+
+	second := cfg.Tasks[1]
+	assert.Equal(t, "symlink.create", second.Action)
+	assert.Equal(t, StringOrSlice{"dirs"}, second.DependsOn)
+	assert.Equal(t, 2, second.Retries)
+	assert.Equal(t, time.Minute, second.Timeout)
+	assert.Equal(t, &When{Profile: StringOrSlice{"work"}, Expr: "${ arch == 'arm64' }"}, second.When)
+}
+
+func TestLoad_Version2Positions(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"config.yaml": `version: "2"
+tasks:
+  - name: links
+    symlink.create:
+      - source: a
+        target: b
+`})
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+
+	task := cfg.Tasks[0]
+	assert.Equal(t, "config.yaml:5:7", task.Pos("args").String())
+	assert.Equal(t, "config.yaml:4:5", task.Pos("action").String())
+	assert.Equal(t, "config.yaml:3:11", task.Pos("id").String())
+	assert.Equal(t, "config.yaml:6:17", task.ArgPos(0, "target").String())
+}
+
+func TestLoad_Version2Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "no action",
+			files:   map[string]string{"config.yaml": "version: \"2\"\ntasks:\n  - name: x\n"},
+			wantErr: "config.yaml:3:5: task 1: no action",
+		},
+		{
+			name:    "two actions",
+			files:   map[string]string{"config.yaml": "version: \"2\"\ntasks:\n  - dir.create: [a]\n    mise.use: [go@1]\n"},
+			wantErr: "config.yaml:3:5: task 1: more than one action: dir.create, mise.use",
+		},
+		{
+			name:    "when expression string",
+			files:   map[string]string{"config.yaml": "version: \"2\"\ntasks:\n  - dir.create: [a]\n    when: \"${ true }\"\n"},
+			wantErr: "when must be a mapping of os, profile and expr",
+		},
+		{
+			name: "include without version inherits version 2",
+			files: map[string]string{
+				"config.yaml": "version: \"2\"\ninclude: a.yaml\ntasks: []\n",
+				"a.yaml":      "tasks:\n  - action: dir.create\n",
+			},
+			wantErr: `a.yaml:2:13: task 1: "action" is a version 1 field`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+
+			_, err := Load(filepath.Join(dir, "config.yaml"))
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoad_MixedVersions(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "version: \"1\"\ninclude: a.yaml\ntasks:\n  - action: dir.create\n    args: [one]\n",
+		"a.yaml":      "version: \"2\"\ntasks:\n  - dir.create: [two]\n",
+	})
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Tasks, 2)
+	assert.Equal(t, []any{"one"}, cfg.Tasks[0].Args)
+	assert.Equal(t, []any{"two"}, cfg.Tasks[1].Args)
+}
@@ -2,6 +2,7 @@ package coordinator
 
 import (
 	"booster/internal/task"
+	"sort"
 )
 
 type TaskCompleteMsg struct {
@@ -10,76 +11,106 @@ type TaskCompleteMsg struct {
 	Logs      []string
 }
 
-type Coordinator struct {
-	logHistory  map[int][]string
-	currentLogs []string
-	currentTask int
-
+type taskState struct {
+	logs          []string
 	pendingResult *task.Result
 	logsDone      bool
 }
 
+type Coordinator struct {
+	logHistory map[int][]string
+	active     map[int]*taskState
+	completed  map[int]bool
+}
+
 func New() *Coordinator {
 	return &Coordinator{
 		logHistory: make(map[int][]string),
+		active:     make(map[int]*taskState),
+		completed:  make(map[int]bool),
 	}
 }
 
 func (c *Coordinator) StartTask(taskIndex int) {
-	c.currentTask = taskIndex
-	c.currentLogs = nil
-	c.pendingResult = nil
-	c.logsDone = false
+	delete(c.completed, taskIndex)
+	c.active[taskIndex] = &taskState{}
 }
 
-func (c *Coordinator) AddLogLine(line string) {
-	c.currentLogs = append(c.currentLogs, line)
+func (c *Coordinator) state(taskIndex int) *taskState {
+	if c.completed[taskIndex] {
+		return nil
+	}
+	s, ok := c.active[taskIndex]
+	if !ok {
+		s = &taskState{}
+		c.active[taskIndex] = s
+	}
+	return s
 }
 
-func (c *Coordinator) CurrentLogs() []string {
-	return c.currentLogs
+func (c *Coordinator) AddLogLine(taskIndex int, line string) {
+	if s := c.state(taskIndex); s != nil {
+		s.logs = append(s.logs, line)
+	}
+}
+
+func (c *Coordinator) Active() []int {
+	idx := make([]int, 0, len(c.active))
+	for i := range c.active {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
 }
 
 func (c *Coordinator) LogsFor(taskIndex int) []string {
+	if s, ok := c.active[taskIndex]; ok {
+		return s.logs
+	}
 	return c.logHistory[taskIndex]
 }
 
-func (c *Coordinator) LogsDone() *TaskCompleteMsg {
-	c.logsDone = true
+func (c *Coordinator) LogsDone(taskIndex int) *TaskCompleteMsg {
+	s := c.state(taskIndex)
+	if s == nil {
+		return nil
+	}
+	s.logsDone = true
 
-	if c.pendingResult != nil {
-		return c.complete(*c.pendingResult)
+	if s.pendingResult != nil {
+		return c.complete(taskIndex, *s.pendingResult)
 	}
 	return nil
 }
 
-func (c *Coordinator) TaskDone(result task.Result) *TaskCompleteMsg {
-	if c.pendingResult == nil && !c.logsDone {
-		c.pendingResult = &result
+func (c *Coordinator) TaskDone(taskIndex int, result task.Result) *TaskCompleteMsg {
+	s := c.state(taskIndex)
+	if s == nil {
+		return nil
+	}
+	if s.pendingResult == nil && !s.logsDone {
+		s.pendingResult = &result
 		return nil
 	}
 
-	if !c.logsDone {
+	if !s.logsDone {
 		return nil
 	}
 
-	return c.complete(result)
+	return c.complete(taskIndex, result)
 }
 
-func (c *Coordinator) complete(result task.Result) *TaskCompleteMsg {
-	if len(c.currentLogs) > 0 {
-		c.logHistory[c.currentTask] = c.currentLogs
+func (c *Coordinator) complete(taskIndex int, result task.Result) *TaskCompleteMsg {
+	s := c.active[taskIndex]
+	if len(s.logs) > 0 {
+		c.logHistory[taskIndex] = s.logs
 	}
+	delete(c.active, taskIndex)
+	c.completed[taskIndex] = true
 
-	msg := &TaskCompleteMsg{
-		TaskIndex: c.currentTask,
+	return &TaskCompleteMsg{
+		TaskIndex: taskIndex,
 		Result:    result,
-		Logs:      c.currentLogs,
+		Logs:      s.logs,
 	}
-
-	c.currentLogs = nil
-	c.pendingResult = nil
-	c.logsDone = false
-
-	return msg
 }
@@ -41,13 +41,13 @@ func TestCoordinator_TaskCompletes_WhenLogsFinishFirst(t *testing.T) {
 			c.StartTask(0)
 
 			for _, line := range tt.logLines {
-				c.AddLogLine(line)
+				c.AddLogLine(0, line)
 			}
 
-			msg := c.LogsDone()
+			msg := c.LogsDone(0)
 			assert.Nil(t, msg, "LogsDone alone should not complete task")
 
-			msg = c.TaskDone(tt.taskResult)
+			msg = c.TaskDone(0, tt.taskResult)
 			require.NotNil(t, msg, "TaskDone after LogsDone should complete task")
 
 			assert.Equal(t, tt.taskResult.Status, msg.Result.Status)
@@ -61,16 +61,16 @@ func TestCoordinator_TaskCompletes_WhenTaskFinishesFirst(t *testing.T) {
 	c := New()
 	c.StartTask(0)
 
-	c.AddLogLine("early log")
+	c.AddLogLine(0, "early log")
 
-	msg := c.TaskDone(task.Result{Status: task.StatusDone})
+	msg := c.TaskDone(0, task.Result{Status: task.StatusDone})
 	assert.Nil(t, msg, "TaskDone before LogsDone should not complete task")
 
-	c.AddLogLine("late log")
+	c.AddLogLine(0, "late log")
 
-	assert.Len(t, c.CurrentLogs(), 2)
+	assert.Len(t, c.LogsFor(0), 2)
 
-	msg = c.LogsDone()
+	msg = c.LogsDone(0)
 	require.NotNil(t, msg, "LogsDone after TaskDone should complete task")
 
 	logs := c.LogsFor(0)
@@ -83,19 +83,19 @@ func TestCoordinator_LogHistory_PersistsAcrossTasks(t *testing.T) {
 	c := New()
 
 	c.StartTask(0)
-	c.AddLogLine("task0-line1")
-	c.AddLogLine("task0-line2")
-	c.LogsDone()
-	c.TaskDone(task.Result{Status: task.StatusDone})
+	c.AddLogLine(0, "task0-line1")
+	c.AddLogLine(0, "task0-line2")
+	c.LogsDone(0)
+	c.TaskDone(0, task.Result{Status: task.StatusDone})
 
 	c.StartTask(1)
-	c.AddLogLine("task1-line1")
-	c.LogsDone()
-	c.TaskDone(task.Result{Status: task.StatusDone})
+	c.AddLogLine(1, "task1-line1")
+	c.LogsDone(1)
+	c.TaskDone(1, task.Result{Status: task.StatusDone})
 
 	c.StartTask(2)
-	c.LogsDone()
-	c.TaskDone(task.Result{Status: task.StatusSkipped})
+	c.LogsDone(2)
+	c.TaskDone(2, task.Result{Status: task.StatusSkipped})
 
 	logs0 := c.LogsFor(0)
 	require.Len(t, logs0, 2)
@@ -110,27 +110,27 @@ func TestCoordinator_LogHistory_PersistsAcrossTasks(t *testing.T) {
 	assert.Empty(t, logs2)
 }
 
-func TestCoordinator_CurrentLogs_ReturnsAccumulator(t *testing.T) {
+func TestCoordinator_LogsFor_ReturnsLiveLogsOfActiveTask(t *testing.T) {
 	c := New()
 	c.StartTask(0)
 
-	assert.Empty(t, c.CurrentLogs())
+	assert.Empty(t, c.LogsFor(0))
 
-	c.AddLogLine("line1")
-	assert.Equal(t, []string{"line1"}, c.CurrentLogs())
+	c.AddLogLine(0, "line1")
+	assert.Equal(t, []string{"line1"}, c.LogsFor(0))
 
-	c.AddLogLine("line2")
-	assert.Equal(t, []string{"line1", "line2"}, c.CurrentLogs())
+	c.AddLogLine(0, "line2")
+	assert.Equal(t, []string{"line1", "line2"}, c.LogsFor(0))
 }
 
 func TestCoordinator_TaskFailure_PreservesLogs(t *testing.T) {
 	c := New()
 	c.StartTask(0)
 
-	c.AddLogLine("before failure")
-	c.AddLogLine("error output")
-	c.LogsDone()
-	msg := c.TaskDone(task.Result{Status: task.StatusFailed, Message: "something broke"})
+	c.AddLogLine(0, "before failure")
+	c.AddLogLine(0, "error output")
+	c.LogsDone(0)
+	msg := c.TaskDone(0, task.Result{Status: task.StatusFailed, Message: "something broke"})
 
 	require.NotNil(t, msg)
 	assert.Equal(t, task.StatusFailed, msg.Result.Status)
@@ -146,17 +146,42 @@ func TestCoordinator_StartTask_ResetsState(t *testing.T) {
 	c := New()
 
 	c.StartTask(0)
-	c.AddLogLine("task0 log")
-	c.LogsDone()
-	c.TaskDone(task.Result{Status: task.StatusDone})
+	c.AddLogLine(0, "task0 log")
+	c.LogsDone(0)
+	c.TaskDone(0, task.Result{Status: task.StatusDone})
 
 	c.StartTask(1)
 
-	assert.Empty(t, c.CurrentLogs())
+	assert.Empty(t, c.LogsFor(1))
+	assert.Equal(t, []int{1}, c.Active())
 
 	assert.Len(t, c.LogsFor(0), 1)
 }
 
+func TestCoordinator_ConcurrentTasks_KeepSeparateLogs(t *testing.T) {
+	c := New()
+	c.StartTask(0)
+	c.StartTask(2)
+
+	c.AddLogLine(0, "a1")
+	c.AddLogLine(2, "b1")
+	c.AddLogLine(0, "a2")
+
+	assert.Equal(t, []int{0, 2}, c.Active())
+	assert.Equal(t, []string{"a1", "a2"}, c.LogsFor(0))
+	assert.Equal(t, []string{"b1"}, c.LogsFor(2))
+
+	c.LogsDone(2)
+	msg := c.TaskDone(2, task.Result{Status: task.StatusDone})
+	require.NotNil(t, msg)
+	assert.Equal(t, 2, msg.TaskIndex)
+	assert.Equal(t, []string{"b1"}, msg.Logs)
+
+	assert.Equal(t, []int{0}, c.Active())
+	assert.Equal(t, []string{"a1", "a2"}, c.LogsFor(0))
+	assert.Equal(t, []string{"b1"}, c.LogsFor(2))
+}
+
 func TestCoordinator_LogsFor_ReturnsNilForUnknownTask(t *testing.T) {
 	c := New()
 
@@ -167,11 +192,11 @@ func TestCoordinator_LogsFor_ReturnsNilForUnknownTask(t *testing.T) {
 func TestCoordinator_TaskCompleteMsg_ContainsLogs(t *testing.T) {
 	c := New()
 	c.StartTask(0)
-	c.AddLogLine("log1")
-	c.AddLogLine("log2")
-	c.LogsDone()
+	c.AddLogLine(0, "log1")
+	c.AddLogLine(0, "log2")
+	c.LogsDone(0)
 
-	msg := c.TaskDone(task.Result{Status: task.StatusDone})
+	msg := c.TaskDone(0, task.Result{Status: task.StatusDone})
 
 	require.NotNil(t, msg)
 	require.Len(t, msg.Logs, 2)
@@ -182,12 +207,14 @@ func TestCoordinator_TaskCompleteMsg_ContainsLogs(t *testing.T) {
 func TestCoordinator_DoubleCompletion_Handled(t *testing.T) {
 	c := New()
 	c.StartTask(0)
-	c.AddLogLine("log")
-	c.LogsDone()
+	c.AddLogLine(0, "log")
+	c.LogsDone(0)
 
-	msg1 := c.TaskDone(task.Result{Status: task.StatusDone})
+	msg1 := c.TaskDone(0, task.Result{Status: task.StatusDone})
 	require.NotNil(t, msg1)
 
-	msg2 := c.TaskDone(task.Result{Status: task.StatusFailed})
+	msg2 := c.TaskDone(0, task.Result{Status: task.StatusFailed})
 	assert.Nil(t, msg2, "Second TaskDone should return nil")
+	assert.Empty(t, c.Active())
+	assert.Equal(t, []string{"log"}, c.LogsFor(0))
 }
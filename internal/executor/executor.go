@@ -1,36 +1,199 @@
 package executor
 
 import (
+	"booster/internal/backup"
+	"booster/internal/journal"
+	"booster/internal/logstream"
+	"booster/internal/redact"
 	"booster/internal/task"
 	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
+const defaultRetryDelay = time.Second
+
 type Summary struct {
 	Done        int
 	Skipped     int
 	Failed      int
+	Ignored     int
+	TimedOut    int
+	Cancelled   int
 	Pending     int
 	HasFailures bool
 }
 
+type Option func(*Executor)
+
+func WithJobs(n int) Option {
+	return func(e *Executor) {
+		if n > 0 {
+			e.jobs = n
+		}
+	}
+}
+
+func WithKeepGoing(keepGoing bool) Option {
+	return func(e *Executor) {
+		e.keepGoing = keepGoing
+	}
+}
+
+// WithTimeout applies to tasks that do not set their own timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(e *Executor) {
+		e.timeout = d
+	}
+}
+
+func WithDiff(showDiff bool) Option {
+	return func(e *Executor) {
+		e.showDiff = showDiff
+	}
+}
+
+func WithBackup(b *backup.Backup) Option {
+	return func(e *Executor) {
+		e.backup = b
+	}
+}
+
+func WithJournal(j *journal.Journal) Option {
+	return func(e *Executor) {
+		e.journal = j
+	}
+}
+
+func WithRedactor(r *redact.Redactor) Option {
+	return func(e *Executor) {
+		e.redactor = r
+	}
+}
+
+// WithResume skips tasks that succeeded in prev with the same fingerprint. It
+// only applies to executors created with NewGraph.
+func WithResume(prev *journal.Run) Option {
+	return func(e *Executor) {
+		e.resume = prev
+	}
+}
+
 type Executor struct {
-	tasks     []task.Task
-	results   []task.Result
-	current   int
-	aborted   bool
-	startTime time.Time
-	endTime   time.Time
+	mu sync.Mutex
+
+	tasks        []task.Task
+	deps         [][]int
+	ignoreErrors []bool
+	retries      []int
+	retryDelays  []time.Duration
+	timeouts     []time.Duration
+	fingerprints []string
+	ids          []string
+	resumed      map[int]string
+	results      []task.Result
+	running      map[int]bool
+	finished     []bool
+	blockedBy    []int
+	locked       map[string]int
+	idle         *sync.Cond
+	jobs         int
+	keepGoing    bool
+	timeout      time.Duration
+	showDiff     bool
+	journal      *journal.Journal
+	redactor     *redact.Redactor
+	backup       *backup.Backup
+	scope        *task.Scope
+	resume       *journal.Run
+	current      int
+	aborted      bool
+	startTime    time.Time
+	endTime      time.Time
 }
 
-func New(tasks []task.Task) *Executor {
+func New(tasks []task.Task, opts ...Option) *Executor {
 	results := make([]task.Result, len(tasks))
+	blockedBy := make([]int, len(tasks))
 	for i := range results {
 		results[i] = task.Result{Status: task.StatusPending}
+		blockedBy[i] = -1
 	}
-	return &Executor{
-		tasks:   tasks,
-		results: results,
+	e := &Executor{
+		tasks:        tasks,
+		deps:         make([][]int, len(tasks)),
+		ignoreErrors: make([]bool, len(tasks)),
+		retries:      make([]int, len(tasks)),
+		retryDelays:  make([]time.Duration, len(tasks)),
+		timeouts:     make([]time.Duration, len(tasks)),
+		fingerprints: make([]string, len(tasks)),
+		ids:          make([]string, len(tasks)),
+		resumed:      make(map[int]string),
+		results:      results,
+		running:      make(map[int]bool),
+		finished:     make([]bool, len(tasks)),
+		blockedBy:    blockedBy,
+		locked:       make(map[string]int),
+		jobs:         1,
+	}
+	e.idle = sync.NewCond(&e.mu)
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+func NewGraph(g *task.Graph, opts ...Option) *Executor {
+	e := New(g.Tasks(), opts...)
+	for i, n := range g.Nodes() {
+		e.deps[i] = n.Deps
+		e.ignoreErrors[i] = n.IgnoreErrors
+		e.retries[i] = n.Retries
+		e.retryDelays[i] = n.RetryDelay
+		e.timeouts[i] = n.Timeout
+		e.fingerprints[i] = n.Fingerprint
+		e.ids[i] = n.ID
+	}
+	e.scope = g.Scope()
+	e.applyResume()
+	return e
+}
+
+func (e *Executor) applyResume() {
+	if e.resume == nil {
+		return
+	}
+
+	for i, fp := range e.fingerprints {
+		if fp == "" {
+			continue
+		}
+		prev, ok := e.resume.Succeeded(fp)
+		if !ok {
+			continue
+		}
+		e.finished[i] = true
+		e.resumed[i] = prev.Status
+		e.results[i] = task.Result{
+			Status:  task.StatusSkipped,
+			Message: "completed in previous run",
+			Data:    prev.Output,
+		}
+		if e.journal != nil {
+			e.journal.Record(prev)
+		}
+	}
+	for i := range e.resumed {
+		e.publish(i)
+	}
+
+	for e.current < len(e.tasks) && e.finished[e.current] {
+		e.current++
 	}
 }
 
@@ -38,23 +201,56 @@ func (e *Executor) Total() int {
 	return len(e.tasks)
 }
 
+func (e *Executor) Jobs() int {
+	return e.jobs
+}
+
+func (e *Executor) KeepGoing() bool {
+	return e.keepGoing
+}
+
 func (e *Executor) Current() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.current
 }
 
 func (e *Executor) Done() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.done()
+}
+
+func (e *Executor) done() bool {
 	return e.current >= len(e.tasks)
 }
 
 func (e *Executor) Abort() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if !e.aborted {
 		e.aborted = true
-		e.endTime = time.Now()
+		if len(e.running) == 0 {
+			e.endTime = time.Now()
+		}
 	}
 }
 
+func (e *Executor) Aborted() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.aborted
+}
+
 func (e *Executor) Stopped() bool {
-	return e.aborted || e.Done()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stopped()
+}
+
+func (e *Executor) stopped() bool {
+	return (e.aborted && len(e.running) == 0) || e.done()
 }
 
 func (e *Executor) Tasks() []task.Task {
@@ -62,40 +258,367 @@ func (e *Executor) Tasks() []task.Task {
 }
 
 func (e *Executor) Results() []task.Result {
-	return e.results
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]task.Result(nil), e.results...)
 }
 
 func (e *Executor) ResultAt(i int) task.Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if i < 0 || i >= len(e.results) {
 		return task.Result{Status: task.StatusPending}
 	}
 	return e.results[i]
 }
 
-func (e *Executor) RunNext(ctx context.Context) (task.Result, bool) {
-	if e.Stopped() {
-		return task.Result{}, false
+func (e *Executor) ErrorIgnored(i int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if i < 0 || i >= len(e.results) {
+		return false
+	}
+	status := e.results[i].Status
+	return e.ignoreErrors[i] && status.IsFailure() && status != task.StatusCancelled
+}
+
+func (e *Executor) IsRunning(i int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.running[i]
+}
+
+func (e *Executor) Running() []int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var idx []int
+	for i := range e.tasks {
+		if e.running[i] {
+			idx = append(idx, i)
+		}
 	}
+	return idx
+}
 
-	if e.current == 0 {
+// StartReady claims every task that can start now. Claimed tasks must be
+// executed with Run.
+func (e *Executor) StartReady() []int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var started []int
+	for i := e.current; i < len(e.tasks) && len(e.running) < e.jobs; i++ {
+		if e.claim(i) {
+			started = append(started, i)
+			continue
+		}
+		// Starting later tasks would keep a ready terminal task waiting.
+		if e.ready(i) && usesTerminal(e.tasks[i]) {
+			break
+		}
+	}
+	return started
+}
+
+func (e *Executor) ready(i int) bool {
+	if e.aborted || e.finished[i] || e.running[i] {
+		return false
+	}
+	for _, d := range e.deps[i] {
+		if !e.finished[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// A task using the terminal runs alone, so nothing else writes to the screen
+// while it prompts.
+func usesTerminal(t task.Task) bool {
+	return slices.Contains(task.ResourcesOf(t), task.ResourceTerminal)
+}
+
+func (e *Executor) claim(i int) bool {
+	if !e.ready(i) {
+		return false
+	}
+	if _, held := e.locked[task.ResourceTerminal]; held {
+		return false
+	}
+	if usesTerminal(e.tasks[i]) && len(e.running) > 0 {
+		return false
+	}
+
+	resources := task.ResourcesOf(e.tasks[i])
+	for _, r := range resources {
+		if _, held := e.locked[r]; held {
+			return false
+		}
+	}
+
+	if e.startTime.IsZero() {
 		e.startTime = time.Now()
 	}
+	for _, r := range resources {
+		e.locked[r] = i
+	}
+	e.running[i] = true
+	return true
+}
 
-	t := e.tasks[e.current]
+func (e *Executor) Run(ctx context.Context, i int) task.Result {
 	taskStart := time.Now()
-	result := t.Run(ctx)
+	if e.backup != nil {
+		ctx = backup.WithBackup(ctx, e.backup)
+	}
+	diff := e.diff(ctx, i)
+	result := e.runAttempts(ctx, i)
+	if diff != "" {
+		result.Output = strings.TrimSpace(diff + "\n" + result.Output)
+	}
 	result.Duration = time.Since(taskStart)
-	e.results[e.current] = result
-	e.current++
 
-	if e.Done() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.results[i] = result
+	e.finished[i] = true
+	delete(e.running, i)
+	e.idle.Broadcast()
+	e.record(i, result)
+	e.publish(i)
+	for r, holder := range e.locked {
+		if holder == i {
+			delete(e.locked, r)
+		}
+	}
+
+	switch {
+	case result.Status == task.StatusCancelled:
+		e.aborted = true
+	case result.Status.IsFailure() && !e.ignoreErrors[i]:
+		e.blockedBy[i] = i
+		if e.keepGoing {
+			e.skipDependents()
+		} else {
+			e.aborted = true
+		}
+	}
+
+	for e.current < len(e.tasks) && e.finished[e.current] {
+		e.current++
+	}
+
+	if e.done() || (e.aborted && len(e.running) == 0) {
 		e.endTime = time.Now()
 	}
 
-	return result, true
+	return result
+}
+
+// A task that cannot be diffed reports the same error when it runs.
+func (e *Executor) diff(ctx context.Context, i int) string {
+	if !e.showDiff {
+		return ""
+	}
+	d, err := task.Diff(e.tasks[i])
+	if err != nil || d == "" {
+		return ""
+	}
+	if w := logstream.Writer(ctx); w != nil {
+		_, _ = io.WriteString(w, d)
+	}
+	return d
+}
+
+func (e *Executor) runAttempts(ctx context.Context, i int) task.Result {
+	maxAttempts := 1 + e.retries[i]
+	delay := e.retryDelays[i]
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	for attempt := 1; ; attempt++ {
+		result := e.runOnce(ctx, i)
+		result.Attempts = attempt
+		if !result.Status.IsFailure() || result.Status == task.StatusCancelled || attempt >= maxAttempts {
+			return result
+		}
+
+		if w := logstream.Writer(ctx); w != nil {
+			fmt.Fprintf(w, "attempt %d/%d failed: %v\n", attempt, maxAttempts, result.Error)
+			fmt.Fprintf(w, "retrying in %s\n", delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			result.Status = task.StatusCancelled
+			result.Error = fmt.Errorf("cancelled: %w", ctx.Err())
+			return result
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (e *Executor) runOnce(ctx context.Context, i int) task.Result {
+	timeout := e.timeouts[i]
+	if timeout <= 0 {
+		timeout = e.timeout
+	}
+
+	taskCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		taskCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result := e.tasks[i].Run(taskCtx)
+	if result.Status != task.StatusFailed {
+		return result
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		result.Status = task.StatusCancelled
+		result.Error = fmt.Errorf("cancelled: %w", ctx.Err())
+	case taskCtx.Err() != nil:
+		result.Status = task.StatusTimedOut
+		result.Error = fmt.Errorf("timed out after %s", timeout)
+	}
+	return result
+}
+
+func (e *Executor) Wait() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for len(e.running) > 0 {
+		e.idle.Wait()
+	}
+}
+
+func (e *Executor) record(i int, result task.Result) {
+	if e.journal == nil || e.fingerprints[i] == "" {
+		return
+	}
+	// A task left out by tag selection was not part of the run.
+	if result.Message == task.MessageFilteredByTag {
+		return
+	}
+	output := e.redactor.Value(result.Data)
+	e.journal.Record(journal.Entry{
+		Fingerprint: e.fingerprints[i],
+		Task:        e.redactor.String(e.tasks[i].Name()),
+		Status:      result.Status.String(),
+		Timestamp:   time.Now(),
+		Duration:    result.Duration,
+		Attempts:    result.Attempts,
+		Output:      output,
+		Redacted:    !reflect.DeepEqual(output, result.Data),
+	})
+}
+
+// publish waits until every node expanded from the same config task has
+// finished; a task expanded into several nodes publishes the list of their
+// outputs.
+func (e *Executor) publish(i int) {
+	id := e.ids[i]
+	if e.scope == nil || id == "" {
+		return
+	}
+
+	var nodes []int
+	for j, other := range e.ids {
+		if other != id {
+			continue
+		}
+		if !e.finished[j] {
+			return
+		}
+		nodes = append(nodes, j)
+	}
+
+	if len(nodes) == 1 {
+		e.scope.Record(id, e.results[i].Data, e.statusOf(i))
+		return
+	}
+
+	outputs := make([]any, len(nodes))
+	status := task.StatusSkipped.String()
+	failure := ""
+	for k, j := range nodes {
+		outputs[k] = e.results[j].Data
+		s := e.statusOf(j)
+		if e.results[j].Status.IsFailure() && failure == "" {
+			failure = s
+		}
+		if s == task.StatusDone.String() {
+			status = s
+		}
+	}
+	if failure != "" {
+		status = failure
+	}
+	e.scope.Record(id, outputs, status)
+}
+
+// Nodes completed in a previous run keep the status they finished with then.
+func (e *Executor) statusOf(i int) string {
+	if s, ok := e.resumed[i]; ok {
+		return s
+	}
+	return e.results[i].Status.String()
+}
+
+// Tasks are in topological order, so a single forward pass sees each
+// dependency before its dependents.
+func (e *Executor) skipDependents() {
+	for i := range e.tasks {
+		if e.finished[i] || e.running[i] {
+			continue
+		}
+		for _, d := range e.deps[i] {
+			if root := e.blockedBy[d]; root >= 0 {
+				e.blockedBy[i] = root
+				e.finished[i] = true
+				e.results[i] = task.Result{
+					Status:  task.StatusSkipped,
+					Message: "dependency failed: " + e.tasks[root].Name(),
+				}
+				break
+			}
+		}
+	}
+}
+
+func (e *Executor) RunNext(ctx context.Context) (task.Result, bool) {
+	e.mu.Lock()
+	next := -1
+	if !e.stopped() {
+		for i := e.current; i < len(e.tasks); i++ {
+			if e.claim(i) {
+				next = i
+				break
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	if next < 0 {
+		return task.Result{}, false
+	}
+	return e.Run(ctx, next), true
 }
 
 func (e *Executor) ElapsedTime() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if e.startTime.IsZero() {
 		return 0
 	}
@@ -107,19 +630,30 @@ func (e *Executor) ElapsedTime() time.Duration {
 }
 
 func (e *Executor) Summary() Summary {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	var s Summary
-	for _, r := range e.results {
+	for i, r := range e.results {
 		switch r.Status {
 		case task.StatusDone:
 			s.Done++
 		case task.StatusSkipped:
 			s.Skipped++
-		case task.StatusFailed:
+		case task.StatusFailed, task.StatusTimedOut:
 			s.Failed++
+			if r.Status == task.StatusTimedOut {
+				s.TimedOut++
+			}
+			if e.ignoreErrors[i] {
+				s.Ignored++
+			}
+		case task.StatusCancelled:
+			s.Cancelled++
 		case task.StatusPending:
 			s.Pending++
 		}
 	}
-	s.HasFailures = s.Failed > 0
+	s.HasFailures = s.Failed > s.Ignored || s.Cancelled > 0
 	return s
 }
@@ -1,9 +1,18 @@
 package executor
 
 import (
+	"booster/internal/backup"
+	"booster/internal/condition"
+	"booster/internal/config"
+	"booster/internal/journal"
+	"booster/internal/logstream"
+	"booster/internal/redact"
 	"booster/internal/task"
 	"context"
 	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -338,3 +347,739 @@ func TestExecutor_ElapsedTime_OnEmptyExecutor(t *testing.T) {
 	elapsed = exec.ElapsedTime()
 	assert.Equal(t, time.Duration(0), elapsed, "ElapsedTime should remain 0 for empty executor")
 }
+
+type resourceTask struct {
+	mockTask
+	resources []string
+}
+
+func (r *resourceTask) Resources() []string { return r.resources }
+
+func TestExecutor_StartReady_LimitedByJobs(t *testing.T) {
+	tasks := []task.Task{
+		&mockTask{name: "task1", result: task.Result{Status: task.StatusDone}},
+		&mockTask{name: "task2", result: task.Result{Status: task.StatusDone}},
+		&mockTask{name: "task3", result: task.Result{Status: task.StatusDone}},
+	}
+	exec := New(tasks, WithJobs(2))
+
+	assert.Equal(t, 2, exec.Jobs())
+	assert.Equal(t, []int{0, 1}, exec.StartReady())
+	assert.Empty(t, exec.StartReady(), "no free worker slot")
+
+	exec.Run(context.Background(), 1)
+
+	assert.Equal(t, []int{2}, exec.StartReady())
+	assert.Equal(t, []int{0, 2}, exec.Running())
+	assert.Equal(t, 0, exec.Current())
+
+	exec.Run(context.Background(), 0)
+	exec.Run(context.Background(), 2)
+
+	assert.True(t, exec.Done())
+	assert.Empty(t, exec.Running())
+}
+
+func TestExecutor_StartReady_SerializesSharedResources(t *testing.T) {
+	tasks := []task.Task{
+		&resourceTask{mockTask: mockTask{name: "pkg1"}, resources: []string{task.ResourcePackageManager}},
+		&resourceTask{mockTask: mockTask{name: "pkg2"}, resources: []string{task.ResourcePackageManager}},
+		&mockTask{name: "free"},
+	}
+	exec := New(tasks, WithJobs(4))
+
+	assert.Equal(t, []int{0, 2}, exec.StartReady())
+
+	exec.Run(context.Background(), 0)
+
+	assert.Equal(t, []int{1}, exec.StartReady())
+}
+
+func TestExecutor_StartReady_TerminalTasksRunAlone(t *testing.T) {
+	tasks := []task.Task{
+		&mockTask{name: "first"},
+		&resourceTask{mockTask: mockTask{name: "prompt"}, resources: []string{task.ResourceTerminal}},
+		&mockTask{name: "later"},
+	}
+	exec := New(tasks, WithJobs(4))
+
+	assert.Equal(t, []int{0}, exec.StartReady(), "the prompt waits for running tasks and nothing starts past it")
+
+	exec.Run(context.Background(), 0)
+
+	assert.Equal(t, []int{1}, exec.StartReady())
+	assert.Empty(t, exec.StartReady(), "nothing starts while the prompt runs")
+
+	exec.Run(context.Background(), 1)
+
+	assert.Equal(t, []int{2}, exec.StartReady())
+}
+
+func TestExecutor_NewGraph_WaitsForDependencies(t *testing.T) {
+	builder := task.NewBuilder().Register("mock", func(args any) ([]task.Task, error) {
+		return []task.Task{&mockTask{name: args.(string), result: task.Result{Status: task.StatusDone}}}, nil
+	})
+	g, err := builder.BuildGraph([]config.Task{
+		{Action: "mock", Args: "base", ID: "base"},
+		{Action: "mock", Args: "child", DependsOn: config.StringOrSlice{"base"}},
+		{Action: "mock", Args: "other"},
+	})
+	require.NoError(t, err)
+
+	exec := NewGraph(g, WithJobs(3))
+
+	assert.Equal(t, []int{0, 2}, exec.StartReady())
+
+	exec.Run(context.Background(), 0)
+
+	assert.Equal(t, []int{1}, exec.StartReady())
+}
+
+func TestExecutor_Abort_WaitsForRunningTasks(t *testing.T) {
+	tasks := []task.Task{
+		&mockTask{name: "task1", result: task.Result{Status: task.StatusFailed}},
+		&mockTask{name: "task2", result: task.Result{Status: task.StatusDone}},
+		&mockTask{name: "task3", result: task.Result{Status: task.StatusDone}},
+	}
+	exec := New(tasks, WithJobs(2))
+	require.Equal(t, []int{0, 1}, exec.StartReady())
+
+	exec.Run(context.Background(), 0)
+	exec.Abort()
+
+	assert.False(t, exec.Stopped(), "task2 is still running")
+	assert.Empty(t, exec.StartReady())
+
+	exec.Run(context.Background(), 1)
+
+	assert.True(t, exec.Stopped())
+	assert.Equal(t, task.StatusPending, exec.ResultAt(2).Status)
+}
+
+// mockGraph registers factory as the "mock" action; without one every task is
+// done.
+func mockGraph(t *testing.T, tasks []config.Task, factory task.Factory) *task.Graph {
+	t.Helper()
+
+	if factory == nil {
+		factory = mockResults(nil)
+	}
+	g, err := task.NewBuilder().Register("mock", factory).BuildGraph(tasks)
+	require.NoError(t, err)
+	return g
+}
+
+// mockResults names each task after its args; tasks not in results are done.
+func mockResults(results map[string]task.Status) task.Factory {
+	return func(args any) ([]task.Task, error) {
+		name := args.(string)
+		status, ok := results[name]
+		if !ok {
+			status = task.StatusDone
+		}
+		result := task.Result{Status: status}
+		if status == task.StatusFailed {
+			result.Error = errors.New(name + " failed")
+		}
+		return []task.Task{&mockTask{name: name, result: result}}, nil
+	}
+}
+
+func runAll(exec *Executor) {
+	for {
+		if _, ok := exec.RunNext(context.Background()); !ok {
+			return
+		}
+	}
+}
+
+func TestExecutor_StopsAtFirstFailureByDefault(t *testing.T) {
+	g := mockGraph(t, []config.Task{
+		{Action: "mock", Args: "a"},
+		{Action: "mock", Args: "b"},
+		{Action: "mock", Args: "c"},
+	}, mockResults(map[string]task.Status{"b": task.StatusFailed}))
+
+	exec := NewGraph(g)
+	runAll(exec)
+
+	assert.True(t, exec.Aborted())
+	assert.True(t, exec.Stopped())
+	assert.Equal(t, task.StatusPending, exec.ResultAt(2).Status)
+	assert.True(t, exec.Summary().HasFailures)
+}
+
+func TestExecutor_KeepGoing_SkipsDependentsOfFailedTasks(t *testing.T) {
+	g := mockGraph(t, []config.Task{
+		{Action: "mock", Args: "a", ID: "a"},
+		{Action: "mock", Args: "b", ID: "b", DependsOn: config.StringOrSlice{"a"}},
+		{Action: "mock", Args: "c", DependsOn: config.StringOrSlice{"b"}},
+		{Action: "mock", Args: "d"},
+		{Action: "mock", Args: "e"},
+	}, mockResults(map[string]task.Status{"a": task.StatusFailed, "d": task.StatusFailed}))
+
+	exec := NewGraph(g, WithKeepGoing(true))
+	runAll(exec)
+
+	require.True(t, exec.Done())
+	assert.False(t, exec.Aborted())
+
+	assert.Equal(t, task.StatusFailed, exec.ResultAt(0).Status)
+	assert.Equal(t, task.StatusSkipped, exec.ResultAt(1).Status)
+	assert.Equal(t, "dependency failed: a", exec.ResultAt(1).Message)
+	assert.Equal(t, task.StatusSkipped, exec.ResultAt(2).Status)
+	assert.Equal(t, "dependency failed: a", exec.ResultAt(2).Message, "reason names the root failure")
+	assert.Equal(t, task.StatusFailed, exec.ResultAt(3).Status)
+	assert.Equal(t, task.StatusDone, exec.ResultAt(4).Status)
+
+	summary := exec.Summary()
+	assert.Equal(t, 2, summary.Failed)
+	assert.Equal(t, 2, summary.Skipped)
+	assert.True(t, summary.HasFailures)
+}
+
+func TestExecutor_IgnoreErrors_ContinuesWithDependents(t *testing.T) {
+	g := mockGraph(t, []config.Task{
+		{Action: "mock", Args: "flaky", ID: "flaky", IgnoreErrors: true},
+		{Action: "mock", Args: "next", DependsOn: config.StringOrSlice{"flaky"}},
+	}, mockResults(map[string]task.Status{"flaky": task.StatusFailed}))
+
+	exec := NewGraph(g)
+	runAll(exec)
+
+	require.True(t, exec.Done())
+	assert.False(t, exec.Aborted())
+	assert.True(t, exec.ErrorIgnored(0))
+	assert.False(t, exec.ErrorIgnored(1))
+	assert.Equal(t, task.StatusDone, exec.ResultAt(1).Status)
+
+	summary := exec.Summary()
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, 1, summary.Ignored)
+	assert.False(t, summary.HasFailures)
+}
+
+func TestExecutor_WithJournal_RecordsFinishedTasks(t *testing.T) {
+	g := mockGraph(t, []config.Task{
+		{Action: "mock", Args: "a"},
+		{Action: "mock", Args: "b"},
+	}, mockResults(map[string]task.Status{"b": task.StatusFailed}))
+	path := filepath.Join(t.TempDir(), "journal.yaml")
+	j := journal.New(path, time.Now())
+
+	exec := NewGraph(g, WithJournal(j))
+	runAll(exec)
+
+	run, err := journal.Load(path)
+	require.NoError(t, err)
+	require.Len(t, run.Entries, 2)
+	assert.Equal(t, "a", run.Entries[0].Task)
+	assert.Equal(t, "done", run.Entries[0].Status)
+	assert.Equal(t, g.Nodes()[0].Fingerprint, run.Entries[0].Fingerprint)
+	assert.Equal(t, "b", run.Entries[1].Task)
+	assert.Equal(t, "failed", run.Entries[1].Status)
+}
+
+func TestExecutor_WithResume_SkipsPreviouslySucceededTasks(t *testing.T) {
+	cfg := []config.Task{
+		{Action: "mock", Args: "a"},
+		{Action: "mock", Args: "b"},
+		{Action: "mock", Args: "c"},
+	}
+	g := mockGraph(t, cfg, nil)
+	nodes := g.Nodes()
+	previous := &journal.Run{Entries: []journal.Entry{
+		{Fingerprint: nodes[0].Fingerprint, Task: "a", Status: "done"},
+		{Fingerprint: nodes[1].Fingerprint, Task: "b", Status: "failed"},
+		{Fingerprint: "changed-since", Task: "c", Status: "done"},
+	}}
+	path := filepath.Join(t.TempDir(), "journal.yaml")
+	j := journal.New(path, time.Now())
+
+	exec := NewGraph(g, WithResume(previous), WithJournal(j))
+
+	assert.Equal(t, 1, exec.Current())
+	assert.Equal(t, task.StatusSkipped, exec.ResultAt(0).Status)
+	assert.Equal(t, "completed in previous run", exec.ResultAt(0).Message)
+
+	runAll(exec)
+
+	assert.Equal(t, task.StatusDone, exec.ResultAt(1).Status)
+	assert.Equal(t, task.StatusDone, exec.ResultAt(2).Status)
+
+	run, err := journal.Load(path)
+	require.NoError(t, err)
+	require.Len(t, run.Entries, 3, "resumed task is carried into the new journal")
+	assert.Equal(t, "a", run.Entries[0].Task)
+	assert.Equal(t, "done", run.Entries[0].Status)
+}
+
+// echoTasks outputs its args, one task per list item.
+func echoTasks(args any) ([]task.Task, error) {
+	items, ok := args.([]any)
+	if !ok {
+		items = []any{args}
+	}
+	var created []task.Task
+	for _, item := range items {
+		created = append(created, &mockTask{
+			name:   fmt.Sprint(item),
+			result: task.Result{Status: task.StatusDone, Data: item},
+		})
+	}
+	return created, nil
+}
+
+func TestExecutor_WithResume_RunsTasksSkippedUnderAnotherProfile(t *testing.T) {
+	cfg := []config.Task{
+		{Action: "mock", Args: "home"},
+		{Action: "mock", Args: "work", When: &config.When{Profile: config.StringOrSlice{"work"}}},
+	}
+	build := func(profile string) *task.Graph {
+		builder := task.NewBuilder().
+			WithEvaluator(condition.NewEvaluator(condition.Context{Profile: profile})).
+			Register("mock", func(args any) ([]task.Task, error) {
+				return []task.Task{&mockTask{name: args.(string), result: task.Result{Status: task.StatusDone}}}, nil
+			})
+		g, err := builder.BuildGraph(cfg)
+		require.NoError(t, err)
+		return g
+	}
+	path := filepath.Join(t.TempDir(), "journal.yaml")
+
+	home := NewGraph(build("home"), WithJournal(journal.New(path, time.Now())))
+	runAll(home)
+	require.Equal(t, task.StatusSkipped, home.ResultAt(1).Status)
+
+	previous, err := journal.Load(path)
+	require.NoError(t, err)
+	work := NewGraph(build("work"), WithResume(previous))
+	runAll(work)
+
+	assert.Equal(t, "completed in previous run", work.ResultAt(0).Message)
+	assert.Equal(t, task.StatusDone, work.ResultAt(1).Status, "the task skipped under home runs under work")
+}
+
+func TestExecutor_WithRedactor_MasksJournalEntries(t *testing.T) {
+	g := mockGraph(t, []config.Task{
+		{Action: "mock", Args: "token=hunter2"},
+	}, echoTasks)
+	path := filepath.Join(t.TempDir(), "journal.yaml")
+	j := journal.New(path, time.Now())
+
+	exec := NewGraph(g, WithJournal(j), WithRedactor(redact.New("hunter2")))
+	runAll(exec)
+
+	assert.Equal(t, "token=hunter2", exec.ResultAt(0).Data, "results keep the real value")
+	run, err := journal.Load(path)
+	require.NoError(t, err)
+	require.Len(t, run.Entries, 1)
+	assert.Equal(t, "token="+redact.Mask, run.Entries[0].Task)
+	assert.Equal(t, "token="+redact.Mask, run.Entries[0].Output)
+	assert.True(t, run.Entries[0].Redacted)
+}
+
+func TestExecutor_WithResume_RerunsTasksWithRedactedOutput(t *testing.T) {
+	cfg := []config.Task{
+		{Action: "mock", Args: "token=hunter2", ID: "token"},
+		{Action: "mock", Args: "plain", ID: "plain"},
+		{Action: "mock", Args: "${ tasks.token.output }"},
+	}
+	path := filepath.Join(t.TempDir(), "journal.yaml")
+	first := NewGraph(mockGraph(t, cfg, echoTasks), WithJournal(journal.New(path, time.Now())), WithRedactor(redact.New("hunter2")))
+	runAll(first)
+
+	previous, err := journal.Load(path)
+	require.NoError(t, err)
+	resumed := NewGraph(mockGraph(t, cfg, echoTasks), WithResume(previous), WithRedactor(redact.New("hunter2")))
+	runAll(resumed)
+
+	assert.Equal(t, "completed in previous run", resumed.ResultAt(1).Message)
+	assert.Equal(t, task.StatusDone, resumed.ResultAt(0).Status, "a task whose output was redacted runs again")
+	assert.Equal(t, "token=hunter2", resumed.ResultAt(2).Data, "later tasks get the real output")
+}
+
+func TestExecutor_PublishesTaskResults(t *testing.T) {
+	g := mockGraph(t, []config.Task{
+		{Action: "mock", Args: "${ tasks.brew.output }/bin (${ tasks.brew.status })", ID: "path"},
+		{Action: "mock", Args: "/opt/homebrew", ID: "brew"},
+		{Action: "mock", Args: []any{"a", "b"}, ID: "multi"},
+		{Action: "mock", Args: "${ tasks.multi.output }"},
+	}, echoTasks)
+
+	exec := NewGraph(g)
+	runAll(exec)
+
+	require.True(t, exec.Done())
+	assert.Equal(t, "/opt/homebrew/bin (done)", exec.ResultAt(1).Data)
+	assert.Equal(t, []any{"a", "b"}, exec.ResultAt(4).Data, "expanded tasks publish a list")
+}
+
+func TestExecutor_WithResume_PublishesPreviousOutputs(t *testing.T) {
+	cfg := []config.Task{
+		{Action: "mock", Args: "/opt/homebrew", ID: "brew"},
+		{Action: "mock", Args: "${ tasks.brew.output }:${ tasks.brew.status }"},
+	}
+	g := mockGraph(t, cfg, echoTasks)
+	previous := &journal.Run{Entries: []journal.Entry{
+		{Fingerprint: g.Nodes()[0].Fingerprint, Task: "brew", Status: "done", Output: "/usr/local"},
+	}}
+	path := filepath.Join(t.TempDir(), "journal.yaml")
+	j := journal.New(path, time.Now())
+
+	exec := NewGraph(g, WithResume(previous), WithJournal(j))
+	runAll(exec)
+
+	assert.Equal(t, task.StatusSkipped, exec.ResultAt(0).Status)
+	assert.Equal(t, "/usr/local:done", exec.ResultAt(1).Data)
+
+	run, err := journal.Load(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, run.Entries)
+	assert.Equal(t, "/usr/local", run.Entries[0].Output, "output is kept for the next resume")
+}
+
+type flakyTask struct {
+	name     string
+	failures int
+	calls    int
+}
+
+func (f *flakyTask) Name() string    { return f.name }
+func (f *flakyTask) NeedsSudo() bool { return false }
+func (f *flakyTask) Run(ctx context.Context) task.Result {
+	f.calls++
+	if w := logstream.Writer(ctx); w != nil {
+		fmt.Fprintf(w, "call %d\n", f.calls)
+	}
+	if f.calls <= f.failures {
+		return task.Result{Status: task.StatusFailed, Error: fmt.Errorf("transient %d", f.calls)}
+	}
+	return task.Result{Status: task.StatusDone}
+}
+
+func flakyGraph(t *testing.T, flaky *flakyTask, retries int) *task.Graph {
+	return mockGraph(t, []config.Task{
+		{Action: "mock", Retries: retries, RetryDelay: time.Millisecond},
+	}, func(args any) ([]task.Task, error) {
+		return []task.Task{flaky}, nil
+	})
+}
+
+func TestExecutor_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		retries      int
+		wantStatus   task.Status
+		wantAttempts int
+	}{
+		{
+			name:         "no retries configured",
+			failures:     1,
+			retries:      0,
+			wantStatus:   task.StatusFailed,
+			wantAttempts: 1,
+		},
+		{
+			name:         "succeeds on a later attempt",
+			failures:     2,
+			retries:      3,
+			wantStatus:   task.StatusDone,
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries exhausted",
+			failures:     5,
+			retries:      2,
+			wantStatus:   task.StatusFailed,
+			wantAttempts: 3,
+		},
+		{
+			name:         "first attempt succeeds",
+			failures:     0,
+			retries:      2,
+			wantStatus:   task.StatusDone,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyTask{name: "flaky", failures: tt.failures}
+			exec := NewGraph(flakyGraph(t, flaky, tt.retries))
+
+			result, ok := exec.RunNext(context.Background())
+
+			require.True(t, ok)
+			assert.Equal(t, tt.wantStatus, result.Status)
+			assert.Equal(t, tt.wantAttempts, result.Attempts)
+			assert.Equal(t, tt.wantAttempts, flaky.calls)
+		})
+	}
+}
+
+func TestExecutor_Retries_LogEveryAttempt(t *testing.T) {
+	flaky := &flakyTask{name: "flaky", failures: 1}
+	exec := NewGraph(flakyGraph(t, flaky, 1))
+
+	w, ch := logstream.NewChannelWriter(100)
+	result, _ := exec.RunNext(logstream.WithWriter(context.Background(), w))
+	w.Close()
+
+	var lines []string
+	for line := range ch {
+		lines = append(lines, line)
+	}
+
+	assert.Equal(t, task.StatusDone, result.Status)
+	assert.Equal(t, []string{
+		"call 1",
+		"attempt 1/2 failed: transient 1",
+		"retrying in 1ms",
+		"call 2",
+	}, lines)
+}
+
+func TestExecutor_Retries_StopWhenContextCancelled(t *testing.T) {
+	flaky := &flakyTask{name: "flaky", failures: 5}
+	exec := NewGraph(flakyGraph(t, flaky, 5))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, _ := exec.RunNext(ctx)
+
+	assert.Equal(t, task.StatusCancelled, result.Status)
+	assert.Equal(t, 1, flaky.calls)
+}
+
+type blockingTask struct {
+	name string
+}
+
+func (b *blockingTask) Name() string    { return b.name }
+func (b *blockingTask) NeedsSudo() bool { return false }
+func (b *blockingTask) Run(ctx context.Context) task.Result {
+	<-ctx.Done()
+	return task.Result{Status: task.StatusFailed, Error: ctx.Err()}
+}
+
+// blockOn blocks the named tasks until they are cancelled; the others are
+// done.
+func blockOn(names ...string) task.Factory {
+	return func(args any) ([]task.Task, error) {
+		name := args.(string)
+		if slices.Contains(names, name) {
+			return []task.Task{&blockingTask{name: name}}, nil
+		}
+		return []task.Task{&mockTask{name: name, result: task.Result{Status: task.StatusDone}}}, nil
+	}
+}
+
+func TestExecutor_Timeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		taskTimeout   time.Duration
+		globalTimeout time.Duration
+		wantError     string
+	}{
+		{
+			name:        "per-task timeout",
+			taskTimeout: 10 * time.Millisecond,
+			wantError:   "timed out after 10ms",
+		},
+		{
+			name:          "global timeout applies to tasks without their own",
+			globalTimeout: 20 * time.Millisecond,
+			wantError:     "timed out after 20ms",
+		},
+		{
+			name:          "per-task timeout overrides global timeout",
+			taskTimeout:   10 * time.Millisecond,
+			globalTimeout: time.Hour,
+			wantError:     "timed out after 10ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := mockGraph(t, []config.Task{{Action: "mock", Args: "slow", Timeout: tt.taskTimeout}}, blockOn("slow"))
+			exec := NewGraph(g, WithTimeout(tt.globalTimeout))
+
+			result, ok := exec.RunNext(context.Background())
+
+			require.True(t, ok)
+			assert.Equal(t, task.StatusTimedOut, result.Status)
+			assert.EqualError(t, result.Error, tt.wantError)
+			assert.True(t, exec.Aborted(), "a timeout stops the run like a failure")
+
+			summary := exec.Summary()
+			assert.Equal(t, 1, summary.Failed)
+			assert.Equal(t, 1, summary.TimedOut)
+			assert.True(t, summary.HasFailures)
+		})
+	}
+}
+
+func TestExecutor_Timeout_KeepGoingSkipsDependents(t *testing.T) {
+	g := mockGraph(t, []config.Task{
+		{Action: "mock", Args: "slow", ID: "slow", Timeout: time.Millisecond},
+		{Action: "mock", Args: "after", DependsOn: config.StringOrSlice{"slow"}},
+		{Action: "mock", Args: "other"},
+	}, blockOn("slow"))
+	exec := NewGraph(g, WithKeepGoing(true))
+
+	runAll(exec)
+
+	assert.Equal(t, task.StatusTimedOut, exec.ResultAt(0).Status)
+	assert.Equal(t, task.StatusSkipped, exec.ResultAt(1).Status)
+	assert.Equal(t, task.StatusDone, exec.ResultAt(2).Status)
+}
+
+func TestExecutor_Cancel_MarksTaskCancelledAndStops(t *testing.T) {
+	g := mockGraph(t, []config.Task{
+		{Action: "mock", Args: "slow"},
+		{Action: "mock", Args: "next"},
+	}, blockOn("slow"))
+	exec := NewGraph(g, WithKeepGoing(true))
+	require.Equal(t, []int{0}, exec.StartReady())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan task.Result)
+	go func() { done <- exec.Run(ctx, 0) }()
+
+	cancel()
+	result := <-done
+	exec.Wait()
+
+	assert.Equal(t, task.StatusCancelled, result.Status)
+	assert.ErrorIs(t, result.Error, context.Canceled)
+	assert.True(t, exec.Stopped(), "cancelling stops the run even with keep-going")
+	assert.Equal(t, task.StatusPending, exec.ResultAt(1).Status)
+
+	summary := exec.Summary()
+	assert.Equal(t, 1, summary.Cancelled)
+	assert.True(t, summary.HasFailures)
+}
+
+func TestExecutor_Wait_BlocksUntilRunningTasksFinish(t *testing.T) {
+	g := mockGraph(t, []config.Task{{Action: "mock", Args: "slow"}}, blockOn("slow"))
+	exec := NewGraph(g)
+	require.Equal(t, []int{0}, exec.StartReady())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go exec.Run(ctx, 0)
+
+	waited := make(chan struct{})
+	go func() {
+		exec.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+		t.Fatal("Wait returned while a task was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after the task finished")
+	}
+}
+
+type diffTask struct {
+	mockTask
+	diff string
+}
+
+func (d *diffTask) Diff() (string, error) { return d.diff, nil }
+
+func TestExecutor_WithDiff(t *testing.T) {
+	const diff = "--- a\n+++ a\n@@ -1 +1 @@\n-old\n+new\n"
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantOutput string
+		wantLogs   []string
+	}{
+		{
+			name:       "prepends diff to output and log",
+			opts:       []Option{WithDiff(true)},
+			wantOutput: diff + "\nwrote a",
+			wantLogs:   []string{"--- a", "+++ a", "@@ -1 +1 @@", "-old", "+new"},
+		},
+		{
+			name:       "no diff without the option",
+			wantOutput: "wrote a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tsk := &diffTask{
+				mockTask: mockTask{name: "render a", result: task.Result{Status: task.StatusDone, Output: "wrote a"}},
+				diff:     diff,
+			}
+			exec := New([]task.Task{tsk}, tt.opts...)
+			w, ch := logstream.NewChannelWriter(10)
+
+			result, _ := exec.RunNext(logstream.WithWriter(context.Background(), w))
+			w.Close()
+
+			var logs []string
+			for line := range ch {
+				logs = append(logs, line)
+			}
+			assert.Equal(t, tt.wantOutput, result.Output)
+			assert.Equal(t, tt.wantLogs, logs)
+		})
+	}
+}
+
+type writeTask struct {
+	mockTask
+	path string
+}
+
+func (w *writeTask) Run(ctx context.Context) task.Result {
+	if err := backup.Save(ctx, w.path); err != nil {
+		return task.Result{Status: task.StatusFailed, Error: err}
+	}
+	return task.Result{Status: task.StatusDone}
+}
+
+func TestExecutor_WithBackup_PassesBackupToTasks(t *testing.T) {
+	dir := t.TempDir()
+	b := backup.New(filepath.Join(dir, "backups"), "run1")
+	exec := New([]task.Task{&writeTask{mockTask: mockTask{name: "write"}, path: filepath.Join(dir, "file")}}, WithBackup(b))
+
+	result, _ := exec.RunNext(context.Background())
+
+	require.Equal(t, task.StatusDone, result.Status)
+	m, err := backup.Load(b.Dir())
+	require.NoError(t, err)
+	assert.Len(t, m.Entries, 1)
+}
+
+func TestExecutor_Journal_SkipsTasksFilteredByTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.yaml")
+	j := journal.New(path, time.Now())
+	g := mockGraph(t, []config.Task{
+		{Action: "mock", Args: "ran"},
+		{Action: "mock", Args: "filtered"},
+	}, mockResults(map[string]task.Status{"ran": task.StatusDone, "filtered": task.StatusSkipped}))
+	g.Nodes()[1].Task.(*mockTask).result.Message = task.MessageFilteredByTag
+	exec := NewGraph(g, WithJournal(j))
+
+	runAll(exec)
+
+	run, err := journal.Load(path)
+	require.NoError(t, err)
+	require.Len(t, run.Entries, 1)
+	assert.Equal(t, "ran", run.Entries[0].Task)
+}
@@ -27,6 +27,11 @@ type Context struct {
 	// Task outputs (populated during execution)
 	// Accessed as tasks.task_name.output, tasks.task_name.status
 	Tasks map[string]TaskResult `expr:"tasks"`
+
+	// Current element of a for_each loop and its list index or map key.
+	// Both are nil outside of loops.
+	Item any `expr:"item"`
+	Key  any `expr:"key"`
 }
 
 // TaskResult holds the output of a completed task.
@@ -63,6 +68,24 @@ func (c *Context) WithVars(vars map[string]any) *Context {
 	return cp
 }
 
+// WithItem returns a copy of the context with the current for_each element
+// set.
+func (c *Context) WithItem(key, item any) *Context {
+	cp := c.clone()
+	cp.Key = key
+	cp.Item = item
+	return cp
+}
+
+// WithTaskResult returns a copy of the context with the result of a task
+// recorded. Unlike SetTaskResult it leaves c untouched, so it is safe to use
+// while other goroutines evaluate expressions against c.
+func (c *Context) WithTaskResult(name string, output any, status string) *Context {
+	cp := c.clone()
+	cp.SetTaskResult(name, output, status)
+	return cp
+}
+
 // SetTaskResult records the result of a completed task.
 // Note: This mutates the context in place. Use clone() first if you need isolation.
 func (c *Context) SetTaskResult(name string, output any, status string) {
@@ -96,6 +96,35 @@ func TestValue_Interpolation(t *testing.T) {
 	}
 }
 
+func TestValue_EscapedExpression(t *testing.T) {
+	ctx := NewContext()
+	ctx.Vars["name"] = "Luke"
+
+	tests := []struct {
+		name string
+		raw  any
+		want any
+	}{
+		{"shell variable", "$${HOME}/bin", "${HOME}/bin"},
+		{"whole string", "$${ vars.name }", "${ vars.name }"},
+		{"next to an expression", "${ vars.name }: $${USER}", "Luke: ${USER}"},
+		{"in a list", []any{"echo $${PATH}", 1}, []any{"echo ${PATH}", 1}},
+		{"in a map", map[string]any{"value": "$${EDITOR:-vim}"}, map[string]any{"value": "${EDITOR:-vim}"}},
+		{"lone dollars", "costs $5 and $$", "costs $5 and $$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewValue(tt.raw)
+			require.NoError(t, err)
+
+			got, err := v.Resolve(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestValue_BuiltinFunctions(t *testing.T) {
 	ctx := NewContext()
 
@@ -417,3 +446,136 @@ func TestContext_CloneIsolation(t *testing.T) {
 	assert.Equal(t, "child2", child2.Vars["key"])
 	assert.Empty(t, child2.Profile, "child2 should not inherit child1's profile")
 }
+
+func TestValue_Nested(t *testing.T) {
+	ctx := NewContext()
+	ctx.Vars["name"] = "Luke"
+
+	tests := []struct {
+		name        string
+		raw         any
+		wantLiteral bool
+		want        any
+	}{
+		{
+			name:        "list of literals",
+			raw:         []any{"a", 1},
+			wantLiteral: true,
+			want:        []any{"a", 1},
+		},
+		{
+			name:        "list with expression",
+			raw:         []any{"a", "${ vars.name }"},
+			wantLiteral: false,
+			want:        []any{"a", "Luke"},
+		},
+		{
+			name: "map nested in list",
+			raw: []any{
+				map[string]any{"source": "${ home }/src", "target": "~/dst", "force": true},
+			},
+			wantLiteral: false,
+			want: []any{
+				map[string]any{"source": ctx.Home + "/src", "target": "~/dst", "force": true},
+			},
+		},
+		{
+			name:        "full expression keeps its type",
+			raw:         map[string]any{"count": "${ 1 + 2 }"},
+			wantLiteral: false,
+			want:        map[string]any{"count": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewValue(tt.raw)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLiteral, v.IsLiteral())
+
+			got, err := v.Resolve(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValue_NestedInvalidExpression(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     any
+		wantErr string
+	}{
+		{
+			name:    "list item",
+			raw:     []any{"ok", "${ 1 + }"},
+			wantErr: "[1]: invalid expression",
+		},
+		{
+			name:    "map in list",
+			raw:     []any{map[string]any{"source": "${ nope }"}},
+			wantErr: "[0].source: invalid expression",
+		},
+		{
+			name:    "top level map",
+			raw:     map[string]any{"packages": []any{"${ nope }"}},
+			wantErr: "packages[0]: invalid expression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewValue(tt.raw)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValue_TaskRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  any
+		want []string
+	}{
+		{"literal", "plain", nil},
+		{"no task reference", "${ os }", nil},
+		{"member access", "${ tasks.brew.output }", []string{"brew"}},
+		{"index access", "${ tasks['git-cfg'].status == 'done' }", []string{"git-cfg"}},
+		{"interpolated", "${ tasks.a.output }/${ tasks.b?.output }", []string{"a", "b"}},
+		{"nested and deduplicated", []any{
+			"${ tasks.b.output }",
+			map[string]any{"source": "${ tasks.a.output }", "target": "${ tasks.b.status }"},
+		}, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewValue(tt.raw)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, v.TaskRefs())
+		})
+	}
+}
+
+func TestContext_WithTaskResult(t *testing.T) {
+	original := NewContext()
+
+	updated := original.WithTaskResult("brew", "/opt/homebrew/bin/brew", "done")
+
+	assert.Empty(t, original.Tasks, "original should be unchanged")
+	v, err := NewValue("${ tasks.brew.output }:${ tasks.brew.status }")
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/homebrew/bin/brew:done", v.MustResolve(updated))
+}
+
+func TestContext_WithItem(t *testing.T) {
+	original := NewContext()
+
+	ctx := original.WithItem("api", map[string]any{"repo": "git@example.com:api.git"})
+
+	assert.Nil(t, original.Item, "original should be unchanged")
+	v, err := NewValue("~/src/${ key } from ${ item.repo }")
+	require.NoError(t, err)
+	assert.Equal(t, "~/src/api from git@example.com:api.git", v.MustResolve(ctx))
+}
@@ -2,9 +2,12 @@ package expr
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
 	"github.com/expr-lang/expr/vm"
 )
 
@@ -13,6 +16,8 @@ import (
 //   - A literal (string, int, bool, list, map) with no expressions
 //   - A full expression: entire value is ${ expr }
 //   - An interpolated string: "prefix ${ expr } suffix"
+//
+// $${ stands for a literal ${, as in "$${HOME}".
 type Value struct {
 	raw any // Original value from YAML
 
@@ -22,6 +27,10 @@ type Value struct {
 
 	// Compiled expression (for full expressions)
 	program *vm.Program
+
+	// Nested values for lists and maps
+	items  []*Value
+	fields map[string]*Value
 }
 
 type part struct {
@@ -30,30 +39,81 @@ type part struct {
 }
 
 // NewValue creates a Value from a raw YAML value.
-// It parses any ${ } expressions found in string values.
+// It parses any ${ } expressions found in string values, including strings
+// nested in lists and maps. Errors in nested values are prefixed with their
+// path, such as "[0].source".
 func NewValue(raw any) (*Value, error) {
+	return newValue(raw, "")
+}
+
+func newValue(raw any, path string) (*Value, error) {
 	v := &Value{raw: raw}
 
+	switch raw := raw.(type) {
+	case []any:
+		v.items = make([]*Value, len(raw))
+		for i, item := range raw {
+			child, err := newValue(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v.items[i] = child
+		}
+		v.raw = v.literal()
+		return v, nil
+	case map[string]any:
+		v.fields = make(map[string]*Value, len(raw))
+		for _, key := range slices.Sorted(maps.Keys(raw)) {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			child, err := newValue(raw[key], childPath)
+			if err != nil {
+				return nil, err
+			}
+			v.fields[key] = child
+		}
+		v.raw = v.literal()
+		return v, nil
+	}
+
 	str, ok := raw.(string)
 	if !ok {
-		// Non-string values are literals (could contain nested Values in lists/maps)
+		// Other non-string values are literals
 		return v, nil
 	}
 
-	// Check if this is a full expression (entire string is ${ expr })
-	trimmed := strings.TrimSpace(str)
-	if strings.HasPrefix(trimmed, "${") && strings.HasSuffix(trimmed, "}") {
+	v, err := newString(str)
+	if err != nil && path != "" {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return v, err
+}
+
+// IsExpression reports whether the entire string is a single ${ expr }, whose
+// value may be of any type.
+func IsExpression(s string) bool {
+	trimmed := strings.TrimSpace(s)
+	if !strings.HasPrefix(trimmed, "${") || !strings.HasSuffix(trimmed, "}") {
+		return false
+	}
+	return !strings.Contains(trimmed[2:], "${")
+}
+
+func newString(str string) (*Value, error) {
+	v := &Value{raw: str}
+
+	if IsExpression(str) {
+		trimmed := strings.TrimSpace(str)
 		inner := strings.TrimSpace(trimmed[2 : len(trimmed)-1])
-		// Verify it's a single expression (no other ${ in the middle)
-		if !strings.Contains(inner, "${") {
-			program, err := expr.Compile(inner, CompileOptions()...)
-			if err != nil {
-				return nil, fmt.Errorf("invalid expression %q: %w", inner, err)
-			}
-			v.program = program
-			v.isFullExpr = true
-			return v, nil
+		program, err := expr.Compile(inner, CompileOptions()...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression %q: %w", inner, err)
 		}
+		v.program = program
+		v.isFullExpr = true
+		return v, nil
 	}
 
 	// Parse as interpolated string
@@ -62,10 +122,40 @@ func NewValue(raw any) (*Value, error) {
 		return nil, err
 	}
 	v.parts = parts
+	v.raw = v.literal()
 
 	return v, nil
 }
 
+// literal returns the raw value with escapes replaced, for values without
+// expressions; other values keep their raw value.
+func (v *Value) literal() any {
+	if !v.IsLiteral() {
+		return v.raw
+	}
+	switch {
+	case v.items != nil:
+		items := make([]any, len(v.items))
+		for i, item := range v.items {
+			items[i] = item.raw
+		}
+		return items
+	case v.fields != nil:
+		fields := make(map[string]any, len(v.fields))
+		for key, field := range v.fields {
+			fields[key] = field.raw
+		}
+		return fields
+	case v.parts != nil:
+		var sb strings.Builder
+		for _, p := range v.parts {
+			sb.WriteString(p.literal)
+		}
+		return sb.String()
+	}
+	return v.raw
+}
+
 // parseInterpolated splits a string into literal and expression parts.
 // Uses brace-matching to correctly handle nested braces in expressions
 // like ${ {"key": "value"}.key }.
@@ -79,6 +169,11 @@ func parseInterpolated(s string) ([]part, error) {
 		if e.start > lastEnd {
 			parts = append(parts, part{literal: s[lastEnd:e.start]})
 		}
+		if e.escaped {
+			parts = append(parts, part{literal: "${"})
+			lastEnd = e.end
+			continue
+		}
 
 		program, err := expr.Compile(e.inner, CompileOptions()...)
 		if err != nil {
@@ -101,6 +196,8 @@ type exprSpan struct {
 	start int    // Index of '$'
 	end   int    // Index after closing '}'
 	inner string // The expression content (without ${ })
+
+	escaped bool // True for a $${ escape, which stands for a literal ${
 }
 
 // findExpressions locates all ${ ... } expressions in s, handling nested braces.
@@ -109,6 +206,12 @@ func findExpressions(s string) []exprSpan {
 	i := 0
 
 	for i < len(s)-1 {
+		if strings.HasPrefix(s[i:], "$${") {
+			spans = append(spans, exprSpan{start: i, end: i + 3, escaped: true})
+			i += 3
+			continue
+		}
+
 		// Look for ${
 		if s[i] == '$' && s[i+1] == '{' {
 			start := i
@@ -157,7 +260,7 @@ func findExpressions(s string) []exprSpan {
 	return spans
 }
 
-// IsLiteral returns true if this value contains no expressions.
+// IsLiteral returns true if this value contains no expressions, at any depth.
 func (v *Value) IsLiteral() bool {
 	if v.program != nil {
 		return false
@@ -167,9 +270,66 @@ func (v *Value) IsLiteral() bool {
 			return false
 		}
 	}
+	for _, item := range v.items {
+		if !item.IsLiteral() {
+			return false
+		}
+	}
+	for _, field := range v.fields {
+		if !field.IsLiteral() {
+			return false
+		}
+	}
 	return true
 }
 
+// TaskRefs returns the names of the tasks whose results this value reads
+// through tasks.<name> or tasks["name"], sorted and without duplicates.
+func (v *Value) TaskRefs() []string {
+	refs := make(map[string]bool)
+	v.collectTaskRefs(refs)
+	return slices.Sorted(maps.Keys(refs))
+}
+
+func (v *Value) collectTaskRefs(refs map[string]bool) {
+	programs := []*vm.Program{v.program}
+	for _, p := range v.parts {
+		programs = append(programs, p.program)
+	}
+	for _, program := range programs {
+		if program == nil {
+			continue
+		}
+		node := program.Node()
+		ast.Walk(&node, taskRefVisitor(refs))
+	}
+
+	for _, item := range v.items {
+		item.collectTaskRefs(refs)
+	}
+	for _, field := range v.fields {
+		field.collectTaskRefs(refs)
+	}
+}
+
+// taskRefVisitor records the property of every member access on the tasks
+// identifier, such as "build" in tasks.build.output.
+type taskRefVisitor map[string]bool
+
+func (refs taskRefVisitor) Visit(node *ast.Node) {
+	member, ok := (*node).(*ast.MemberNode)
+	if !ok {
+		return
+	}
+	ident, ok := member.Node.(*ast.IdentifierNode)
+	if !ok || ident.Value != "tasks" {
+		return
+	}
+	if name, ok := member.Property.(*ast.StringNode); ok {
+		refs[name.Value] = true
+	}
+}
+
 // Resolve evaluates any expressions in this value against the given context.
 func (v *Value) Resolve(ctx *Context) (any, error) {
 	// Full expression: return typed result
@@ -182,6 +342,30 @@ func (v *Value) Resolve(ctx *Context) (any, error) {
 		return v.raw, nil
 	}
 
+	// Lists and maps: resolve each element into a new container
+	if v.items != nil {
+		resolved := make([]any, len(v.items))
+		for i, item := range v.items {
+			r, err := item.Resolve(ctx)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+	}
+	if v.fields != nil {
+		resolved := make(map[string]any, len(v.fields))
+		for key, field := range v.fields {
+			r, err := field.Resolve(ctx)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+	}
+
 	// Interpolated string: evaluate parts and concatenate
 	var sb strings.Builder
 	for _, p := range v.parts {
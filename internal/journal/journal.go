@@ -0,0 +1,125 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Entry struct {
+	Fingerprint string        `yaml:"fingerprint"`
+	Task        string        `yaml:"task"`
+	Status      string        `yaml:"status"`
+	Timestamp   time.Time     `yaml:"timestamp"`
+	Duration    time.Duration `yaml:"duration,omitempty"`
+	Attempts    int           `yaml:"attempts,omitempty"`
+	Output      any           `yaml:"output,omitempty"`
+	// A redacted Output cannot stand in for the real output on resume.
+	Redacted bool `yaml:"redacted,omitempty"`
+}
+
+type Run struct {
+	ID      string    `yaml:"id"`
+	Started time.Time `yaml:"started"`
+	Entries []Entry   `yaml:"entries"`
+}
+
+// A skipped task may have been skipped by a condition that no longer holds,
+// so only tasks that finished as done count.
+func (r *Run) Succeeded(fingerprint string) (Entry, bool) {
+	if r == nil {
+		return Entry{}, false
+	}
+	for i := len(r.Entries) - 1; i >= 0; i-- {
+		e := r.Entries[i]
+		if e.Fingerprint != fingerprint {
+			continue
+		}
+		return e, e.Status == "done" && !e.Redacted
+	}
+	return Entry{}, false
+}
+
+// A missing file is not an error and returns a nil run.
+func Load(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var run Run
+	if err := yaml.Unmarshal(data, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// The file is rewritten after every entry so an interrupted run can be
+// resumed.
+type Journal struct {
+	mu   sync.Mutex
+	path string
+	run  Run
+	err  error
+}
+
+func New(path string, now time.Time) *Journal {
+	return &Journal{
+		path: path,
+		run: Run{
+			ID:      NewRunID(now),
+			Started: now,
+		},
+	}
+}
+
+func NewRunID(t time.Time) string {
+	return t.UTC().Format("20060102T150405.000Z")
+}
+
+func (j *Journal) ID() string {
+	return j.run.ID
+}
+
+func (j *Journal) Path() string {
+	return j.path
+}
+
+func (j *Journal) Record(e Entry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.run.Entries = append(j.run.Entries, e)
+	if err := j.save(); err != nil && j.err == nil {
+		j.err = err
+	}
+}
+
+func (j *Journal) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+func (j *Journal) save() error {
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(j.run)
+	if err != nil {
+		return err
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
@@ -0,0 +1,105 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_MissingFile(t *testing.T) {
+	run, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+
+	require.NoError(t, err)
+	assert.Nil(t, run)
+}
+
+func TestLoad_InvalidYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("entries: [oops"), 0o644))
+
+	_, err := Load(path)
+
+	require.Error(t, err)
+}
+
+func TestJournal_RecordPersistsEachEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "journal.yaml")
+	now := time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)
+	j := New(path, now)
+
+	j.Record(Entry{Fingerprint: "fp1", Task: "first", Status: "done", Timestamp: now})
+
+	run, err := Load(path)
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Equal(t, j.ID(), run.ID)
+	require.Len(t, run.Entries, 1)
+	assert.Equal(t, "first", run.Entries[0].Task)
+
+	j.Record(Entry{Fingerprint: "fp2", Task: "second", Status: "failed", Timestamp: now, Duration: time.Second})
+
+	run, err = Load(path)
+	require.NoError(t, err)
+	require.Len(t, run.Entries, 2)
+	assert.Equal(t, time.Second, run.Entries[1].Duration)
+	assert.NoError(t, j.Err())
+}
+
+func TestJournal_ErrKeepsFirstWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o644))
+
+	j := New(filepath.Join(blocker, "journal.yaml"), time.Now())
+	j.Record(Entry{Fingerprint: "fp", Status: "done"})
+
+	assert.Error(t, j.Err())
+}
+
+func TestNewRunID(t *testing.T) {
+	id := NewRunID(time.Date(2026, 3, 1, 10, 30, 5, 250_000_000, time.UTC))
+
+	assert.Equal(t, "20260301T103005.250Z", id)
+}
+
+func TestRun_Succeeded(t *testing.T) {
+	run := &Run{Entries: []Entry{
+		{Fingerprint: "done", Status: "done"},
+		{Fingerprint: "skipped", Status: "skipped"},
+		{Fingerprint: "failed", Status: "failed"},
+		{Fingerprint: "redacted", Status: "done", Redacted: true},
+		{Fingerprint: "retried", Status: "failed"},
+		{Fingerprint: "retried", Status: "done"},
+	}}
+
+	tests := []struct {
+		fingerprint string
+		want        bool
+	}{
+		{"done", true},
+		{"skipped", false},
+		{"failed", false},
+		{"redacted", false},
+		{"retried", true},
+		{"unknown", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fingerprint, func(t *testing.T) {
+			_, ok := run.Succeeded(tt.fingerprint)
+			assert.Equal(t, tt.want, ok)
+		})
+	}
+}
+
+func TestRun_Succeeded_NilRun(t *testing.T) {
+	var run *Run
+
+	_, ok := run.Succeeded("fp")
+
+	assert.False(t, ok)
+}
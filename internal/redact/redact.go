@@ -0,0 +1,124 @@
+package redact
+
+import (
+	"bytes"
+	"cmp"
+	"io"
+	"slices"
+	"strings"
+)
+
+const Mask = "••••••"
+
+// A nil Redactor changes nothing.
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// New returns nil if there are no non-empty secrets.
+func New(secrets ...string) *Redactor {
+	secrets = slices.DeleteFunc(slices.Clone(secrets), func(s string) bool { return s == "" })
+	if len(secrets) == 0 {
+		return nil
+	}
+	// Longer secrets first, so one containing another is masked whole.
+	slices.SortFunc(secrets, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, s := range secrets {
+		pairs = append(pairs, s, Mask)
+	}
+	return &Redactor{replacer: strings.NewReplacer(pairs...)}
+}
+
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
+
+// The redacted error still unwraps to err.
+func (r *Redactor) Error(err error) error {
+	if r == nil || err == nil {
+		return err
+	}
+	msg := r.String(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+func (r *Redactor) Value(v any) any {
+	if r == nil {
+		return v
+	}
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = r.Value(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = r.Value(item)
+		}
+		return out
+	}
+	return v
+}
+
+// Text is held back until a newline, so a secret split across writes is still
+// masked; Flush writes what is left.
+func (r *Redactor) Writer(w io.Writer) *Writer {
+	return &Writer{r: r, w: w}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+type Writer struct {
+	r   *Redactor
+	w   io.Writer
+	buf []byte
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.r == nil {
+		return w.w.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	i := bytes.LastIndexByte(w.buf, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+	if _, err := io.WriteString(w.w, w.r.String(string(w.buf[:i+1]))); err != nil {
+		return 0, err
+	}
+	w.buf = append(w.buf[:0], w.buf[i+1:]...)
+	return len(p), nil
+}
+
+func (w *Writer) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w.w, w.r.String(string(w.buf)))
+	w.buf = w.buf[:0]
+	return err
+}
@@ -0,0 +1,68 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor_String(t *testing.T) {
+	r := New("hunter2", "", "hunter2-admin")
+
+	assert.Equal(t, "token=••••••, admin=••••••", r.String("token=hunter2, admin=hunter2-admin"))
+	assert.Equal(t, "nothing secret", r.String("nothing secret"))
+}
+
+func TestRedactor_NilChangesNothing(t *testing.T) {
+	r := New("", "")
+
+	assert.Nil(t, r)
+	assert.Equal(t, "hunter2", r.String("hunter2"))
+	err := errors.New("hunter2")
+	assert.Same(t, err, r.Error(err))
+
+	var buf bytes.Buffer
+	fmt.Fprint(r.Writer(&buf), "hunter2")
+	assert.Equal(t, "hunter2", buf.String())
+}
+
+func TestRedactor_Error(t *testing.T) {
+	r := New("hunter2")
+	base := errors.New("login failed")
+	err := fmt.Errorf("password hunter2: %w", base)
+
+	redacted := r.Error(err)
+
+	assert.EqualError(t, redacted, "password ••••••: login failed")
+	assert.ErrorIs(t, redacted, base)
+	assert.Nil(t, r.Error(nil))
+}
+
+func TestRedactor_Writer(t *testing.T) {
+	var buf bytes.Buffer
+	w := New("hunter2").Writer(&buf)
+
+	n, err := fmt.Fprint(w, "echo hunter2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, len("echo hunter2"), n)
+	assert.Empty(t, buf.String(), "text is held back until a newline")
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "echo ••••••", buf.String())
+}
+
+func TestRedactor_Writer_SecretSplitAcrossWrites(t *testing.T) {
+	var buf bytes.Buffer
+	w := New("hunter2").Writer(&buf)
+
+	fmt.Fprint(w, "one\necho hun")
+	assert.Equal(t, "one\n", buf.String())
+	fmt.Fprint(w, "ter2\ntwo")
+	require.NoError(t, w.Flush())
+
+	assert.Equal(t, "one\necho ••••••\ntwo", buf.String())
+}
@@ -5,6 +5,29 @@ import (
 	"fmt"
 )
 
+// Index is -1 if args is a map. The builder uses Index and Key to point at the
+// element in the config file.
+type ArgError struct {
+	Index int
+	Key   string
+	Err   error
+}
+
+func argErrorf(index int, key, format string, a ...any) error {
+	return &ArgError{Index: index, Key: key, Err: fmt.Errorf(format, a...)}
+}
+
+func (e *ArgError) Error() string {
+	if e.Index < 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("arg %d: %v", e.Index+1, e.Err)
+}
+
+func (e *ArgError) Unwrap() error {
+	return e.Err
+}
+
 type SourceTarget struct {
 	Source string
 	Target string
@@ -20,25 +43,25 @@ func parseSourceTargetArgs(args any) ([]SourceTarget, error) {
 	for i, item := range items {
 		m, ok := item.(map[string]any)
 		if !ok {
-			return nil, fmt.Errorf("arg %d: must be a map with 'source' and 'target'", i+1)
+			return nil, argErrorf(i, "", "must be a map with 'source' and 'target'")
 		}
 
 		sourceRaw, hasSource := m["source"]
 		if !hasSource {
-			return nil, fmt.Errorf("arg %d: missing 'source'", i+1)
+			return nil, argErrorf(i, "", "missing 'source'")
 		}
 		source, ok := sourceRaw.(string)
 		if !ok {
-			return nil, fmt.Errorf("arg %d: 'source' must be a string", i+1)
+			return nil, argErrorf(i, "source", "'source' must be a string")
 		}
 
 		targetRaw, hasTarget := m["target"]
 		if !hasTarget {
-			return nil, fmt.Errorf("arg %d: missing 'target'", i+1)
+			return nil, argErrorf(i, "", "missing 'target'")
 		}
 		target, ok := targetRaw.(string)
 		if !ok {
-			return nil, fmt.Errorf("arg %d: 'target' must be a string", i+1)
+			return nil, argErrorf(i, "target", "'target' must be a string")
 		}
 
 		result = append(result, SourceTarget{Source: source, Target: target})
@@ -46,3 +69,19 @@ func parseSourceTargetArgs(args any) ([]SourceTarget, error) {
 
 	return result, nil
 }
+
+func sourceTargetSchema(description, source, target string) map[string]any {
+	return map[string]any{
+		"type":        "array",
+		"description": description,
+		"items": map[string]any{
+			"type":                 "object",
+			"required":             []string{"source", "target"},
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"source": map[string]any{"type": "string", "description": source},
+				"target": map[string]any{"type": "string", "description": target},
+			},
+		},
+	}
+}
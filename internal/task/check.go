@@ -0,0 +1,30 @@
+package task
+
+import (
+	"context"
+	"errors"
+)
+
+// A CheckResult without changes and without an error means the task is in
+// sync.
+type CheckResult struct {
+	Error   error
+	Message string
+	Changes []string
+}
+
+func (r CheckResult) Drifted() bool {
+	return len(r.Changes) > 0
+}
+
+type Checker interface {
+	Check(ctx context.Context) CheckResult
+}
+
+func Check(ctx context.Context, t Task) CheckResult {
+	c, ok := t.(Checker)
+	if !ok {
+		return CheckResult{Error: errors.New("task does not support check mode")}
+	}
+	return c.Check(ctx)
+}
@@ -0,0 +1,22 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck_TaskWithoutProbe(t *testing.T) {
+	result := Check(context.Background(), &mockTask{name: "opaque"})
+
+	require.Error(t, result.Error)
+	assert.Contains(t, result.Error.Error(), "does not support check mode")
+}
+
+func TestCheckResult_Drifted(t *testing.T) {
+	assert.False(t, CheckResult{}.Drifted())
+	assert.False(t, CheckResult{Message: "not macOS"}.Drifted())
+	assert.True(t, CheckResult{Changes: []string{"create x"}}.Drifted())
+}
@@ -2,14 +2,25 @@ package task
 
 import (
 	"booster/internal/condition"
+	"booster/internal/expr"
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 )
 
+const MessageFilteredByTag = "filtered by tag"
+
 type ConditionalTask struct {
 	wrapped   Task
 	condition *condition.Condition
 	evaluator *condition.Evaluator
+
+	// when is evaluated at run time, against the results recorded so far.
+	when     *expr.Value
+	whenText string
+	scope    *Scope
+	item     *loopItem
 }
 
 func NewConditionalTask(t Task, cond *condition.Condition, eval *condition.Evaluator) (*ConditionalTask, error) {
@@ -31,13 +42,95 @@ func (t *ConditionalTask) NeedsSudo() bool {
 	return t.wrapped.NeedsSudo()
 }
 
+func (t *ConditionalTask) Resources() []string {
+	return ResourcesOf(t.wrapped)
+}
+
 func (t *ConditionalTask) Run(ctx context.Context) Result {
-	if !t.evaluator.Matches(t.condition) {
-		reason := t.evaluator.FailureReason(t.condition)
+	skip, err := t.skipMessage()
+	if err != nil {
+		return Result{Status: StatusFailed, Error: err}
+	}
+	if skip != "" {
 		return Result{
 			Status:  StatusSkipped,
-			Message: "condition not met: " + reason,
+			Message: skip,
 		}
 	}
 	return t.wrapped.Run(ctx)
 }
+
+func (t *ConditionalTask) skipMessage() (string, error) {
+	if skip := t.conditionSkip(); skip != "" {
+		return skip, nil
+	}
+	if t.when == nil {
+		return "", nil
+	}
+
+	ok, err := expr.ResolveCondition(t.when, t.scope.contextFor(t.item))
+	if err != nil {
+		return "", fmt.Errorf("evaluate when %s: %w", t.whenText, err)
+	}
+	if !ok {
+		return "condition not met: " + t.whenText, nil
+	}
+	return "", nil
+}
+
+func (t *ConditionalTask) conditionSkip() string {
+	if t.evaluator.FilteredByTag(t.condition) {
+		return MessageFilteredByTag
+	}
+	if !t.evaluator.Matches(t.condition) {
+		return "condition not met: " + t.evaluator.FailureReason(t.condition)
+	}
+	return ""
+}
+
+// Before anything runs, a when that reads task results cannot be evaluated.
+func (t *ConditionalTask) whenRefs() []string {
+	if t.when == nil {
+		return nil
+	}
+	return t.when.TaskRefs()
+}
+
+func (t *ConditionalTask) Check(ctx context.Context) CheckResult {
+	if refs := t.whenRefs(); len(refs) > 0 {
+		if skip := t.conditionSkip(); skip != "" {
+			return CheckResult{Message: skip}
+		}
+		return CheckResult{Message: "when depends on results of " + strings.Join(refs, ", ")}
+	}
+	skip, err := t.skipMessage()
+	if err != nil {
+		return CheckResult{Error: err}
+	}
+	if skip != "" {
+		return CheckResult{Message: skip}
+	}
+	return Check(ctx, t.wrapped)
+}
+
+func (t *ConditionalTask) UnsetPrompts(ctx context.Context) []string {
+	p, ok := t.wrapped.(PromptUser)
+	if !ok || len(t.whenRefs()) > 0 {
+		return nil
+	}
+	if skip, err := t.skipMessage(); err != nil || skip != "" {
+		return nil
+	}
+	return p.UnsetPrompts(ctx)
+}
+
+func (t *ConditionalTask) Diff() (string, error) {
+	if len(t.whenRefs()) > 0 {
+		return "", nil
+	}
+	skip, err := t.skipMessage()
+	if err != nil || skip != "" {
+		return "", err
+	}
+	return Diff(t.wrapped)
+}
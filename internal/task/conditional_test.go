@@ -145,3 +145,96 @@ func TestNewConditionalTask_RejectsNilEvaluator(t *testing.T) {
 	assert.Nil(t, ct)
 	assert.EqualError(t, err, "evaluator cannot be nil")
 }
+
+func TestConditionalTask_ResourcesDelegatesToWrapped(t *testing.T) {
+	eval := condition.NewEvaluator(condition.Context{OS: "arch"})
+	cond := &condition.Condition{OS: []string{"arch"}}
+
+	ct, err := NewConditionalTask(&PkgInstall{}, cond, eval)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{ResourcePackageManager}, ct.Resources())
+}
+
+type checkingTask struct {
+	mockTask
+	check CheckResult
+}
+
+func (c *checkingTask) Check(ctx context.Context) CheckResult { return c.check }
+
+func TestConditionalTask_Check(t *testing.T) {
+	inner := &checkingTask{
+		mockTask: mockTask{name: "test task"},
+		check:    CheckResult{Changes: []string{"create x"}},
+	}
+	cond := &condition.Condition{OS: []string{"arch"}}
+
+	t.Run("delegates when condition met", func(t *testing.T) {
+		ct, err := NewConditionalTask(inner, cond, condition.NewEvaluator(condition.Context{OS: "arch"}))
+		require.NoError(t, err)
+
+		result := ct.Check(context.Background())
+
+		assert.Equal(t, []string{"create x"}, result.Changes)
+	})
+
+	t.Run("reports condition when not met", func(t *testing.T) {
+		ct, err := NewConditionalTask(inner, cond, condition.NewEvaluator(condition.Context{OS: "darwin"}))
+		require.NoError(t, err)
+
+		result := ct.Check(context.Background())
+
+		assert.False(t, result.Drifted())
+		assert.NoError(t, result.Error)
+		assert.Contains(t, result.Message, "condition not met")
+	})
+}
+
+func (c *checkingTask) Diff() (string, error) { return "+x\n", nil }
+
+func TestConditionalTask_Diff(t *testing.T) {
+	inner := &checkingTask{mockTask: mockTask{name: "test task"}}
+	cond := &condition.Condition{OS: []string{"arch"}}
+
+	met, err := NewConditionalTask(inner, cond, condition.NewEvaluator(condition.Context{OS: "arch"}))
+	require.NoError(t, err)
+	diff, err := met.Diff()
+	require.NoError(t, err)
+	assert.Equal(t, "+x\n", diff)
+
+	unmet, err := NewConditionalTask(inner, cond, condition.NewEvaluator(condition.Context{OS: "darwin"}))
+	require.NoError(t, err)
+	diff, err = unmet.Diff()
+	require.NoError(t, err)
+	assert.Empty(t, diff, "a task that will be skipped changes nothing")
+}
+
+func TestConditionalTask_FilteredByTag(t *testing.T) {
+	inner := &checkingTask{mockTask: mockTask{name: "test task"}}
+	eval := condition.NewEvaluator(condition.Context{OS: "arch", Tags: []string{"dotfiles"}})
+	ct, err := NewConditionalTask(inner, &condition.Condition{Tags: []string{"packages"}}, eval)
+	require.NoError(t, err)
+
+	result := ct.Run(context.Background())
+	check := ct.Check(context.Background())
+
+	assert.Equal(t, StatusSkipped, result.Status)
+	assert.Equal(t, MessageFilteredByTag, result.Message)
+	assert.Equal(t, MessageFilteredByTag, check.Message)
+	assert.False(t, inner.called)
+}
+
+func (c *checkingTask) UnsetPrompts(ctx context.Context) []string { return []string{"user.name"} }
+
+func TestUnsetPrompts_LeavesOutSkippedTasks(t *testing.T) {
+	inner := &checkingTask{mockTask: mockTask{name: "test task"}}
+	cond := &condition.Condition{OS: []string{"arch"}}
+
+	met, err := NewConditionalTask(inner, cond, condition.NewEvaluator(condition.Context{OS: "arch"}))
+	require.NoError(t, err)
+	unmet, err := NewConditionalTask(inner, cond, condition.NewEvaluator(condition.Context{OS: "darwin"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"user.name"}, UnsetPrompts(context.Background(), []Task{met, unmet, &mockTask{}}))
+}
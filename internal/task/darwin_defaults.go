@@ -2,6 +2,7 @@ package task
 
 import (
 	"booster/internal/cmdexec"
+	"booster/internal/config"
 	"booster/internal/pathutil"
 	"context"
 	"errors"
@@ -34,6 +35,10 @@ func (t *DarwinDefaults) NeedsSudo() bool {
 	return false
 }
 
+func (t *DarwinDefaults) Fingerprint() string {
+	return fmt.Sprintf("%v", t.Entries)
+}
+
 func (t *DarwinDefaults) Name() string {
 	if len(t.Entries) == 0 {
 		return "set macOS defaults: (none)"
@@ -105,6 +110,31 @@ func (t *DarwinDefaults) Run(ctx context.Context) Result {
 	return Result{Status: StatusDone, Message: msg, Output: allOutput.String()}
 }
 
+func (t *DarwinDefaults) Check(ctx context.Context) CheckResult {
+	if t.OS != "darwin" {
+		return CheckResult{Message: "not macOS"}
+	}
+
+	var changes []string
+	for _, entry := range t.Entries {
+		current, err := t.readDefault(ctx, entry.Domain, entry.Key)
+		if err != nil {
+			current = ""
+		}
+
+		desired := t.normalizeValue(entry.Type, entry.Value)
+		if current != "" && t.normalizeValue(entry.Type, current) == desired {
+			continue
+		}
+		if current == "" {
+			changes = append(changes, fmt.Sprintf("set %s %s to %v", entry.Domain, entry.Key, entry.Value))
+		} else {
+			changes = append(changes, fmt.Sprintf("set %s %s to %v (current: %s)", entry.Domain, entry.Key, entry.Value, current))
+		}
+	}
+	return CheckResult{Changes: changes}
+}
+
 func (t *DarwinDefaults) readDefault(ctx context.Context, domain, key string) (string, error) {
 	output, err := t.Runner.Run(ctx, "defaults", "read", domain, key)
 	if err != nil {
@@ -199,7 +229,7 @@ func NewDarwinDefaultsFactory(cfg DarwinDefaultsConfig) Factory {
 
 		filePath, ok := fileRaw.(string)
 		if !ok {
-			return nil, errors.New("'file' must be a string")
+			return nil, argErrorf(-1, "file", "'file' must be a string")
 		}
 
 		resolvedPath := filePath
@@ -209,28 +239,40 @@ func NewDarwinDefaultsFactory(cfg DarwinDefaultsConfig) Factory {
 			}
 		}
 
-		entries, err := loadDefaultsFile(resolvedPath)
+		entries, nodes, err := loadDefaultsFile(resolvedPath)
 		if err != nil {
-			return nil, fmt.Errorf("load defaults file: %w", err)
+			return nil, argErrorf(-1, "file", "load defaults file: %w", err)
+		}
+
+		// Entry errors point into the defaults file, as written in args.
+		pos := func(i int, key string) config.Position {
+			node := nodes[i]
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				if node.Content[j].Value == key {
+					node = node.Content[j+1]
+					break
+				}
+			}
+			return config.Position{Path: pathutil.Expand(resolvedPath), File: filePath, Line: node.Line, Column: node.Column}
 		}
 
 		for i, entry := range entries {
 			if entry.Domain == "" {
-				return nil, fmt.Errorf("entry %d: missing 'domain'", i+1)
+				return nil, config.ErrorAt(pos(i, ""), fmt.Errorf("entry %d: missing 'domain'", i+1))
 			}
 			if entry.Key == "" {
-				return nil, fmt.Errorf("entry %d: missing 'key'", i+1)
+				return nil, config.ErrorAt(pos(i, ""), fmt.Errorf("entry %d: missing 'key'", i+1))
 			}
 			if entry.Type == "" {
-				return nil, fmt.Errorf("entry %d: missing 'type'", i+1)
+				return nil, config.ErrorAt(pos(i, ""), fmt.Errorf("entry %d: missing 'type'", i+1))
 			}
 			if entry.Value == nil {
-				return nil, fmt.Errorf("entry %d: missing 'value'", i+1)
+				return nil, config.ErrorAt(pos(i, ""), fmt.Errorf("entry %d: missing 'value'", i+1))
 			}
 
 			validTypes := map[string]bool{"bool": true, "int": true, "float": true, "string": true}
 			if !validTypes[entry.Type] {
-				return nil, fmt.Errorf("entry %d: invalid type %q (must be bool, int, float, or string)", i+1, entry.Type)
+				return nil, config.ErrorAt(pos(i, "type"), fmt.Errorf("entry %d: invalid type %q (must be bool, int, float, or string)", i+1, entry.Type))
 			}
 		}
 
@@ -247,18 +289,44 @@ func NewDarwinDefaultsFactory(cfg DarwinDefaultsConfig) Factory {
 	}
 }
 
-func loadDefaultsFile(path string) ([]DefaultsEntry, error) {
+func loadDefaultsFile(path string) ([]DefaultsEntry, []*yaml.Node, error) {
 	expanded := pathutil.Expand(path)
 
 	data, err := os.ReadFile(expanded)
 	if err != nil {
-		return nil, fmt.Errorf("read file: %w", err)
+		return nil, nil, fmt.Errorf("read file: %w", err)
 	}
 
-	var file DefaultsFile
+	var file struct {
+		Defaults []yaml.Node `yaml:"defaults"`
+	}
 	if err := yaml.Unmarshal(data, &file); err != nil {
-		return nil, fmt.Errorf("parse YAML: %w", err)
+		return nil, nil, fmt.Errorf("parse YAML: %w", err)
+	}
+
+	entries := make([]DefaultsEntry, len(file.Defaults))
+	nodes := make([]*yaml.Node, len(file.Defaults))
+	for i := range file.Defaults {
+		nodes[i] = &file.Defaults[i]
+		if err := nodes[i].Decode(&entries[i]); err != nil {
+			return nil, nil, fmt.Errorf("parse YAML: %w", err)
+		}
 	}
 
-	return file.Defaults, nil
+	return entries, nodes, nil
+}
+
+func DarwinDefaultsSchema() map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"required":             []string{"file"},
+		"additionalProperties": false,
+		"description":          "macOS defaults configuration",
+		"properties": map[string]any{
+			"file": map[string]any{
+				"type":        "string",
+				"description": "Path to YAML file containing macOS defaults, relative to the config directory",
+			},
+		},
+	}
 }
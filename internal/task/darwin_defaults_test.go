@@ -6,6 +6,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -546,6 +547,7 @@ func TestNewDarwinDefaultsFactory_ValidationErrorIndex(t *testing.T) {
 		name          string
 		content       string
 		expectedIndex string
+		expectedPos   string
 	}{
 		{
 			name: "first entry missing domain shows entry 1",
@@ -555,6 +557,7 @@ func TestNewDarwinDefaultsFactory_ValidationErrorIndex(t *testing.T) {
     value: true
 `,
 			expectedIndex: "entry 1:",
+			expectedPos:   "defaults.yaml:2:5: ",
 		},
 		{
 			name: "second entry missing key shows entry 2",
@@ -568,6 +571,7 @@ func TestNewDarwinDefaultsFactory_ValidationErrorIndex(t *testing.T) {
     value: true
 `,
 			expectedIndex: "entry 2:",
+			expectedPos:   "defaults.yaml:6:5: ",
 		},
 		{
 			name: "third entry invalid type shows entry 3",
@@ -586,6 +590,7 @@ func TestNewDarwinDefaultsFactory_ValidationErrorIndex(t *testing.T) {
     value: true
 `,
 			expectedIndex: "entry 3:",
+			expectedPos:   "defaults.yaml:12:11: ",
 		},
 	}
 
@@ -610,6 +615,8 @@ func TestNewDarwinDefaultsFactory_ValidationErrorIndex(t *testing.T) {
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), tt.expectedIndex,
 				"error message must show correct 1-indexed entry number")
+			assert.True(t, strings.HasPrefix(err.Error(), tt.expectedPos),
+				"error must point at the entry in the defaults file: %s", err)
 		})
 	}
 }
@@ -751,3 +758,52 @@ func TestDarwinDefaults_CapturesWriteOutput(t *testing.T) {
 	assert.Contains(t, result.Output, "write output captured",
 		"non-empty write output should be captured in result")
 }
+
+func TestDarwinDefaults_Check(t *testing.T) {
+	runner := &cmdexec.MockRunner{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			switch args[2] {
+			case "AppleShowAllFiles":
+				return []byte("1"), nil
+			case "tilesize":
+				return []byte("48"), nil
+			}
+			return nil, errors.New("does not exist")
+		},
+	}
+	task := &DarwinDefaults{
+		Runner: runner,
+		OS:     "darwin",
+		Entries: []DefaultsEntry{
+			{Domain: "com.apple.finder", Key: "AppleShowAllFiles", Type: "bool", Value: true},
+			{Domain: "com.apple.dock", Key: "tilesize", Type: "int", Value: 36},
+			{Domain: "com.apple.dock", Key: "autohide", Type: "bool", Value: true},
+		},
+	}
+
+	result := task.Check(context.Background())
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, []string{
+		"set com.apple.dock tilesize to 36 (current: 48)",
+		"set com.apple.dock autohide to true",
+	}, result.Changes)
+	for _, call := range runner.Calls {
+		assert.Equal(t, "read", call.Args[0], "check must not write defaults")
+	}
+}
+
+func TestDarwinDefaults_Check_NotMacOS(t *testing.T) {
+	runner := &cmdexec.MockRunner{}
+	task := &DarwinDefaults{
+		Runner:  runner,
+		OS:      "linux",
+		Entries: []DefaultsEntry{{Domain: "com.apple.finder", Key: "AppleShowAllFiles", Type: "bool", Value: true}},
+	}
+
+	result := task.Check(context.Background())
+
+	assert.False(t, result.Drifted())
+	assert.Equal(t, "not macOS", result.Message)
+	assert.Empty(t, runner.Calls)
+}
@@ -0,0 +1,88 @@
+package task
+
+import (
+	"booster/internal/expr"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// deferredTask creates its tasks when it runs, because its args read the
+// results of other tasks.
+type deferredTask struct {
+	action  string
+	factory Factory
+	needs   ActionNeeds
+	args    *expr.Value
+	scope   *Scope
+	item    *loopItem
+}
+
+func (t *deferredTask) Name() string {
+	return fmt.Sprintf("%s (uses results of %s)", t.action, strings.Join(t.args.TaskRefs(), ", "))
+}
+
+func (t *deferredTask) NeedsSudo() bool {
+	return t.needs.Sudo
+}
+
+func (t *deferredTask) Resources() []string {
+	return t.needs.Resources
+}
+
+func (t *deferredTask) create() ([]Task, error) {
+	args, err := t.args.Resolve(t.scope.contextFor(t.item))
+	if err != nil {
+		return nil, fmt.Errorf("resolve args: %w", err)
+	}
+	return t.factory(args)
+}
+
+func (t *deferredTask) Run(ctx context.Context) Result {
+	tasks, err := t.create()
+	if err != nil {
+		return Result{Status: StatusFailed, Error: err}
+	}
+	if len(tasks) == 1 {
+		return tasks[0].Run(ctx)
+	}
+
+	var messages, outputs []string
+	var data []any
+	status := StatusSkipped
+	for _, created := range tasks {
+		result := created.Run(ctx)
+		if result.Output != "" {
+			outputs = append(outputs, result.Output)
+		}
+		if result.Status.IsFailure() {
+			result.Output = strings.Join(outputs, "\n")
+			if result.Error != nil {
+				result.Error = fmt.Errorf("%s: %w", created.Name(), result.Error)
+			}
+			return result
+		}
+		if result.Message != "" {
+			messages = append(messages, created.Name()+": "+result.Message)
+		}
+		if result.Status == StatusDone {
+			status = StatusDone
+		}
+		data = append(data, result.Data)
+	}
+
+	return Result{
+		Status:  status,
+		Message: strings.Join(messages, "; "),
+		Output:  strings.Join(outputs, "\n"),
+		Data:    data,
+	}
+}
+
+func (t *deferredTask) Check(ctx context.Context) CheckResult {
+	return CheckResult{Message: "args depend on results of " + strings.Join(t.args.TaskRefs(), ", ")}
+}
+
+func (t *deferredTask) Diff() (string, error) {
+	return "", nil
+}
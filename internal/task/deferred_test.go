@@ -0,0 +1,198 @@
+package task
+
+import (
+	"booster/internal/condition"
+	"booster/internal/config"
+	"booster/internal/expr"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func captureBuilder(got *[]any) *Builder {
+	return NewBuilder().Register("capture", func(args any) ([]Task, error) {
+		*got = append(*got, args)
+		return []Task{&mockTask{
+			name:   "capture",
+			result: Result{Status: StatusDone, Data: args},
+		}}, nil
+	})
+}
+
+func TestBuilder_BuildGraph_DefersArgsReadingTaskResults(t *testing.T) {
+	var got []any
+	g, err := captureBuilder(&got).BuildGraph([]config.Task{
+		{Action: "capture", Args: "${ tasks.brew.output }/bin", ID: "shell"},
+		{Action: "capture", Args: "brew", ID: "brew"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []any{"brew"}, got, "only the literal task is created at build time")
+
+	nodes := g.Nodes()
+	require.Len(t, nodes, 2)
+	assert.Equal(t, "brew", nodes[0].ID, "task results are implicit dependencies")
+	assert.Equal(t, "shell", nodes[1].ID)
+	assert.Equal(t, []int{0}, nodes[1].Deps)
+	assert.Equal(t, "capture (uses results of brew)", nodes[1].Task.Name())
+	assert.Empty(t, nodes[1].Fingerprint, "deferred tasks are never resumed")
+	assert.NotEmpty(t, nodes[0].Fingerprint)
+
+	g.Scope().Record("brew", "/opt/homebrew", StatusDone.String())
+	result := nodes[1].Task.Run(context.Background())
+
+	assert.Equal(t, StatusDone, result.Status)
+	assert.Equal(t, "/opt/homebrew/bin", result.Data)
+}
+
+func TestBuilder_BuildGraph_UnknownTaskReference(t *testing.T) {
+	var got []any
+	_, err := captureBuilder(&got).BuildGraph([]config.Task{
+		{Action: "capture", Args: "x"},
+		{Action: "capture", Args: "${ tasks.missing.output }"},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `task 2 (capture): expression references unknown task "missing"`)
+}
+
+func TestBuilder_BuildGraph_WhenReadsTaskResults(t *testing.T) {
+	var got []any
+	builder := captureBuilder(&got).WithEvaluator(condition.NewEvaluator(condition.Context{}))
+	g, err := builder.BuildGraph([]config.Task{
+		{Action: "capture", Args: "probe", ID: "probe"},
+		{Action: "capture", Args: "x", When: &config.When{Expr: "${ tasks.probe.status == 'done' }"}},
+	})
+	require.NoError(t, err)
+
+	nodes := g.Nodes()
+	assert.Equal(t, []int{0}, nodes[1].Deps)
+
+	result := nodes[1].Task.Run(context.Background())
+	assert.Equal(t, StatusSkipped, result.Status)
+	assert.Equal(t, "condition not met: ${ tasks.probe.status == 'done' }", result.Message)
+
+	g.Scope().Record("probe", nil, StatusDone.String())
+	result = nodes[1].Task.Run(context.Background())
+	assert.Equal(t, StatusDone, result.Status)
+}
+
+func TestBuilder_BuildGraph_CheckWhenReadsTaskResults(t *testing.T) {
+	var got []any
+	builder := captureBuilder(&got).WithEvaluator(condition.NewEvaluator(condition.Context{OS: "linux"}))
+	g, err := builder.BuildGraph([]config.Task{
+		{Action: "capture", Args: "probe", ID: "probe"},
+		{Action: "capture", Args: "x", When: &config.When{Expr: "${ tasks.probe.status == 'done' }"}},
+		{Action: "capture", Args: "y", When: &config.When{OS: config.StringOrSlice{"darwin"}, Expr: "${ tasks.probe.status == 'done' }"}},
+	})
+	require.NoError(t, err)
+	nodes := g.Nodes()
+
+	result := Check(context.Background(), nodes[1].Task)
+	assert.False(t, result.Drifted())
+	assert.NoError(t, result.Error)
+	assert.Equal(t, "when depends on results of probe", result.Message)
+
+	result = Check(context.Background(), nodes[2].Task)
+	assert.Equal(t, "condition not met: os=linux, want darwin", result.Message)
+}
+
+func TestDeferredTask_Run(t *testing.T) {
+	scope := NewScope(expr.NewContext().WithTaskResult("dirs", []any{"/a", "/b"}, "done"))
+	args, err := expr.NewValue("${ tasks.dirs.output }")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		factory     Factory
+		wantStatus  Status
+		wantData    any
+		wantMessage string
+		wantErr     string
+	}{
+		{
+			name: "single task passes its result through",
+			factory: func(args any) ([]Task, error) {
+				return []Task{&mockTask{name: "one", result: Result{Status: StatusSkipped, Message: "exists", Data: "x"}}}, nil
+			},
+			wantStatus:  StatusSkipped,
+			wantData:    "x",
+			wantMessage: "exists",
+		},
+		{
+			name: "several tasks combine their results",
+			factory: func(args any) ([]Task, error) {
+				var tasks []Task
+				for _, dir := range args.([]any) {
+					tasks = append(tasks, &mockTask{name: dir.(string), result: Result{Status: StatusDone, Data: dir}})
+				}
+				return tasks, nil
+			},
+			wantStatus: StatusDone,
+			wantData:   []any{"/a", "/b"},
+		},
+		{
+			name: "failing task stops the rest",
+			factory: func(args any) ([]Task, error) {
+				return []Task{
+					&mockTask{name: "first", result: Result{Status: StatusFailed, Error: assert.AnError}},
+					&mockTask{name: "second", result: Result{Status: StatusDone}},
+				}, nil
+			},
+			wantStatus: StatusFailed,
+			wantErr:    "first: " + assert.AnError.Error(),
+		},
+		{
+			name: "factory error fails the task",
+			factory: func(args any) ([]Task, error) {
+				return nil, assert.AnError
+			},
+			wantStatus: StatusFailed,
+			wantErr:    assert.AnError.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &deferredTask{action: "mock", factory: tt.factory, args: args, scope: scope}
+
+			result := d.Run(context.Background())
+
+			assert.Equal(t, tt.wantStatus, result.Status)
+			if tt.wantErr != "" {
+				require.Error(t, result.Error)
+				assert.Equal(t, tt.wantErr, result.Error.Error())
+				return
+			}
+			assert.Equal(t, tt.wantData, result.Data)
+			assert.Equal(t, tt.wantMessage, result.Message)
+		})
+	}
+}
+
+func TestBuilder_BuildGraph_DeferredTasksHaveDeclaredNeeds(t *testing.T) {
+	factory := func(args any) ([]Task, error) {
+		t.Fatal("factory called")
+		return nil, nil
+	}
+	needs := ActionNeeds{Sudo: true, Resources: []string{ResourcePackageManager}}
+	builder := NewBuilder().
+		Register("probe", func(args any) ([]Task, error) { return []Task{&mockTask{name: "probe"}}, nil }).
+		Register("install", factory).Declare("install", needs).
+		Register("other", factory)
+
+	g, err := builder.BuildGraph([]config.Task{
+		{Action: "probe", Args: "x", ID: "probe"},
+		{Action: "install", Args: "${ tasks.probe.output }"},
+		{Action: "other", Args: "${ tasks.probe.output }"},
+	})
+	require.NoError(t, err)
+
+	nodes := g.Nodes()
+	assert.True(t, nodes[1].Task.NeedsSudo())
+	assert.Equal(t, needs.Resources, ResourcesOf(nodes[1].Task))
+	assert.False(t, nodes[2].Task.NeedsSudo(), "undeclared actions need nothing")
+	assert.Empty(t, ResourcesOf(nodes[2].Task))
+}
@@ -0,0 +1,32 @@
+package task
+
+import (
+	"os"
+
+	"github.com/aymanbagabas/go-udiff"
+)
+
+// Diff returns an empty string when nothing would change.
+type Differ interface {
+	Diff() (string, error)
+}
+
+func Diff(t Task) (string, error) {
+	if d, ok := t.(Differ); ok {
+		return d.Diff()
+	}
+	return "", nil
+}
+
+// A missing file diffs against /dev/null.
+func unifiedDiff(label, path string, content []byte) (string, error) {
+	oldLabel := label
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		oldLabel = "/dev/null"
+	}
+	return udiff.Unified(oldLabel, label, string(existing), string(content)), nil
+}
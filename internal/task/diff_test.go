@@ -0,0 +1,58 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff_TaskWithoutDiffer(t *testing.T) {
+	diff, err := Diff(&mockTask{name: "opaque"})
+
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing")
+	require.NoError(t, os.WriteFile(existing, []byte("a\nb\nc\n"), 0o644))
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{
+			name:    "unchanged file has no diff",
+			path:    existing,
+			content: "a\nb\nc\n",
+			want:    "",
+		},
+		{
+			name:    "changed line",
+			path:    existing,
+			content: "a\nB\nc\n",
+			want:    "--- label\n+++ label\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:    "missing file diffs against /dev/null",
+			path:    filepath.Join(dir, "missing"),
+			content: "new\n",
+			want:    "--- /dev/null\n+++ label\n@@ -0,0 +1 @@\n+new\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := unifiedDiff("label", tt.path, []byte(tt.content))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, diff)
+		})
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"booster/internal/pathutil"
 	"context"
 	"errors"
-	"fmt"
 	"os"
 )
 
@@ -26,7 +25,7 @@ func (t *DirCreate) Run(ctx context.Context) Result {
 	info, err := os.Stat(expanded)
 	if err == nil {
 		if info.IsDir() {
-			return Result{Status: StatusSkipped, Message: "already exists"}
+			return Result{Status: StatusSkipped, Message: "already exists", Data: expanded}
 		}
 		return Result{
 			Status: StatusFailed,
@@ -38,7 +37,21 @@ func (t *DirCreate) Run(ctx context.Context) Result {
 		return Result{Status: StatusFailed, Error: err}
 	}
 
-	return Result{Status: StatusDone, Message: "created"}
+	return Result{Status: StatusDone, Message: "created", Data: expanded}
+}
+
+func (t *DirCreate) Check(ctx context.Context) CheckResult {
+	info, err := os.Stat(pathutil.Expand(t.Path))
+	if err == nil {
+		if info.IsDir() {
+			return CheckResult{}
+		}
+		return CheckResult{Error: errors.New("path exists but is not a directory")}
+	}
+	if !os.IsNotExist(err) {
+		return CheckResult{Error: err}
+	}
+	return CheckResult{Changes: []string{"create directory " + t.Path}}
 }
 
 func NewDirCreate(args any) ([]Task, error) {
@@ -51,10 +64,22 @@ func NewDirCreate(args any) ([]Task, error) {
 	for i, p := range paths {
 		path, ok := p.(string)
 		if !ok {
-			return nil, fmt.Errorf("arg %d: path must be a string", i+1)
+			return nil, argErrorf(i, "", "path must be a string")
 		}
 		tasks = append(tasks, &DirCreate{Path: path})
 	}
 
 	return tasks, nil
 }
+
+func DirCreateSchema() map[string]any {
+	return map[string]any{
+		"type":        "array",
+		"description": "List of directory paths to create",
+		"items": map[string]any{
+			"type":        "string",
+			"description": "Directory path (supports ~ expansion)",
+		},
+		"examples": []any{[]string{"~/dev", "~/.config", "~/.local/bin"}},
+	}
+}
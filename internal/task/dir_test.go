@@ -126,3 +126,40 @@ func TestNewDirCreate_EmptyList(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, tasks)
 }
+
+func TestDirCreate_Check(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing")
+	require.NoError(t, os.Mkdir(existing, 0o755))
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name        string
+		path        string
+		wantChanges []string
+		wantErr     string
+	}{
+		{name: "in sync when directory exists", path: existing},
+		{name: "drift when directory is missing", path: missing, wantChanges: []string{"create directory " + missing}},
+		{name: "error when path is a file", path: file, wantErr: "path exists but is not a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := (&DirCreate{Path: tt.path}).Check(context.Background())
+
+			if tt.wantErr != "" {
+				require.Error(t, result.Error)
+				assert.Contains(t, result.Error.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, result.Error)
+			assert.Equal(t, tt.wantChanges, result.Changes)
+		})
+	}
+
+	_, err := os.Stat(missing)
+	assert.True(t, os.IsNotExist(err), "check must not create the directory")
+}
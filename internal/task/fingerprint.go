@@ -0,0 +1,27 @@
+package task
+
+import (
+	"booster/internal/config"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Fingerprinter is implemented by tasks whose inputs go beyond their args,
+// such as the content of a template source.
+type Fingerprinter interface {
+	Fingerprint() string
+}
+
+func fingerprint(ct config.Task, t Task) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%v\x00", ct.Action, ct.Args)
+	if ct.When != nil {
+		fmt.Fprintf(h, "%v", *ct.When)
+	}
+	fmt.Fprintf(h, "\x00%s\x00", t.Name())
+	if f, ok := t.(Fingerprinter); ok {
+		h.Write([]byte(f.Fingerprint()))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package task
+
+import (
+	"booster/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint_StableForSameInputs(t *testing.T) {
+	ct := config.Task{Action: "dir.create", Args: []any{"a"}}
+	task := &DirCreate{Path: "a"}
+
+	assert.Equal(t, fingerprint(ct, task), fingerprint(ct, task))
+}
+
+func TestFingerprint_ChangesWithInputs(t *testing.T) {
+	base := config.Task{Action: "dir.create", Args: []any{"a"}}
+	baseFP := fingerprint(base, &DirCreate{Path: "a"})
+
+	tests := []struct {
+		name string
+		ct   config.Task
+		task Task
+	}{
+		{
+			name: "different args",
+			ct:   config.Task{Action: "dir.create", Args: []any{"b"}},
+			task: &DirCreate{Path: "a"},
+		},
+		{
+			name: "different action",
+			ct:   config.Task{Action: "other", Args: []any{"a"}},
+			task: &DirCreate{Path: "a"},
+		},
+		{
+			name: "added condition",
+			ct:   config.Task{Action: "dir.create", Args: []any{"a"}, When: &config.When{OS: config.StringOrSlice{"arch"}}},
+			task: &DirCreate{Path: "a"},
+		},
+		{
+			name: "different expanded task",
+			ct:   base,
+			task: &DirCreate{Path: "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NotEqual(t, baseFP, fingerprint(tt.ct, tt.task))
+		})
+	}
+}
+
+func TestFingerprint_IncludesTemplateSource(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config.tmpl")
+	require.NoError(t, os.WriteFile(source, []byte("one"), 0o644))
+
+	ct := config.Task{Action: "template.render"}
+	tmpl := &TemplateRender{Source: source, Target: filepath.Join(dir, "out")}
+	before := fingerprint(ct, tmpl)
+
+	require.NoError(t, os.WriteFile(source, []byte("two"), 0o644))
+
+	assert.NotEqual(t, before, fingerprint(ct, tmpl))
+}
+
+func TestBuilder_BuildGraph_SetsFingerprints(t *testing.T) {
+	builder := NewBuilder().Register("dir.create", NewDirCreate)
+
+	g, err := builder.BuildGraph([]config.Task{
+		{Action: "dir.create", Args: []any{"a", "b"}},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 2, g.Len())
+	assert.NotEmpty(t, g.Nodes()[0].Fingerprint)
+	assert.NotEqual(t, g.Nodes()[0].Fingerprint, g.Nodes()[1].Fingerprint)
+}
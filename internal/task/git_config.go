@@ -18,6 +18,8 @@ type GitConfigItem struct {
 	Prompt string
 }
 
+// Without a Prompter, GitConfig fails before changing anything if a key would
+// be asked for.
 type GitConfig struct {
 	Runner   cmdexec.Runner
 	Prompter Prompter
@@ -42,20 +44,39 @@ func (t *GitConfig) NeedsSudo() bool {
 	return false
 }
 
+func (t *GitConfig) Resources() []string {
+	for _, item := range t.Items {
+		if item.Prompt != "" {
+			return []string{ResourceGitConfig, ResourceTerminal}
+		}
+	}
+	return []string{ResourceGitConfig}
+}
+
 func (t *GitConfig) Run(ctx context.Context) Result {
 	if len(t.Items) == 0 {
 		return Result{Status: StatusSkipped, Message: "no items to configure"}
 	}
+	if t.Prompter == nil {
+		if missing := t.UnsetPrompts(ctx); len(missing) > 0 {
+			return Result{
+				Status: StatusFailed,
+				Error:  fmt.Errorf("no value for %s and cannot prompt", strings.Join(missing, ", ")),
+			}
+		}
+	}
 
 	var configured []string
 	var skipped []string
 	var allOutput strings.Builder
+	values := make(map[string]any)
 
 	for _, item := range t.Items {
 		output, err := t.Runner.Run(ctx, "git", "config", "--global", "--get", item.Key)
 		existing := strings.TrimSpace(string(output))
 
 		if item.Value != "" {
+			values[item.Key] = item.Value
 			if existing == item.Value {
 				skipped = append(skipped, item.Key)
 				continue
@@ -78,15 +99,18 @@ func (t *GitConfig) Run(ctx context.Context) Result {
 		}
 
 		if err == nil && existing != "" {
+			values[item.Key] = existing
 			skipped = append(skipped, item.Key)
 			continue
 		}
 
 		if item.Prompt != "" {
+			// The value may have been readable when UnsetPrompts checked it.
 			if t.Prompter == nil {
 				return Result{
 					Status: StatusFailed,
-					Error:  fmt.Errorf("cannot prompt for %s: no prompter configured", item.Key),
+					Error:  fmt.Errorf("no value for %s and cannot prompt", item.Key),
+					Output: allOutput.String(),
 				}
 			}
 
@@ -110,6 +134,7 @@ func (t *GitConfig) Run(ctx context.Context) Result {
 					Output: allOutput.String(),
 				}
 			}
+			values[item.Key] = value
 			configured = append(configured, item.Key)
 		} else {
 			skipped = append(skipped, item.Key)
@@ -121,6 +146,7 @@ func (t *GitConfig) Run(ctx context.Context) Result {
 			Status:  StatusSkipped,
 			Message: "all keys already configured",
 			Output:  allOutput.String(),
+			Data:    values,
 		}
 	}
 
@@ -133,9 +159,48 @@ func (t *GitConfig) Run(ctx context.Context) Result {
 		Status:  StatusDone,
 		Message: msg,
 		Output:  allOutput.String(),
+		Data:    values,
 	}
 }
 
+func (t *GitConfig) UnsetPrompts(ctx context.Context) []string {
+	var keys []string
+	for _, item := range t.Items {
+		if item.Value != "" || item.Prompt == "" {
+			continue
+		}
+		output, err := t.Runner.Run(ctx, "git", "config", "--global", "--get", item.Key)
+		if err != nil || strings.TrimSpace(string(output)) == "" {
+			keys = append(keys, item.Key)
+		}
+	}
+	return keys
+}
+
+func (t *GitConfig) Check(ctx context.Context) CheckResult {
+	var changes []string
+	for _, item := range t.Items {
+		output, err := t.Runner.Run(ctx, "git", "config", "--global", "--get", item.Key)
+		existing := strings.TrimSpace(string(output))
+
+		switch {
+		case item.Value != "" && existing == "":
+			changes = append(changes, fmt.Sprintf("set %s to %q", item.Key, item.Value))
+		case item.Value != "" && existing != item.Value:
+			changes = append(changes, fmt.Sprintf("set %s to %q (current: %q)", item.Key, item.Value, existing))
+		case item.Value == "" && item.Prompt != "" && (err != nil || existing == ""):
+			changes = append(changes, fmt.Sprintf("%s is unset", item.Key))
+		}
+	}
+	return CheckResult{Changes: changes}
+}
+
+// GitConfigNeeds includes the terminal, since whether a key is prompted for is
+// only known once the args are resolved.
+func GitConfigNeeds() ActionNeeds {
+	return ActionNeeds{Resources: []string{ResourceGitConfig, ResourceTerminal}}
+}
+
 func NewGitConfig(runner cmdexec.Runner, prompter Prompter) Factory {
 	return func(args any) ([]Task, error) {
 		items, err := parseGitConfigArgs(args)
@@ -165,12 +230,12 @@ func parseGitConfigArgs(args any) ([]GitConfigItem, error) {
 	for i, arg := range list {
 		m, ok := arg.(map[string]any)
 		if !ok {
-			return nil, fmt.Errorf("arg %d: must be a map with 'key' field", i+1)
+			return nil, argErrorf(i, "", "must be a map with 'key' field")
 		}
 
 		key, ok := m["key"].(string)
 		if !ok || key == "" {
-			return nil, fmt.Errorf("arg %d: 'key' is required and must be a string", i+1)
+			return nil, argErrorf(i, "key", "'key' is required and must be a string")
 		}
 
 		value := ""
@@ -192,3 +257,30 @@ func parseGitConfigArgs(args any) ([]GitConfigItem, error) {
 
 	return items, nil
 }
+
+func GitConfigSchema() map[string]any {
+	return map[string]any{
+		"type":        "array",
+		"description": "List of git configuration items",
+		"items": map[string]any{
+			"type":                 "object",
+			"required":             []string{"key"},
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"key": map[string]any{
+					"type":        "string",
+					"minLength":   1,
+					"description": "Git config key (e.g., user.name, user.email)",
+				},
+				"value": map[string]any{
+					"type":        "string",
+					"description": "Value to set (if not prompting)",
+				},
+				"prompt": map[string]any{
+					"type":        "string",
+					"description": "Prompt text to ask user for value",
+				},
+			},
+		},
+	}
+}
@@ -288,7 +288,7 @@ func TestGitConfig_Prompting(t *testing.T) {
 			}
 			if tt.name == "fails when prompter not configured" {
 				assert.Error(t, result.Error)
-				assert.Contains(t, result.Error.Error(), "no prompter configured")
+				assert.EqualError(t, result.Error, "no value for user.name and cannot prompt")
 			}
 
 			if tt.checkCalls != nil && prompter != nil {
@@ -300,6 +300,95 @@ func TestGitConfig_Prompting(t *testing.T) {
 	}
 }
 
+func TestGitConfig_WithoutPrompterListsEveryMissingKey(t *testing.T) {
+	runner := &cmdexec.MockRunner{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			if len(args) == 4 && args[2] == "--get" {
+				if args[3] == "core.editor" {
+					return []byte("nvim\n"), nil
+				}
+				return nil, errors.New("exit status 1")
+			}
+			return nil, nil
+		},
+	}
+	task := &GitConfig{
+		Runner: runner,
+		Items: []GitConfigItem{
+			{Key: "init.defaultBranch", Value: "main"},
+			{Key: "user.name", Prompt: "Name?"},
+			{Key: "core.editor", Prompt: "Editor?"},
+			{Key: "user.email", Prompt: "Email?"},
+		},
+	}
+
+	result := task.Run(context.Background())
+
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.EqualError(t, result.Error, "no value for user.name, user.email and cannot prompt")
+	for _, call := range runner.Calls {
+		assert.Equal(t, "--get", call.Args[2], "nothing is set before failing")
+	}
+}
+
+func TestGitConfig_WithoutPrompterFailsWhenKeyBecomesUnreadable(t *testing.T) {
+	gets := 0
+	runner := &cmdexec.MockRunner{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			gets++
+			if gets == 1 {
+				return []byte("Alice\n"), nil
+			}
+			return nil, context.DeadlineExceeded
+		},
+	}
+	task := &GitConfig{
+		Runner: runner,
+		Items:  []GitConfigItem{{Key: "user.name", Prompt: "Name?"}},
+	}
+
+	result := task.Run(context.Background())
+
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.EqualError(t, result.Error, "no value for user.name and cannot prompt")
+}
+
+func TestGitConfig_OutputsEffectiveValues(t *testing.T) {
+	runner := &cmdexec.MockRunner{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			if len(args) == 4 && args[2] == "--get" {
+				if args[3] == "core.editor" {
+					return []byte("nvim\n"), nil
+				}
+				return nil, errors.New("exit status 1")
+			}
+			return nil, nil
+		},
+	}
+	prompter := &MockPrompter{PromptFunc: func(ctx context.Context, promptText string) (string, error) {
+		return "jane@example.com", nil
+	}}
+
+	task := &GitConfig{
+		Runner:   runner,
+		Prompter: prompter,
+		Items: []GitConfigItem{
+			{Key: "user.name", Value: "Jane Doe"},
+			{Key: "user.email", Prompt: "Email?"},
+			{Key: "core.editor", Prompt: "Editor?"},
+		},
+	}
+
+	result := task.Run(context.Background())
+
+	require.Equal(t, StatusDone, result.Status)
+	assert.Equal(t, map[string]any{
+		"user.name":   "Jane Doe",
+		"user.email":  "jane@example.com",
+		"core.editor": "nvim",
+	}, result.Data)
+}
+
 func TestGitConfig_HandlesMultipleItems(t *testing.T) {
 	runner := &cmdexec.MockRunner{
 		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, error) {
@@ -579,3 +668,45 @@ func TestNewGitConfig_ErrorIndexSecondArg(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "arg 2:", "error must show correct 1-indexed position")
 }
+
+func TestGitConfig_Check(t *testing.T) {
+	current := map[string]string{
+		"init.defaultBranch": "master",
+		"user.name":          "Jane",
+		"pull.rebase":        "true",
+	}
+	runner := &cmdexec.MockRunner{
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			if v, ok := current[args[3]]; ok {
+				return []byte(v + "\n"), nil
+			}
+			return nil, errors.New("exit status 1")
+		},
+	}
+	prompter := &MockPrompter{}
+	task := &GitConfig{
+		Runner:   runner,
+		Prompter: prompter,
+		Items: []GitConfigItem{
+			{Key: "init.defaultBranch", Value: "main"},
+			{Key: "pull.rebase", Value: "true"},
+			{Key: "core.editor", Value: "nvim"},
+			{Key: "user.name", Prompt: "Your name"},
+			{Key: "user.email", Prompt: "Your email"},
+			{Key: "user.signingkey"},
+		},
+	}
+
+	result := task.Check(context.Background())
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, []string{
+		`set init.defaultBranch to "main" (current: "master")`,
+		`set core.editor to "nvim"`,
+		"user.email is unset",
+	}, result.Changes)
+	assert.Empty(t, prompter.Calls, "check must not prompt")
+	for _, call := range runner.Calls {
+		assert.Equal(t, "--get", call.Args[2], "check must only read git config")
+	}
+}
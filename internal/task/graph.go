@@ -0,0 +1,216 @@
+package task
+
+import (
+	"booster/internal/config"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Node struct {
+	Task         Task
+	ID           string
+	Fingerprint  string
+	Deps         []int
+	IgnoreErrors bool
+	Retries      int
+	RetryDelay   time.Duration
+	Timeout      time.Duration
+}
+
+type Graph struct {
+	nodes []Node
+	scope *Scope
+}
+
+// Executors record task results into the scope.
+func (g *Graph) Scope() *Scope {
+	return g.scope
+}
+
+func (g *Graph) Len() int {
+	return len(g.nodes)
+}
+
+func (g *Graph) Nodes() []Node {
+	return g.nodes
+}
+
+func (g *Graph) Tasks() []Task {
+	if len(g.nodes) == 0 {
+		return nil
+	}
+	tasks := make([]Task, len(g.nodes))
+	for i, n := range g.nodes {
+		tasks[i] = n.Task
+	}
+	return tasks
+}
+
+func (g *Graph) DepsOf(i int) []int {
+	if i < 0 || i >= len(g.nodes) {
+		return nil
+	}
+	return g.nodes[i].Deps
+}
+
+// A task depends on those listed in depends_on and those whose results its
+// expressions read.
+func resolveDependencies(tasks []config.Task, exprs []taskExprs) ([][]int, error) {
+	ids := make(map[string]int, len(tasks))
+	for i, ct := range tasks {
+		if ct.ID == "" {
+			continue
+		}
+		if prev, ok := ids[ct.ID]; ok {
+			return nil, fmt.Errorf("%s: duplicate id %q (already used by %s)", taskRef(i, ct), ct.ID, taskRef(prev, tasks[prev]))
+		}
+		ids[ct.ID] = i
+	}
+
+	deps := make([][]int, len(tasks))
+	for i, ct := range tasks {
+		for _, ref := range ct.DependsOn {
+			dep, ok := ids[ref]
+			if !ok {
+				return nil, fmt.Errorf("%s (%s): depends_on references unknown task %q", taskRef(i, ct), ct.Action, ref)
+			}
+			deps[i] = append(deps[i], dep)
+		}
+		for _, ref := range exprs[i].taskRefs() {
+			dep, ok := ids[ref]
+			if !ok {
+				return nil, fmt.Errorf("%s (%s): expression references unknown task %q", taskRef(i, ct), ct.Action, ref)
+			}
+			if !slices.Contains(deps[i], dep) {
+				deps[i] = append(deps[i], dep)
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// Ties are broken by config position, so a config without any depends_on
+// keeps its YAML order.
+func topoOrder(tasks []config.Task, deps [][]int) ([]int, error) {
+	indegree := make([]int, len(deps))
+	dependents := make([][]int, len(deps))
+	for i, ds := range deps {
+		indegree[i] = len(ds)
+		for _, d := range ds {
+			dependents[d] = append(dependents[d], i)
+		}
+	}
+
+	order := make([]int, 0, len(deps))
+	done := make([]bool, len(deps))
+	for len(order) < len(deps) {
+		next := -1
+		for i := range deps {
+			if !done[i] && indegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, cycleError(tasks, deps, done)
+		}
+
+		done[next] = true
+		order = append(order, next)
+		for _, d := range dependents[next] {
+			indegree[d]--
+		}
+	}
+
+	return order, nil
+}
+
+func cycleError(tasks []config.Task, deps [][]int, done []bool) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(deps))
+	var stack []int
+	var cycle []int
+
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		state[i] = visiting
+		stack = append(stack, i)
+		for _, d := range deps[i] {
+			if state[d] == visiting {
+				for j, s := range stack {
+					if s == d {
+						cycle = append(append(cycle, stack[j:]...), d)
+						break
+					}
+				}
+				return true
+			}
+			if state[d] == unvisited && visit(d) {
+				return true
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = visited
+		return false
+	}
+
+	for i := range deps {
+		if !done[i] && state[i] == unvisited && visit(i) {
+			break
+		}
+	}
+
+	names := make([]string, len(cycle))
+	for i, idx := range cycle {
+		names[i] = taskLabel(tasks, idx)
+	}
+	return fmt.Errorf("dependency cycle: %s", strings.Join(names, " -> "))
+}
+
+func taskLabel(tasks []config.Task, i int) string {
+	if tasks[i].ID != "" {
+		return tasks[i].ID
+	}
+	return taskRef(i, tasks[i])
+}
+
+func taskRef(i int, ct config.Task) string {
+	if ct.Origin.Index > 0 {
+		return ct.Origin.String()
+	}
+	return fmt.Sprintf("task %d", i+1)
+}
+
+// Errors are located at pos, unless err is already located in a file the task
+// reads, such as a darwin.defaults file.
+func taskError(i int, ct config.Task, pos config.Position, err error) error {
+	if ct.Origin.Line == 0 {
+		return fmt.Errorf("%s (%s): %w", taskRef(i, ct), ct.Action, err)
+	}
+
+	var perr *config.PositionError
+	if errors.As(err, &perr) {
+		return &config.PositionError{
+			Pos:     perr.Pos,
+			Err:     fmt.Errorf("%s (%s): %w", ct.Origin, ct.Action, perr.Err),
+			Snippet: perr.Snippet,
+		}
+	}
+	return config.ErrorAt(pos, fmt.Errorf("task %d (%s): %w", ct.Origin.Index, ct.Action, err))
+}
+
+func argPos(ct config.Task, err error) config.Position {
+	var aerr *ArgError
+	if errors.As(err, &aerr) {
+		return ct.ArgPos(aerr.Index, aerr.Key)
+	}
+	return ct.Pos("args")
+}
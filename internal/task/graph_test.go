@@ -0,0 +1,280 @@
+package task
+
+import (
+	"booster/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func taskNames(tasks []Task) []string {
+	names := make([]string, len(tasks))
+	for i, t := range tasks {
+		names[i] = t.Name()
+	}
+	return names
+}
+
+func TestBuilder_BuildGraph_OrdersByDependencies(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []config.Task
+		want  []string
+	}{
+		{
+			name: "no dependencies keeps config order",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: []any{"a"}},
+				{Action: "dir.create", Args: []any{"b"}},
+				{Action: "dir.create", Args: []any{"c"}},
+			},
+			want: []string{"create a", "create b", "create c"},
+		},
+		{
+			name: "dependency defined later runs first",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: []any{"a"}, DependsOn: config.StringOrSlice{"base"}},
+				{Action: "dir.create", Args: []any{"b"}},
+				{Action: "dir.create", Args: []any{"base"}, ID: "base"},
+			},
+			want: []string{"create b", "create base", "create a"},
+		},
+		{
+			name: "chain of dependencies",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: []any{"c"}, ID: "c", DependsOn: config.StringOrSlice{"b"}},
+				{Action: "dir.create", Args: []any{"b"}, ID: "b", DependsOn: config.StringOrSlice{"a"}},
+				{Action: "dir.create", Args: []any{"a"}, ID: "a"},
+			},
+			want: []string{"create a", "create b", "create c"},
+		},
+		{
+			name: "multiple dependencies",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: []any{"last"}, DependsOn: config.StringOrSlice{"x", "y"}},
+				{Action: "dir.create", Args: []any{"y"}, ID: "y"},
+				{Action: "dir.create", Args: []any{"x"}, ID: "x"},
+			},
+			want: []string{"create y", "create x", "create last"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewBuilder().Register("dir.create", NewDirCreate)
+
+			g, err := builder.BuildGraph(tt.tasks)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, taskNames(g.Tasks()))
+		})
+	}
+}
+
+func TestBuilder_BuildGraph_DepsPointAtAllExpandedTasks(t *testing.T) {
+	builder := NewBuilder().Register("dir.create", NewDirCreate)
+
+	g, err := builder.BuildGraph([]config.Task{
+		{Action: "dir.create", Args: []any{"a", "b"}, ID: "dirs"},
+		{Action: "dir.create", Args: []any{"c"}, DependsOn: config.StringOrSlice{"dirs"}},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 3, g.Len())
+	assert.Empty(t, g.DepsOf(0))
+	assert.Empty(t, g.DepsOf(1))
+	assert.Equal(t, []int{0, 1}, g.DepsOf(2))
+	assert.Equal(t, "dirs", g.Nodes()[0].ID)
+	assert.Equal(t, "dirs", g.Nodes()[1].ID)
+	assert.Empty(t, g.Nodes()[2].ID)
+}
+
+func TestBuilder_BuildGraph_CarriesTaskOptions(t *testing.T) {
+	builder := NewBuilder().Register("dir.create", NewDirCreate)
+
+	g, err := builder.BuildGraph([]config.Task{
+		{Action: "dir.create", Args: []any{"a", "b"}, IgnoreErrors: true, Retries: 2, RetryDelay: time.Second, Timeout: time.Minute},
+		{Action: "dir.create", Args: []any{"c"}},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 3, g.Len())
+	for _, n := range g.Nodes()[:2] {
+		assert.True(t, n.IgnoreErrors)
+		assert.Equal(t, 2, n.Retries)
+		assert.Equal(t, time.Second, n.RetryDelay)
+		assert.Equal(t, time.Minute, n.Timeout)
+	}
+	assert.False(t, g.Nodes()[2].IgnoreErrors)
+	assert.Zero(t, g.Nodes()[2].Retries)
+}
+
+func TestBuilder_BuildGraph_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tasks   []config.Task
+		wantErr string
+	}{
+		{
+			name: "unknown reference",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: []any{"a"}, DependsOn: config.StringOrSlice{"missing"}},
+			},
+			wantErr: `task 1 (dir.create): depends_on references unknown task "missing"`,
+		},
+		{
+			name: "duplicate id",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: []any{"a"}, ID: "dup"},
+				{Action: "dir.create", Args: []any{"b"}, ID: "dup"},
+			},
+			wantErr: `task 2: duplicate id "dup" (already used by task 1)`,
+		},
+		{
+			name: "self dependency",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: []any{"a"}, ID: "a", DependsOn: config.StringOrSlice{"a"}},
+			},
+			wantErr: "dependency cycle: a -> a",
+		},
+		{
+			name: "two task cycle",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: []any{"a"}, ID: "a", DependsOn: config.StringOrSlice{"b"}},
+				{Action: "dir.create", Args: []any{"b"}, ID: "b", DependsOn: config.StringOrSlice{"a"}},
+			},
+			wantErr: "dependency cycle: a -> b -> a",
+		},
+		{
+			name: "cycle behind an acyclic task",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: []any{"ok"}, ID: "ok"},
+				{Action: "dir.create", Args: []any{"x"}, ID: "x", DependsOn: config.StringOrSlice{"ok", "z"}},
+				{Action: "dir.create", Args: []any{"y"}, ID: "y", DependsOn: config.StringOrSlice{"x"}},
+				{Action: "dir.create", Args: []any{"z"}, ID: "z", DependsOn: config.StringOrSlice{"y"}},
+			},
+			wantErr: "dependency cycle: x -> z -> y -> x",
+		},
+		{
+			name: "errors name the defining file",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: []any{"a"}, ID: "dup", Origin: config.Origin{Position: config.Position{File: "config.yaml"}, Index: 1}},
+				{Action: "dir.create", Args: []any{"b"}, ID: "dup", Origin: config.Origin{Position: config.Position{File: "dotfiles.yaml"}, Index: 3}},
+			},
+			wantErr: `dotfiles.yaml: task 3: duplicate id "dup" (already used by config.yaml: task 1)`,
+		},
+		{
+			name: "factory errors name the defining file",
+			tasks: []config.Task{
+				{Action: "dir.create", Args: "not a list", Origin: config.Origin{Position: config.Position{File: "packages.yaml"}, Index: 2}},
+			},
+			wantErr: "packages.yaml: task 2 (dir.create): ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewBuilder().Register("dir.create", NewDirCreate)
+
+			_, err := builder.BuildGraph(tt.tasks)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestBuilder_Build_ReturnsTopologicalOrder(t *testing.T) {
+	builder := NewBuilder().Register("dir.create", NewDirCreate)
+
+	tasks, err := builder.Build([]config.Task{
+		{Action: "dir.create", Args: []any{"second"}, DependsOn: config.StringOrSlice{"first"}},
+		{Action: "dir.create", Args: []any{"first"}, ID: "first"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create first", "create second"}, taskNames(tasks))
+}
+
+func TestBuilder_BuildGraph_ErrorPositions(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "factory error points at the argument",
+			files: map[string]string{"config.yaml": `version: "1"
+tasks:
+  - action: dir.create
+    args:
+      - ~/src
+      - 42
+`},
+			wantErr: "config.yaml:6:9: task 1 (dir.create): arg 2: path must be a string\n" +
+				"  6 |       - 42\n" +
+				"    |         ^",
+		},
+		{
+			name: "unknown action points at the action",
+			files: map[string]string{"config.yaml": `version: "1"
+tasks:
+  - action: dir.make
+    args: [~/src]
+`},
+			wantErr: `config.yaml:3:13: task 1: unknown action "dir.make"`,
+		},
+		{
+			name: "invalid when expression points at the condition",
+			files: map[string]string{"config.yaml": `version: "1"
+tasks:
+  - action: dir.create
+    args: [~/src]
+    when: "${ 1 + }"
+`},
+			wantErr: "config.yaml:5:11: task 1 (dir.create): when: invalid expression",
+		},
+		{
+			name: "defaults file errors point into the defaults file",
+			files: map[string]string{
+				"config.yaml": `version: "1"
+tasks:
+  - action: darwin.defaults
+    args: {file: macos.yaml}
+`,
+				"macos.yaml": `defaults:
+  - domain: com.apple.dock
+    key: autohide
+    type: boolean
+    value: true
+`,
+			},
+			wantErr: "macos.yaml:4:11: config.yaml:3:5: task 1 (darwin.defaults): entry 1: invalid type \"boolean\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+			}
+			cfg, err := config.Load(filepath.Join(dir, "config.yaml"))
+			require.NoError(t, err)
+
+			builder := NewBuilder().
+				Register("dir.create", NewDirCreate).
+				Register("darwin.defaults", NewDarwinDefaultsFactory(DarwinDefaultsConfig{OS: "darwin", ConfigDir: dir}))
+
+			_, err = builder.BuildGraph(cfg.Tasks)
+
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), tt.wantErr), "got: %s", err)
+		})
+	}
+}
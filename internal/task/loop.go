@@ -0,0 +1,47 @@
+package task
+
+import (
+	"booster/internal/expr"
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Map values are keyed by their key in sorted order. A task without for_each
+// has a single nil item.
+func loopItems(forEach *expr.Value, ctx *expr.Context) ([]*loopItem, error) {
+	if forEach == nil {
+		return []*loopItem{nil}, nil
+	}
+
+	v, err := forEach.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]*loopItem, rv.Len())
+		for i := range rv.Len() {
+			items[i] = &loopItem{key: i, value: rv.Index(i).Interface()}
+		}
+		return items, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map keys must be strings, got %s", rv.Type().Key())
+		}
+		keys := rv.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(a.String(), b.String())
+		})
+		items := make([]*loopItem, len(keys))
+		for i, k := range keys {
+			items[i] = &loopItem{key: k.String(), value: rv.MapIndex(k).Interface()}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("must be a list or map, got %T", v)
+	}
+}
@@ -0,0 +1,158 @@
+package task
+
+import (
+	"booster/internal/condition"
+	"booster/internal/config"
+	"booster/internal/expr"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoopItems(t *testing.T) {
+	ctx := expr.NewContext().WithVars(map[string]any{
+		"projects": map[string]any{"web": "w", "api": "a"},
+		"csv":      "x,y",
+		"name":     "scalar",
+	})
+
+	tests := []struct {
+		name    string
+		forEach any
+		want    []*loopItem
+		wantErr string
+	}{
+		{
+			name:    "list keyed by index",
+			forEach: []any{"a", "b"},
+			want:    []*loopItem{{key: 0, value: "a"}, {key: 1, value: "b"}},
+		},
+		{
+			name:    "map keyed by sorted key",
+			forEach: map[string]any{"zsh": 1, "bash": 2},
+			want:    []*loopItem{{key: "bash", value: 2}, {key: "zsh", value: 1}},
+		},
+		{
+			name:    "expression evaluating to a map",
+			forEach: "${ vars.projects }",
+			want:    []*loopItem{{key: "api", value: "a"}, {key: "web", value: "w"}},
+		},
+		{
+			name:    "expression evaluating to a string list",
+			forEach: "${ split(vars.csv, ',') }",
+			want:    []*loopItem{{key: 0, value: "x"}, {key: 1, value: "y"}},
+		},
+		{
+			name:    "empty list",
+			forEach: []any{},
+			want:    []*loopItem{},
+		},
+		{
+			name:    "scalar is an error",
+			forEach: "${ vars.name }",
+			wantErr: "must be a list or map, got string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := expr.NewValue(tt.forEach)
+			require.NoError(t, err)
+
+			got, err := loopItems(v, ctx)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuilder_BuildGraph_ForEach(t *testing.T) {
+	var got []any
+	g, err := captureBuilder(&got).BuildGraph([]config.Task{
+		{
+			Action:  "capture",
+			ID:      "dirs",
+			ForEach: map[string]any{"web": "git@example.com:web.git", "api": "git@example.com:api.git"},
+			Args:    map[string]any{"path": "~/src/${ key }", "repo": "${ item }"},
+		},
+		{Action: "capture", Args: "after", DependsOn: config.StringOrSlice{"dirs"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{
+		map[string]any{"path": "~/src/api", "repo": "git@example.com:api.git"},
+		map[string]any{"path": "~/src/web", "repo": "git@example.com:web.git"},
+		"after",
+	}, got)
+
+	nodes := g.Nodes()
+	require.Len(t, nodes, 3)
+	assert.Equal(t, "dirs", nodes[0].ID)
+	assert.Equal(t, "dirs", nodes[1].ID)
+	assert.NotEqual(t, nodes[0].Fingerprint, nodes[1].Fingerprint, "each item has its own fingerprint")
+	assert.Equal(t, []int{0, 1}, nodes[2].Deps, "dependents wait for every item")
+}
+
+func TestBuilder_BuildGraph_ForEachWhenReadsItem(t *testing.T) {
+	var got []any
+	builder := captureBuilder(&got).WithEvaluator(condition.NewEvaluator(condition.Context{}))
+	g, err := builder.BuildGraph([]config.Task{
+		{
+			Action:  "capture",
+			ForEach: []any{"keep", "drop"},
+			Args:    "${ item }",
+			When:    &config.When{Expr: "${ item != 'drop' }"},
+		},
+	})
+	require.NoError(t, err)
+
+	nodes := g.Nodes()
+	require.Len(t, nodes, 2)
+	assert.Equal(t, StatusDone, nodes[0].Task.Run(context.Background()).Status)
+	assert.Equal(t, StatusSkipped, nodes[1].Task.Run(context.Background()).Status)
+}
+
+func TestBuilder_BuildGraph_ForEachErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tasks   []config.Task
+		wantErr string
+	}{
+		{
+			name:    "not a list or map",
+			tasks:   []config.Task{{Action: "capture", ForEach: 3}},
+			wantErr: "task 1 (capture): for_each: must be a list or map, got int",
+		},
+		{
+			name:    "invalid expression",
+			tasks:   []config.Task{{Action: "capture", ForEach: "${ 1 + }"}},
+			wantErr: "task 1 (capture): for_each: invalid expression",
+		},
+		{
+			name: "reads task results",
+			tasks: []config.Task{
+				{Action: "capture", ID: "list"},
+				{Action: "capture", ForEach: "${ tasks.list.output }"},
+			},
+			wantErr: "task 2 (capture): for_each cannot read task results",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []any
+			_, err := captureBuilder(&got).BuildGraph(tt.tasks)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -51,6 +51,10 @@ func (t *MiseUse) NeedsSudo() bool {
 	return false
 }
 
+func (t *MiseUse) Resources() []string {
+	return []string{ResourceMise}
+}
+
 func (t *MiseUse) Run(ctx context.Context) Result {
 	runner := t.Runner
 	if runner == nil {
@@ -85,6 +89,31 @@ func (t *MiseUse) Run(ctx context.Context) Result {
 	return Result{Status: StatusDone, Message: msg, Output: allOutput.String()}
 }
 
+func (t *MiseUse) Check(ctx context.Context) CheckResult {
+	runner := t.Runner
+	if runner == nil {
+		runner = cmdexec.DefaultRunner()
+	}
+
+	if err := t.checkMiseAvailable(runner); err != nil {
+		return CheckResult{Error: err}
+	}
+
+	var changes []string
+	for _, tool := range t.Tools {
+		current := t.getCurrentVersion(ctx, runner, tool.Name)
+		if current == tool.Version {
+			continue
+		}
+		if current == "" {
+			changes = append(changes, fmt.Sprintf("use %s (not installed)", tool))
+		} else {
+			changes = append(changes, fmt.Sprintf("use %s (current: %s)", tool, current))
+		}
+	}
+	return CheckResult{Changes: changes}
+}
+
 func (t *MiseUse) checkMiseAvailable(runner cmdexec.Runner) error {
 	_, err := runner.LookPath("mise")
 	if err != nil {
@@ -125,6 +154,10 @@ type MiseUseConfig struct {
 	Runner cmdexec.Runner
 }
 
+func MiseUseNeeds() ActionNeeds {
+	return ActionNeeds{Resources: []string{ResourceMise}}
+}
+
 func NewMiseUseFactory(cfg MiseUseConfig) Factory {
 	return func(args any) ([]Task, error) {
 		list, ok := args.([]any)
@@ -136,11 +169,11 @@ func NewMiseUseFactory(cfg MiseUseConfig) Factory {
 		for i, item := range list {
 			s, ok := item.(string)
 			if !ok {
-				return nil, fmt.Errorf("arg %d: must be a string", i+1)
+				return nil, argErrorf(i, "", "must be a string")
 			}
 			spec, err := parseToolSpec(s)
 			if err != nil {
-				return nil, fmt.Errorf("arg %d: %w", i+1, err)
+				return nil, &ArgError{Index: i, Err: err}
 			}
 			tools = append(tools, spec)
 		}
@@ -160,3 +193,16 @@ func NewMiseUseFactory(cfg MiseUseConfig) Factory {
 		}}, nil
 	}
 }
+
+func MiseUseSchema() map[string]any {
+	return map[string]any{
+		"type":        "array",
+		"description": "List of tools with versions in tool@version format",
+		"items": map[string]any{
+			"type":        "string",
+			"pattern":     "^[^@]+@.+$",
+			"description": "Tool specification (e.g., go@1.22.0, node@20.10.0)",
+		},
+		"examples": []any{[]string{"go@1.22.0", "node@20.10.0", "rust@1.75.0"}},
+	}
+}
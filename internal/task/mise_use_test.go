@@ -490,3 +490,49 @@ func TestMiseUseFactory_UsesProvidedRunner(t *testing.T) {
 	assert.Equal(t, StatusFailed, result.Status)
 	assert.Contains(t, result.Error.Error(), "mise not found")
 }
+
+func TestMiseUse_Check(t *testing.T) {
+	runner := &cmdexec.MockRunner{
+		LookPathFunc: func(name string) (string, error) { return "/usr/bin/mise", nil },
+		RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			switch args[1] {
+			case "go":
+				return []byte("1.22.0\n"), nil
+			case "node":
+				return []byte("18.0.0\n"), nil
+			}
+			return nil, errors.New("not installed")
+		},
+	}
+	task := &MiseUse{
+		Runner: runner,
+		Tools: []ToolSpec{
+			{Name: "go", Version: "1.22.0"},
+			{Name: "node", Version: "20.10.0"},
+			{Name: "bun", Version: "1.0.18"},
+		},
+	}
+
+	result := task.Check(context.Background())
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, []string{
+		"use node@20.10.0 (current: 18.0.0)",
+		"use bun@1.0.18 (not installed)",
+	}, result.Changes)
+	for _, call := range runner.Calls {
+		assert.NotEqual(t, "use", call.Args[0], "check must not run mise use")
+	}
+}
+
+func TestMiseUse_Check_MiseMissing(t *testing.T) {
+	runner := &cmdexec.MockRunner{
+		LookPathFunc: func(name string) (string, error) { return "", errors.New("not found") },
+	}
+	task := &MiseUse{Runner: runner, Tools: []ToolSpec{{Name: "go", Version: "1.22.0"}}}
+
+	result := task.Check(context.Background())
+
+	require.Error(t, result.Error)
+	assert.Contains(t, result.Error.Error(), "mise not found")
+}
@@ -13,6 +13,7 @@ type mockPackageManager struct {
 	installCalls   [][]string
 	caskCalls      [][]string
 	supportsCasks  bool
+	listCtx        context.Context
 }
 
 func newMockManager(name string, supportsCasks bool) *mockPackageManager {
@@ -27,6 +28,7 @@ func newMockManager(name string, supportsCasks bool) *mockPackageManager {
 func (m *mockPackageManager) Name() string { return m.name }
 
 func (m *mockPackageManager) ListInstalled(ctx context.Context) ([]string, error) {
+	m.listCtx = ctx
 	if m.listErr != nil {
 		return nil, m.listErr
 	}
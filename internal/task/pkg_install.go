@@ -2,6 +2,7 @@ package task
 
 import (
 	"booster/internal/cmdexec"
+	"booster/internal/logstream"
 	"context"
 	"errors"
 	"fmt"
@@ -220,7 +221,16 @@ func (t *PkgInstall) Name() string {
 }
 
 func (t *PkgInstall) NeedsSudo() bool {
-	return t.OS != "darwin"
+	return pkgInstallNeedsSudo(t.OS)
+}
+
+// Homebrew refuses to run as root; other package managers need it.
+func pkgInstallNeedsSudo(os string) bool {
+	return os != "darwin"
+}
+
+func (t *PkgInstall) Resources() []string {
+	return []string{ResourcePackageManager}
 }
 
 func (t *PkgInstall) Run(ctx context.Context) Result {
@@ -232,7 +242,8 @@ func (t *PkgInstall) Run(ctx context.Context) Result {
 		}
 	}
 
-	queryCtx := context.Background()
+	// Package listings are not interesting in the task log.
+	queryCtx := logstream.WithWriter(ctx, nil)
 
 	toInstall, err := t.findMissingPackages(queryCtx)
 	if err != nil {
@@ -251,6 +262,32 @@ func (t *PkgInstall) Run(ctx context.Context) Result {
 	return t.performInstallation(ctx, toInstall, casksToInstall)
 }
 
+func (t *PkgInstall) Check(ctx context.Context) CheckResult {
+	if err := t.validateCaskSupport(); err != nil {
+		return CheckResult{Error: err}
+	}
+
+	queryCtx := logstream.WithWriter(ctx, nil)
+
+	missing, err := t.findMissingPackages(queryCtx)
+	if err != nil {
+		return CheckResult{Error: err}
+	}
+	missingCasks, err := t.findMissingCasks(queryCtx)
+	if err != nil {
+		return CheckResult{Error: err}
+	}
+
+	var changes []string
+	if len(missing) > 0 {
+		changes = append(changes, "install packages: "+strings.Join(missing, ", "))
+	}
+	if len(missingCasks) > 0 {
+		changes = append(changes, "install casks: "+strings.Join(missingCasks, ", "))
+	}
+	return CheckResult{Changes: changes}
+}
+
 func (t *PkgInstall) validateCaskSupport() error {
 	if len(t.Casks) > 0 && t.OS != "darwin" && !t.Manager.SupportsCasks() {
 		return fmt.Errorf("casks specified but OS is %s (not darwin)", t.OS)
@@ -377,6 +414,10 @@ type PkgInstallConfig struct {
 	PathFinder BrewPathFinder
 }
 
+func PkgInstallNeeds(cfg PkgInstallConfig) ActionNeeds {
+	return ActionNeeds{Sudo: pkgInstallNeedsSudo(cfg.OS), Resources: []string{ResourcePackageManager}}
+}
+
 func NewPkgInstallFactory(cfg PkgInstallConfig) Factory {
 	return func(args any) ([]Task, error) {
 		packages, casks, err := parsePkgInstallArgs(args)
@@ -421,14 +462,14 @@ func parsePkgInstallArgs(args any) (packages, casks []string, err error) {
 		case map[string]any:
 
 			if pkgs, ok := v["packages"]; ok {
-				parsed, err := parseStringList(pkgs, fmt.Sprintf("arg %d packages", i+1))
+				parsed, err := parseStringList(pkgs, i, "packages")
 				if err != nil {
 					return nil, nil, err
 				}
 				packages = append(packages, parsed...)
 			}
 			if caskList, ok := v["casks"]; ok {
-				parsed, err := parseStringList(caskList, fmt.Sprintf("arg %d casks", i+1))
+				parsed, err := parseStringList(caskList, i, "casks")
 				if err != nil {
 					return nil, nil, err
 				}
@@ -436,26 +477,53 @@ func parsePkgInstallArgs(args any) (packages, casks []string, err error) {
 			}
 
 		default:
-			return nil, nil, fmt.Errorf("arg %d: must be a string or map, got %T", i+1, item)
+			return nil, nil, argErrorf(i, "", "must be a string or map, got %T", item)
 		}
 	}
 
 	return packages, casks, nil
 }
 
-func parseStringList(v any, context string) ([]string, error) {
+func parseStringList(v any, index int, key string) ([]string, error) {
 	list, ok := v.([]any)
 	if !ok {
-		return nil, fmt.Errorf("%s: must be a list", context)
+		return nil, argErrorf(index, key, "%s: must be a list", key)
 	}
 
 	var result []string
 	for i, item := range list {
 		s, ok := item.(string)
 		if !ok {
-			return nil, fmt.Errorf("%s[%d]: must be a string", context, i)
+			return nil, argErrorf(index, key, "%s[%d]: must be a string", key, i)
 		}
 		result = append(result, s)
 	}
 	return result, nil
 }
+
+func PkgInstallSchema() map[string]any {
+	stringList := func(description string) map[string]any {
+		return map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": description,
+		}
+	}
+	return map[string]any{
+		"type":        "array",
+		"description": "List of packages to install (strings or structured objects)",
+		"items": map[string]any{
+			"oneOf": []any{
+				map[string]any{"type": "string", "description": "Package name"},
+				map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"properties": map[string]any{
+						"packages": stringList("List of regular packages"),
+						"casks":    stringList("List of Homebrew casks (macOS only)"),
+					},
+				},
+			},
+		},
+	}
+}
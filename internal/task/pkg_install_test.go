@@ -2,6 +2,7 @@ package task
 
 import (
 	"booster/internal/cmdexec"
+	"booster/internal/logstream"
 	"context"
 	"errors"
 	"testing"
@@ -28,6 +29,26 @@ func TestPkgInstall_SkipsWhenAllInstalled(t *testing.T) {
 	assert.Empty(t, manager.installCalls, "should not call install")
 }
 
+func TestPkgInstall_QueriesWithRunContextWithoutLogWriter(t *testing.T) {
+	manager := newMockManager("paru", false)
+	manager.installed["git"] = true
+
+	task := &PkgInstall{
+		Packages: []string{"git"},
+		Manager:  manager,
+		OS:       "arch",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w, _ := logstream.NewChannelWriter(10)
+	task.Run(logstream.WithWriter(ctx, w))
+	cancel()
+
+	require.NotNil(t, manager.listCtx)
+	assert.Nil(t, logstream.Writer(manager.listCtx), "query output should not reach the task log")
+	assert.ErrorIs(t, manager.listCtx.Err(), context.Canceled, "query should be cancelled with the run")
+}
+
 func TestPkgInstall_InstallsMissingPackages(t *testing.T) {
 	manager := newMockManager("paru", false)
 	manager.installed["git"] = true
@@ -756,3 +777,65 @@ func TestNewPkgInstallFactory_CreatesHomebrewManagerOnDarwin(t *testing.T) {
 	}
 	assert.True(t, brewCalled, "should use homebrew manager on darwin")
 }
+
+func TestPkgInstall_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		packages    []string
+		casks       []string
+		installed   []string
+		casksOK     []string
+		wantChanges []string
+	}{
+		{
+			name:      "in sync when everything is installed",
+			packages:  []string{"git", "neovim"},
+			installed: []string{"git", "neovim"},
+		},
+		{
+			name:        "lists missing packages",
+			packages:    []string{"git", "neovim", "ripgrep"},
+			installed:   []string{"git"},
+			wantChanges: []string{"install packages: neovim, ripgrep"},
+		},
+		{
+			name:        "lists missing casks",
+			packages:    []string{"git"},
+			casks:       []string{"firefox", "iterm2"},
+			installed:   []string{"git"},
+			casksOK:     []string{"iterm2"},
+			wantChanges: []string{"install casks: firefox"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := newMockManager("homebrew", true)
+			for _, p := range tt.installed {
+				mgr.installed[p] = true
+			}
+			for _, c := range tt.casksOK {
+				mgr.casksInstalled[c] = true
+			}
+			task := &PkgInstall{Manager: mgr, OS: "darwin", Packages: tt.packages, Casks: tt.casks}
+
+			result := task.Check(context.Background())
+
+			require.NoError(t, result.Error)
+			assert.Equal(t, tt.wantChanges, result.Changes)
+			assert.Empty(t, mgr.installCalls, "check must not install anything")
+			assert.Empty(t, mgr.caskCalls, "check must not install anything")
+		})
+	}
+}
+
+func TestPkgInstall_Check_ListError(t *testing.T) {
+	mgr := newMockManager("paru", false)
+	mgr.listErr = errors.New("pacman broke")
+	task := &PkgInstall{Manager: mgr, OS: "arch", Packages: []string{"git"}}
+
+	result := task.Check(context.Background())
+
+	require.Error(t, result.Error)
+	assert.Contains(t, result.Error.Error(), "pacman broke")
+}
@@ -23,46 +23,72 @@ func (t *PkgManagerInstall) NeedsSudo() bool {
 	return true
 }
 
+func (t *PkgManagerInstall) Resources() []string {
+	return []string{ResourcePackageManager}
+}
+
 func (t *PkgManagerInstall) Run(ctx context.Context) Result {
 	runner := t.Runner
 	if runner == nil {
 		runner = cmdexec.DefaultRunner()
 	}
 
-	binaryExists := t.checkBinaryExists(runner)
+	path, binaryExists := t.binaryPath(runner)
 	packageRegistered := t.checkPackageRegistered(ctx, runner)
 
 	if binaryExists && packageRegistered {
-		return Result{Status: StatusSkipped, Message: "already installed"}
+		return Result{Status: StatusSkipped, Message: "already installed", Data: path}
 	}
 
+	var result Result
 	switch t.Manager {
 	case "paru":
-		return t.installParu(ctx, runner)
+		result = t.installParu(ctx, runner)
 	case "yay":
-		return t.installYay(ctx, runner)
+		result = t.installYay(ctx, runner)
 	case "homebrew":
-		return t.installHomebrew(ctx, runner)
+		result = t.installHomebrew(ctx, runner)
 	default:
 		return Result{
 			Status: StatusFailed,
 			Error:  fmt.Errorf("unsupported package manager: %s", t.Manager),
 		}
 	}
+
+	if result.Status == StatusDone {
+		result.Data, _ = t.binaryPath(runner)
+	}
+	return result
+}
+
+func (t *PkgManagerInstall) Check(ctx context.Context) CheckResult {
+	runner := t.Runner
+	if runner == nil {
+		runner = cmdexec.DefaultRunner()
+	}
+
+	if t.checkBinaryExists(runner) && t.checkPackageRegistered(ctx, runner) {
+		return CheckResult{}
+	}
+	return CheckResult{Changes: []string{"install " + t.Manager}}
 }
 
 func (t *PkgManagerInstall) checkBinaryExists(runner cmdexec.Runner) bool {
+	_, found := t.binaryPath(runner)
+	return found
+}
+
+func (t *PkgManagerInstall) binaryPath(runner cmdexec.Runner) (string, bool) {
 	if t.Manager == "homebrew" {
 		finder := t.PathFinder
 		if finder == nil {
 			finder = defaultBrewPathFinder
 		}
-		_, found := finder()
-		return found
+		return finder()
 	}
 
-	_, err := runner.LookPath(t.Manager)
-	return err == nil
+	path, err := runner.LookPath(t.Manager)
+	return path, err == nil
 }
 
 func (t *PkgManagerInstall) checkPackageRegistered(ctx context.Context, runner cmdexec.Runner) bool {
@@ -154,6 +180,10 @@ func (t *PkgManagerInstall) installHomebrew(ctx context.Context, runner cmdexec.
 	return Result{Status: StatusDone, Message: "installed", Output: allOutput}
 }
 
+func PkgManagerInstallNeeds() ActionNeeds {
+	return ActionNeeds{Sudo: true, Resources: []string{ResourcePackageManager}}
+}
+
 func NewPkgManagerInstallFactory(runner cmdexec.Runner) Factory {
 	return func(args any) ([]Task, error) {
 		list, ok := args.([]any)
@@ -165,7 +195,7 @@ func NewPkgManagerInstallFactory(runner cmdexec.Runner) Factory {
 		for i, item := range list {
 			name, ok := item.(string)
 			if !ok {
-				return nil, fmt.Errorf("arg %d: must be a string", i+1)
+				return nil, argErrorf(i, "", "must be a string")
 			}
 			tasks = append(tasks, &PkgManagerInstall{
 				Manager: name,
@@ -176,3 +206,15 @@ func NewPkgManagerInstallFactory(runner cmdexec.Runner) Factory {
 		return tasks, nil
 	}
 }
+
+func PkgManagerInstallSchema() map[string]any {
+	return map[string]any{
+		"type":        "array",
+		"description": "List of package managers to install",
+		"items": map[string]any{
+			"type":        "string",
+			"enum":        []string{"paru", "yay", "homebrew"},
+			"description": "Package manager name",
+		},
+	}
+}
@@ -178,6 +178,43 @@ func TestPkgManagerInstall_Paru(t *testing.T) {
 	}
 }
 
+func TestPkgManagerInstall_OutputsBinaryPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		task     *PkgManagerInstall
+		wantData any
+	}{
+		{
+			name: "paru already installed",
+			task: &PkgManagerInstall{
+				Manager: "paru",
+				Runner: &cmdexec.MockRunner{
+					LookPathFunc: func(name string) (string, error) { return "/usr/bin/paru", nil },
+				},
+			},
+			wantData: "/usr/bin/paru",
+		},
+		{
+			name: "homebrew already installed",
+			task: &PkgManagerInstall{
+				Manager:    "homebrew",
+				Runner:     &cmdexec.MockRunner{},
+				PathFinder: func() (string, bool) { return "/opt/homebrew/bin/brew", true },
+			},
+			wantData: "/opt/homebrew/bin/brew",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.task.Run(context.Background())
+
+			require.Equal(t, StatusSkipped, result.Status)
+			assert.Equal(t, tt.wantData, result.Data)
+		})
+	}
+}
+
 func TestPkgManagerInstall_Name(t *testing.T) {
 	tests := []struct {
 		manager  string
@@ -446,3 +483,48 @@ func TestPkgManagerInstall_CancelledDuringClone(t *testing.T) {
 	assert.Equal(t, StatusFailed, result.Status)
 	assert.Error(t, result.Error)
 }
+
+func TestPkgManagerInstall_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		lookPath    func(string) (string, error)
+		runErr      error
+		wantChanges []string
+	}{
+		{
+			name:     "in sync when installed",
+			lookPath: func(string) (string, error) { return "/usr/bin/paru", nil },
+		},
+		{
+			name:        "drift when binary is missing",
+			lookPath:    func(string) (string, error) { return "", errors.New("not found") },
+			wantChanges: []string{"install paru"},
+		},
+		{
+			name:        "drift when package is not registered",
+			lookPath:    func(string) (string, error) { return "/usr/bin/paru", nil },
+			runErr:      errors.New("package not found"),
+			wantChanges: []string{"install paru"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &cmdexec.MockRunner{
+				LookPathFunc: tt.lookPath,
+				RunFunc: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+					return nil, tt.runErr
+				},
+			}
+			task := &PkgManagerInstall{Runner: runner, Manager: "paru"}
+
+			result := task.Check(context.Background())
+
+			require.NoError(t, result.Error)
+			assert.Equal(t, tt.wantChanges, result.Changes)
+			for _, call := range runner.Calls {
+				assert.Equal(t, "pacman", call.Name, "check must only query pacman")
+			}
+		})
+	}
+}
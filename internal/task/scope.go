@@ -0,0 +1,42 @@
+package task
+
+import (
+	"booster/internal/expr"
+	"sync"
+)
+
+type Scope struct {
+	mu  sync.RWMutex
+	ctx *expr.Context
+}
+
+func NewScope(ctx *expr.Context) *Scope {
+	return &Scope{ctx: ctx}
+}
+
+// Later results are recorded into a copy, so the snapshot can be evaluated
+// against without locking.
+func (s *Scope) Context() *expr.Context {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ctx
+}
+
+func (s *Scope) Record(id string, output any, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = s.ctx.WithTaskResult(id, output, status)
+}
+
+type loopItem struct {
+	key   any
+	value any
+}
+
+func (s *Scope) contextFor(item *loopItem) *expr.Context {
+	ctx := s.Context()
+	if item != nil {
+		ctx = ctx.WithItem(item.key, item.value)
+	}
+	return ctx
+}
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"booster/internal/backup"
 	"booster/internal/pathutil"
 	"context"
 	"fmt"
@@ -45,7 +46,7 @@ func (t *SymlinkCreate) Run(ctx context.Context) Result {
 				return Result{Status: StatusFailed, Error: err}
 			}
 			if linkDest == source {
-				return Result{Status: StatusSkipped, Message: "already exists"}
+				return Result{Status: StatusSkipped, Message: "already exists", Data: target}
 			}
 			return Result{Status: StatusFailed, Error: fmt.Errorf("symlink points to different source: %s", linkDest)}
 		}
@@ -57,11 +58,44 @@ func (t *SymlinkCreate) Run(ctx context.Context) Result {
 		return Result{Status: StatusFailed, Error: err}
 	}
 
+	if err := backup.Save(ctx, target); err != nil {
+		return Result{Status: StatusFailed, Error: fmt.Errorf("back up %s: %w", t.Target, err)}
+	}
+
 	if err := os.Symlink(source, target); err != nil {
 		return Result{Status: StatusFailed, Error: err}
 	}
 
-	return Result{Status: StatusDone, Message: "created"}
+	return Result{Status: StatusDone, Message: "created", Data: target}
+}
+
+func (t *SymlinkCreate) Check(ctx context.Context) CheckResult {
+	source, err := filepath.Abs(pathutil.Expand(t.Source))
+	if err != nil {
+		return CheckResult{Error: fmt.Errorf("failed to resolve source path: %w", err)}
+	}
+	target := pathutil.Expand(t.Target)
+
+	if _, err := os.Stat(source); err != nil {
+		return CheckResult{Error: fmt.Errorf("source does not exist: %s", source)}
+	}
+
+	info, err := os.Lstat(target)
+	if err != nil {
+		return CheckResult{Changes: []string{fmt.Sprintf("create symlink %s → %s", t.Target, source)}}
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return CheckResult{Changes: []string{fmt.Sprintf("%s exists but is not a symlink", t.Target)}}
+	}
+
+	linkDest, err := os.Readlink(target)
+	if err != nil {
+		return CheckResult{Error: err}
+	}
+	if linkDest != source {
+		return CheckResult{Changes: []string{fmt.Sprintf("%s points to %s instead of %s", t.Target, linkDest, source)}}
+	}
+	return CheckResult{}
 }
 
 func NewSymlinkCreate(args any) ([]Task, error) {
@@ -77,3 +111,8 @@ func NewSymlinkCreate(args any) ([]Task, error) {
 
 	return tasks, nil
 }
+
+func SymlinkCreateSchema() map[string]any {
+	return sourceTargetSchema("List of symlink source/target pairs",
+		"Source file path (must exist)", "Target symlink path to create")
+}
@@ -1,9 +1,12 @@
 package task
 
 import (
+	"booster/internal/backup"
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -269,3 +272,89 @@ func TestNewSymlinkCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestSymlinkCreate_Check(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	other := filepath.Join(dir, "other")
+	require.NoError(t, os.WriteFile(source, nil, 0o644))
+	require.NoError(t, os.WriteFile(other, nil, 0o644))
+
+	tests := []struct {
+		name        string
+		setup       func(target string)
+		source      string
+		wantChanges []string
+		wantErr     string
+	}{
+		{
+			name:   "in sync when link points at source",
+			setup:  func(target string) { require.NoError(t, os.Symlink(source, target)) },
+			source: source,
+		},
+		{
+			name:        "drift when link is missing",
+			setup:       func(string) {},
+			source:      source,
+			wantChanges: []string{"create symlink {target} → " + source},
+		},
+		{
+			name:        "drift when link points elsewhere",
+			setup:       func(target string) { require.NoError(t, os.Symlink(other, target)) },
+			source:      source,
+			wantChanges: []string{"{target} points to " + other + " instead of " + source},
+		},
+		{
+			name:        "drift when target is a regular file",
+			setup:       func(target string) { require.NoError(t, os.WriteFile(target, nil, 0o644)) },
+			source:      source,
+			wantChanges: []string{"{target} exists but is not a symlink"},
+		},
+		{
+			name:    "error when source is missing",
+			setup:   func(string) {},
+			source:  filepath.Join(dir, "missing"),
+			wantErr: "source does not exist",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := filepath.Join(dir, fmt.Sprintf("target%d", i))
+			tt.setup(target)
+
+			result := (&SymlinkCreate{Source: tt.source, Target: target}).Check(context.Background())
+
+			if tt.wantErr != "" {
+				require.Error(t, result.Error)
+				assert.Contains(t, result.Error.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, result.Error)
+			var want []string
+			for _, c := range tt.wantChanges {
+				want = append(want, strings.ReplaceAll(c, "{target}", target))
+			}
+			assert.Equal(t, want, result.Changes)
+		})
+	}
+}
+
+func TestSymlinkCreate_RecordsCreatedLinkForRollback(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	target := filepath.Join(dir, "target")
+	require.NoError(t, os.WriteFile(source, nil, 0o644))
+
+	b := backup.New(filepath.Join(dir, "backups"), "run1")
+	ctx := backup.WithBackup(context.Background(), b)
+
+	result := (&SymlinkCreate{Source: source, Target: target}).Run(ctx)
+
+	require.Equal(t, StatusDone, result.Status)
+	m, err := backup.Load(b.Dir())
+	require.NoError(t, err)
+	require.Len(t, m.Entries, 1)
+	assert.Equal(t, backup.KindMissing, m.Entries[0].Kind)
+	assert.Equal(t, target, m.Entries[0].Path)
+}
@@ -3,7 +3,9 @@ package task
 import (
 	"booster/internal/condition"
 	"booster/internal/config"
+	"booster/internal/expr"
 	"context"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -16,14 +18,45 @@ const (
 	StatusSkipped
 	StatusDone
 	StatusFailed
+	StatusCancelled
+	StatusTimedOut
 )
 
+func (s Status) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusRunning:
+		return "running"
+	case StatusSkipped:
+		return "skipped"
+	case StatusDone:
+		return "done"
+	case StatusFailed:
+		return "failed"
+	case StatusCancelled:
+		return "cancelled"
+	case StatusTimedOut:
+		return "timed out"
+	default:
+		return fmt.Sprintf("status(%d)", int(s))
+	}
+}
+
+func (s Status) IsFailure() bool {
+	return s == StatusFailed || s == StatusCancelled || s == StatusTimedOut
+}
+
 type Result struct {
 	Error    error
 	Message  string
 	Output   string
 	Status   Status
 	Duration time.Duration
+	Attempts int
+
+	// Data is read by later tasks as tasks.<id>.output.
+	Data any
 }
 
 type Task interface {
@@ -36,6 +69,41 @@ type Task interface {
 
 type Factory func(args any) ([]Task, error)
 
+const (
+	ResourcePackageManager = "package-manager"
+	ResourceTerminal       = "terminal"
+	ResourceGitConfig      = "git-config"
+	ResourceMise           = "mise"
+)
+
+// Tasks holding any of the same resources never run concurrently.
+type ResourceUser interface {
+	Resources() []string
+}
+
+func ResourcesOf(t Task) []string {
+	if r, ok := t.(ResourceUser); ok {
+		return r.Resources()
+	}
+	return nil
+}
+
+// A PromptUser names the values it would prompt for when it runs.
+type PromptUser interface {
+	UnsetPrompts(ctx context.Context) []string
+}
+
+// UnsetPrompts leaves out tasks that are only created when they run.
+func UnsetPrompts(ctx context.Context, tasks []Task) []string {
+	var prompts []string
+	for _, t := range tasks {
+		if p, ok := t.(PromptUser); ok {
+			prompts = append(prompts, p.UnsetPrompts(ctx)...)
+		}
+	}
+	return prompts
+}
+
 func AnyNeedsSudo(tasks []Task) bool {
 	for _, t := range tasks {
 		if t.NeedsSudo() {
@@ -45,14 +113,28 @@ func AnyNeedsSudo(tasks []Task) bool {
 	return false
 }
 
+// ActionNeeds is what the tasks of an action may need. It is declared for
+// tasks that are only created when they run, after sudo is set up and
+// resources are claimed.
+type ActionNeeds struct {
+	Sudo      bool
+	Resources []string
+}
+
 type Builder struct {
 	factories map[string]Factory
+	schemas   map[string]map[string]any
+	needs     map[string]ActionNeeds
 	evaluator *condition.Evaluator
+	scope     *Scope
 }
 
 func NewBuilder() *Builder {
 	return &Builder{
 		factories: make(map[string]Factory),
+		schemas:   make(map[string]map[string]any),
+		needs:     make(map[string]ActionNeeds),
+		scope:     NewScope(expr.NewContext()),
 	}
 }
 
@@ -61,42 +143,227 @@ func (b *Builder) Register(action string, factory Factory) *Builder {
 	return b
 }
 
+func (b *Builder) Describe(action string, args map[string]any) *Builder {
+	b.schemas[action] = args
+	return b
+}
+
+func (b *Builder) Declare(action string, needs ActionNeeds) *Builder {
+	b.needs[action] = needs
+	return b
+}
+
+// ArgSchemas maps actions that were not described to nil.
+func (b *Builder) ArgSchemas() map[string]map[string]any {
+	schemas := make(map[string]map[string]any, len(b.factories))
+	for action := range b.factories {
+		schemas[action] = b.schemas[action]
+	}
+	return schemas
+}
+
 func (b *Builder) WithEvaluator(eval *condition.Evaluator) *Builder {
 	b.evaluator = eval
 	return b
 }
 
+func (b *Builder) WithExprContext(ctx *expr.Context) *Builder {
+	b.scope = NewScope(ctx)
+	return b
+}
+
 func (b *Builder) Build(tasks []config.Task) ([]Task, error) {
-	var result []Task
+	g, err := b.BuildGraph(tasks)
+	if err != nil {
+		return nil, err
+	}
+	return g.Tasks(), nil
+}
+
+type taskExprs struct {
+	args    *expr.Value
+	when    *expr.Value
+	forEach *expr.Value
+}
 
+func (e taskExprs) taskRefs() []string {
+	refs := e.args.TaskRefs()
+	if e.when != nil {
+		refs = append(refs, e.when.TaskRefs()...)
+	}
+	return refs
+}
+
+func compileExprs(tasks []config.Task) ([]taskExprs, error) {
+	exprs := make([]taskExprs, len(tasks))
 	for i, ct := range tasks {
-		factory, ok := b.factories[ct.Action]
-		if !ok {
-			return nil, fmt.Errorf("task %d: unknown action %q", i+1, ct.Action)
+		args, err := expr.NewValue(ct.Args)
+		if err != nil {
+			return nil, taskError(i, ct, ct.Pos("args"), err)
 		}
+		exprs[i].args = args
 
-		created, err := factory(ct.Args)
+		if ct.When != nil && ct.When.Expr != "" {
+			when, err := expr.NewValue(ct.When.Expr)
+			if err != nil {
+				return nil, taskError(i, ct, ct.Pos("when"), fmt.Errorf("when: %w", err))
+			}
+			exprs[i].when = when
+		}
+
+		if ct.ForEach != nil {
+			forEach, err := expr.NewValue(ct.ForEach)
+			if err != nil {
+				return nil, taskError(i, ct, ct.Pos("for_each"), fmt.Errorf("for_each: %w", err))
+			}
+			// The number of tasks must be known before anything runs.
+			if len(forEach.TaskRefs()) > 0 {
+				return nil, taskError(i, ct, ct.Pos("for_each"), errors.New("for_each cannot read task results"))
+			}
+			exprs[i].forEach = forEach
+		}
+	}
+	return exprs, nil
+}
+
+func (b *Builder) BuildGraph(tasks []config.Task) (*Graph, error) {
+	exprs, err := compileExprs(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	deps, err := resolveDependencies(tasks, exprs)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := topoOrder(tasks, deps)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([][]Node, len(tasks))
+	for i, ct := range tasks {
+		created[i], err = b.create(i, ct, exprs[i])
 		if err != nil {
-			return nil, fmt.Errorf("task %d (%s): %w", i+1, ct.Action, err)
+			return nil, err
+		}
+	}
+
+	nodeIndices := make([][]int, len(tasks))
+	g := &Graph{scope: b.scope}
+	for _, i := range order {
+		var nodeDeps []int
+		for _, d := range deps[i] {
+			nodeDeps = append(nodeDeps, nodeIndices[d]...)
+		}
+
+		for _, n := range created[i] {
+			n.ID = tasks[i].ID
+			n.Deps = nodeDeps
+			n.IgnoreErrors = tasks[i].IgnoreErrors
+			n.Retries = tasks[i].Retries
+			n.RetryDelay = tasks[i].RetryDelay
+			n.Timeout = tasks[i].Timeout
+
+			nodeIndices[i] = append(nodeIndices[i], len(g.nodes))
+			g.nodes = append(g.nodes, n)
+		}
+	}
+
+	return g, nil
+}
+
+// Args that read task results are resolved when the task runs; all other args
+// are resolved here.
+func (b *Builder) create(i int, ct config.Task, exprs taskExprs) ([]Node, error) {
+	factory, ok := b.factories[ct.Action]
+	if !ok {
+		if ct.Origin.Line > 0 {
+			return nil, config.ErrorAt(ct.Pos("action"), fmt.Errorf("task %d: unknown action %q", ct.Origin.Index, ct.Action))
+		}
+		return nil, fmt.Errorf("%s: unknown action %q", taskRef(i, ct), ct.Action)
+	}
+
+	items, err := loopItems(exprs.forEach, b.scope.Context())
+	if err != nil {
+		return nil, taskError(i, ct, ct.Pos("for_each"), fmt.Errorf("for_each: %w", err))
+	}
+
+	var nodes []Node
+	for _, item := range items {
+		var created []Task
+		// Deferred args read results that may differ between runs, so such
+		// tasks get no fingerprint and are never skipped by --resume.
+		deferred := len(exprs.args.TaskRefs()) > 0
+		resolved := ct
+		if deferred {
+			created = []Task{&deferredTask{
+				action:  ct.Action,
+				factory: factory,
+				needs:   b.needs[ct.Action],
+				args:    exprs.args,
+				scope:   b.scope,
+				item:    item,
+			}}
+		} else {
+			resolved.Args, err = exprs.args.Resolve(b.scope.contextFor(item))
+			if err != nil {
+				return nil, taskError(i, ct, ct.Pos("args"), err)
+			}
+			created, err = factory(resolved.Args)
+			if err != nil {
+				return nil, taskError(i, ct, argPos(ct, err), err)
+			}
 		}
 
 		for _, t := range created {
-			if b.evaluator != nil && ct.When != nil {
-				cond := &condition.Condition{
-					OS:      ct.When.OS,
-					Profile: ct.When.Profile,
-				}
-				wrapped, err := NewConditionalTask(t, cond, b.evaluator)
-				if err != nil {
-					return nil, fmt.Errorf("task %d (%s): %w", i+1, ct.Action, err)
-				}
-				t = wrapped
+			var fp string
+			if !deferred {
+				fp = fingerprint(resolved, t)
 			}
-			result = append(result, t)
+			t, err = b.wrap(t, ct, exprs, item)
+			if err != nil {
+				return nil, taskError(i, ct, ct.Pos("when"), err)
+			}
+			nodes = append(nodes, Node{Task: t, Fingerprint: fp})
+		}
+	}
+
+	return nodes, nil
+}
+
+func (b *Builder) wrap(t Task, ct config.Task, exprs taskExprs, item *loopItem) (Task, error) {
+	eval := b.evaluator
+	var cond *condition.Condition
+	if eval == nil {
+		if exprs.when == nil {
+			return t, nil
+		}
+		// Without an evaluator only the expression of when is checked.
+		eval = condition.NewEvaluator(condition.Context{})
+	} else {
+		if ct.When == nil && !eval.HasTagFilter() {
+			return t, nil
+		}
+		cond = &condition.Condition{Tags: ct.Tags}
+		if ct.When != nil {
+			cond.OS = ct.When.OS
+			cond.Profile = ct.When.Profile
 		}
 	}
 
-	return result, nil
+	wrapped, err := NewConditionalTask(t, cond, eval)
+	if err != nil {
+		return nil, err
+	}
+	if exprs.when != nil {
+		wrapped.when = exprs.when
+		wrapped.whenText = ct.When.Expr
+		wrapped.scope = b.scope
+		wrapped.item = item
+	}
+	return wrapped, nil
 }
 
 func DefaultBuilder(ctx condition.Context) *Builder {
@@ -105,5 +372,7 @@ func DefaultBuilder(ctx condition.Context) *Builder {
 	return NewBuilder().
 		WithEvaluator(eval).
 		Register("dir.create", NewDirCreate).
-		Register("symlink.create", NewSymlinkCreate)
+		Describe("dir.create", DirCreateSchema()).
+		Register("symlink.create", NewSymlinkCreate).
+		Describe("symlink.create", SymlinkCreateSchema())
 }
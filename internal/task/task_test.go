@@ -3,6 +3,7 @@ package task
 import (
 	"booster/internal/condition"
 	"booster/internal/config"
+	"booster/internal/expr"
 	"context"
 	"errors"
 	"testing"
@@ -264,3 +265,254 @@ func TestBuilder_Build_NoEvaluator_NoWrapping(t *testing.T) {
 			"task should not skip due to unmet condition when no evaluator present")
 	}
 }
+
+func TestBuilder_Build_NoEvaluator_ChecksWhenExpression(t *testing.T) {
+	exprCtx := expr.NewContext().WithVars(map[string]any{"editor": "nvim"})
+	builder := NewBuilder().Register("mock", func(args any) ([]Task, error) {
+		return []Task{&mockTask{name: args.(string), result: Result{Status: StatusDone}}}, nil
+	}).WithExprContext(exprCtx)
+
+	tasks, err := builder.Build([]config.Task{
+		{Action: "mock", Args: "true", When: &config.When{Expr: "${ vars.editor == 'nvim' }"}},
+		{Action: "mock", Args: "false", When: &config.When{Expr: "${ vars.editor == 'vim' }"}},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, StatusDone, tasks[0].Run(context.Background()).Status)
+	result := tasks[1].Run(context.Background())
+	assert.Equal(t, StatusSkipped, result.Status)
+	assert.Equal(t, "condition not met: ${ vars.editor == 'vim' }", result.Message)
+}
+
+func TestBuilder_Build_FiltersByTag(t *testing.T) {
+	eval := condition.NewEvaluator(condition.Context{
+		OS:       "arch",
+		Tags:     []string{"dotfiles"},
+		SkipTags: []string{"slow"},
+	})
+	builder := NewBuilder().Register("mock", func(args any) ([]Task, error) {
+		return []Task{&mockTask{name: args.(string), result: Result{Status: StatusDone}}}, nil
+	}).WithEvaluator(eval)
+
+	tasks, err := builder.Build([]config.Task{
+		{Action: "mock", Args: "selected", Tags: config.StringOrSlice{"dotfiles"}},
+		{Action: "mock", Args: "other tag", Tags: config.StringOrSlice{"packages"}},
+		{Action: "mock", Args: "untagged"},
+		{Action: "mock", Args: "skipped tag", Tags: config.StringOrSlice{"dotfiles", "slow"}},
+		{Action: "mock", Args: "wrong os", Tags: config.StringOrSlice{"dotfiles"}, When: &config.When{OS: config.StringOrSlice{"darwin"}}},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, tasks, 5)
+
+	want := []struct {
+		status  Status
+		message string
+	}{
+		{StatusDone, ""},
+		{StatusSkipped, "filtered by tag"},
+		{StatusSkipped, "filtered by tag"},
+		{StatusSkipped, "filtered by tag"},
+		{StatusSkipped, "condition not met: os=arch, want darwin"},
+	}
+	for i, w := range want {
+		result := tasks[i].Run(context.Background())
+		assert.Equal(t, w.status, result.Status, tasks[i].Name())
+		assert.Equal(t, w.message, result.Message, tasks[i].Name())
+	}
+}
+
+func TestBuilder_Build_ResolvesExpressionsInArgs(t *testing.T) {
+	var got any
+	exprCtx := expr.NewContext().WithProfile("work").WithVars(map[string]any{"name": "luke"})
+	exprCtx.OS = "arch"
+	builder := NewBuilder().Register("capture", func(args any) ([]Task, error) {
+		got = args
+		return nil, nil
+	}).WithExprContext(exprCtx)
+
+	_, err := builder.Build([]config.Task{
+		{Action: "capture", Args: []any{
+			map[string]any{"source": "dotfiles/${ os }/gitconfig", "target": "~/.gitconfig"},
+			"~/${ vars.name }-${ profile }",
+			"${ os == \"arch\" }",
+			42,
+		}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		map[string]any{"source": "dotfiles/arch/gitconfig", "target": "~/.gitconfig"},
+		"~/luke-work",
+		true,
+		42,
+	}, got)
+}
+
+func TestBuilder_Build_InvalidExpressionInArgs(t *testing.T) {
+	builder := NewBuilder().Register("dir.create", NewDirCreate)
+
+	_, err := builder.Build([]config.Task{
+		{Action: "dir.create", Args: []any{"~/valid"}},
+		{Action: "dir.create", Args: []any{"~/ok", "~/${ 1 + }"}},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task 2 (dir.create): [1]: invalid expression")
+}
+
+func TestBuilder_BuildGraph_FingerprintUsesResolvedArgs(t *testing.T) {
+	build := func(name string) string {
+		builder := NewBuilder().
+			Register("mock", func(args any) ([]Task, error) {
+				return []Task{&mockTask{name: "same name"}}, nil
+			}).
+			WithExprContext(expr.NewContext().WithVars(map[string]any{"name": name}))
+		g, err := builder.BuildGraph([]config.Task{
+			{Action: "mock", Args: "${ vars.name }"},
+		})
+		require.NoError(t, err)
+		return g.Nodes()[0].Fingerprint
+	}
+
+	assert.NotEqual(t, build("a"), build("b"))
+}
+
+func TestBuilder_Build_WhenExpression(t *testing.T) {
+	eval := condition.NewEvaluator(condition.Context{OS: "arch"})
+	exprCtx := expr.NewContext().WithVars(map[string]any{"editor": "nvim"})
+	exprCtx.OS = "arch"
+	builder := NewBuilder().Register("mock", func(args any) ([]Task, error) {
+		return []Task{&mockTask{name: args.(string), result: Result{Status: StatusDone}}}, nil
+	}).WithEvaluator(eval).WithExprContext(exprCtx)
+
+	tasks, err := builder.Build([]config.Task{
+		{Action: "mock", Args: "true", When: &config.When{Expr: "${ vars.editor == 'nvim' }"}},
+		{Action: "mock", Args: "false", When: &config.When{Expr: "${ vars.editor == 'vim' }"}},
+		{Action: "mock", Args: "os first", When: &config.When{OS: config.StringOrSlice{"darwin"}, Expr: "${ true }"}},
+		{Action: "mock", Args: "not bool", When: &config.When{Expr: "${ vars.editor }"}},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, tasks, 4)
+
+	want := []struct {
+		status  Status
+		message string
+	}{
+		{StatusDone, ""},
+		{StatusSkipped, "condition not met: ${ vars.editor == 'vim' }"},
+		{StatusSkipped, "condition not met: os=arch, want darwin"},
+		{StatusFailed, ""},
+	}
+	for i, w := range want {
+		result := tasks[i].Run(context.Background())
+		assert.Equal(t, w.status, result.Status, tasks[i].Name())
+		assert.Equal(t, w.message, result.Message, tasks[i].Name())
+	}
+
+	failed := tasks[3].Run(context.Background())
+	require.Error(t, failed.Error)
+	assert.Contains(t, failed.Error.Error(), "evaluate when ${ vars.editor }")
+}
+
+func TestBuilder_Build_InvalidWhenExpression(t *testing.T) {
+	builder := NewBuilder().Register("dir.create", NewDirCreate)
+
+	_, err := builder.Build([]config.Task{
+		{Action: "dir.create", Args: []any{"~/ok"}, When: &config.When{Expr: "${ 1 + }"}},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task 1 (dir.create): when: invalid expression")
+}
+
+func TestResourcesOf(t *testing.T) {
+	tests := []struct {
+		name string
+		task Task
+		want []string
+	}{
+		{
+			name: "task without resources",
+			task: &DirCreate{Path: "/tmp/x"},
+			want: nil,
+		},
+		{
+			name: "package install",
+			task: &PkgInstall{},
+			want: []string{ResourcePackageManager},
+		},
+		{
+			name: "package manager install",
+			task: &PkgManagerInstall{},
+			want: []string{ResourcePackageManager},
+		},
+		{
+			name: "mise use",
+			task: &MiseUse{},
+			want: []string{ResourceMise},
+		},
+		{
+			name: "git config without prompts",
+			task: &GitConfig{Items: []GitConfigItem{{Key: "user.name", Value: "x"}}},
+			want: []string{ResourceGitConfig},
+		},
+		{
+			name: "git config with prompt holds the terminal",
+			task: &GitConfig{Items: []GitConfigItem{{Key: "user.email", Prompt: "Email"}}},
+			want: []string{ResourceGitConfig, ResourceTerminal},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ResourcesOf(tt.task))
+		})
+	}
+}
+
+func TestStatus_String(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   string
+	}{
+		{StatusPending, "pending"},
+		{StatusRunning, "running"},
+		{StatusSkipped, "skipped"},
+		{StatusDone, "done"},
+		{StatusFailed, "failed"},
+		{StatusCancelled, "cancelled"},
+		{StatusTimedOut, "timed out"},
+		{Status(42), "status(42)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.status.String())
+		})
+	}
+}
+
+func TestStatus_IsFailure(t *testing.T) {
+	assert.True(t, StatusFailed.IsFailure())
+	assert.True(t, StatusCancelled.IsFailure())
+	assert.True(t, StatusTimedOut.IsFailure())
+	assert.False(t, StatusDone.IsFailure())
+	assert.False(t, StatusSkipped.IsFailure())
+	assert.False(t, StatusPending.IsFailure())
+}
+
+func TestBuilder_ArgSchemas(t *testing.T) {
+	args := map[string]any{"type": "array"}
+	builder := NewBuilder().
+		Register("described", NewDirCreate).
+		Describe("described", args).
+		Register("undescribed", NewDirCreate)
+
+	assert.Equal(t, map[string]map[string]any{
+		"described":   args,
+		"undescribed": nil,
+	}, builder.ArgSchemas())
+}
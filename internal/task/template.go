@@ -1,6 +1,7 @@
 package task
 
 import (
+	"booster/internal/backup"
 	"booster/internal/pathutil"
 	"bytes"
 	"context"
@@ -17,7 +18,7 @@ type TemplateSystem struct {
 }
 
 type TemplateContext struct {
-	Vars map[string]string
+	Vars map[string]any
 
 	System TemplateSystem
 }
@@ -32,48 +33,95 @@ func (t *TemplateRender) Name() string {
 	return fmt.Sprintf("render %s → %s", filepath.Base(t.Source), filepath.Base(t.Target))
 }
 
+func (t *TemplateRender) Fingerprint() string {
+	content, err := os.ReadFile(pathutil.Expand(t.Source))
+	if err != nil {
+		return "unreadable: " + err.Error()
+	}
+	return fmt.Sprintf("%s\x00%v", content, t.Context)
+}
+
 func (t *TemplateRender) NeedsSudo() bool {
 	return false
 }
 
 func (t *TemplateRender) Run(ctx context.Context) Result {
-	source := pathutil.Expand(t.Source)
 	target := pathutil.Expand(t.Target)
 
-	tmplContent, err := os.ReadFile(source)
+	rendered, err := t.render()
 	if err != nil {
-		return Result{Status: StatusFailed, Error: fmt.Errorf("read template: %w", err)}
-	}
-
-	tmpl, err := template.New(filepath.Base(source)).Parse(string(tmplContent))
-	if err != nil {
-		return Result{Status: StatusFailed, Error: fmt.Errorf("parse template: %w", err)}
-	}
-
-	var buf bytes.Buffer
-	if execErr := tmpl.Execute(&buf, t.Context); execErr != nil {
-		return Result{Status: StatusFailed, Error: fmt.Errorf("execute template: %w", execErr)}
+		return Result{Status: StatusFailed, Error: err}
 	}
-	rendered := buf.Bytes()
 
 	existing, err := os.ReadFile(target)
 	if err == nil && bytes.Equal(existing, rendered) {
-		return Result{Status: StatusSkipped, Message: "already up to date"}
+		return Result{Status: StatusSkipped, Message: "already up to date", Data: target}
 	}
 
 	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
 		return Result{Status: StatusFailed, Error: fmt.Errorf("create directories: %w", err)}
 	}
 
+	if err := backup.Save(ctx, target); err != nil {
+		return Result{Status: StatusFailed, Error: fmt.Errorf("back up %s: %w", t.Target, err)}
+	}
+
 	if err := os.WriteFile(target, rendered, 0o644); err != nil {
 		return Result{Status: StatusFailed, Error: fmt.Errorf("write output: %w", err)}
 	}
 
-	return Result{Status: StatusDone, Message: "rendered"}
+	return Result{Status: StatusDone, Message: "rendered", Data: target}
+}
+
+func (t *TemplateRender) Check(ctx context.Context) CheckResult {
+	rendered, err := t.render()
+	if err != nil {
+		return CheckResult{Error: err}
+	}
+
+	existing, err := os.ReadFile(pathutil.Expand(t.Target))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return CheckResult{Changes: []string{"create " + t.Target}}
+		}
+		return CheckResult{Error: fmt.Errorf("read output: %w", err)}
+	}
+	if !bytes.Equal(existing, rendered) {
+		return CheckResult{Changes: []string{"update " + t.Target}}
+	}
+	return CheckResult{}
+}
+
+func (t *TemplateRender) Diff() (string, error) {
+	rendered, err := t.render()
+	if err != nil {
+		return "", err
+	}
+	return unifiedDiff(t.Target, pathutil.Expand(t.Target), rendered)
+}
+
+func (t *TemplateRender) render() ([]byte, error) {
+	source := pathutil.Expand(t.Source)
+
+	tmplContent, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(source)).Parse(string(tmplContent))
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, t.Context); err != nil {
+		return nil, fmt.Errorf("execute template: %w", err)
+	}
+	return buf.Bytes(), nil
 }
 
 type TemplateRenderConfig struct {
-	Vars    map[string]string
+	Vars    map[string]any
 	OS      string
 	Profile string
 }
@@ -105,3 +153,8 @@ func NewTemplateRenderFactory(cfg TemplateRenderConfig) Factory {
 		return tasks, nil
 	}
 }
+
+func TemplateRenderSchema() map[string]any {
+	return sourceTargetSchema("List of template source/target pairs",
+		"Template source file path (.tmpl)", "Rendered output file path")
+}
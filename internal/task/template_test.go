@@ -1,9 +1,12 @@
 package task
 
 import (
+	"booster/internal/backup"
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,7 +24,7 @@ func TestTemplateRender_RendersTemplate(t *testing.T) {
 		Source: source,
 		Target: target,
 		Context: TemplateContext{
-			Vars: map[string]string{"Name": "World"},
+			Vars: map[string]any{"Name": "World"},
 		},
 	}
 	result := task.Run(context.Background())
@@ -46,7 +49,7 @@ func TestTemplateRender_SkipsWhenUpToDate(t *testing.T) {
 		Source: source,
 		Target: target,
 		Context: TemplateContext{
-			Vars: map[string]string{"Name": "World"},
+			Vars: map[string]any{"Name": "World"},
 		},
 	}
 	result := task.Run(context.Background())
@@ -67,7 +70,7 @@ func TestTemplateRender_UpdatesWhenChanged(t *testing.T) {
 		Source: source,
 		Target: target,
 		Context: TemplateContext{
-			Vars: map[string]string{"Name": "NewValue"},
+			Vars: map[string]any{"Name": "NewValue"},
 		},
 	}
 	result := task.Run(context.Background())
@@ -90,7 +93,7 @@ func TestTemplateRender_CreatesParentDirectories(t *testing.T) {
 		Source: source,
 		Target: target,
 		Context: TemplateContext{
-			Vars: map[string]string{"Value": "test"},
+			Vars: map[string]any{"Value": "test"},
 		},
 	}
 	result := task.Run(context.Background())
@@ -113,7 +116,7 @@ func TestTemplateRender_FailsOnInvalidTemplate(t *testing.T) {
 		Source: source,
 		Target: target,
 		Context: TemplateContext{
-			Vars: map[string]string{"Name": "World"},
+			Vars: map[string]any{"Name": "World"},
 		},
 	}
 	result := task.Run(context.Background())
@@ -149,7 +152,7 @@ func TestTemplateRender_Idempotency(t *testing.T) {
 		Source: source,
 		Target: target,
 		Context: TemplateContext{
-			Vars: map[string]string{"X": "42"},
+			Vars: map[string]any{"X": "42"},
 		},
 	}
 
@@ -191,7 +194,7 @@ func TestTemplateRender_MultipleVariables(t *testing.T) {
 		Source: source,
 		Target: target,
 		Context: TemplateContext{
-			Vars: map[string]string{
+			Vars: map[string]any{
 				"Name":   "Alice",
 				"Email":  "alice@example.com",
 				"Editor": "vim",
@@ -219,7 +222,7 @@ func TestTemplateRender_MissingVariable(t *testing.T) {
 	task := &TemplateRender{
 		Source:  source,
 		Target:  target,
-		Context: TemplateContext{Vars: map[string]string{}},
+		Context: TemplateContext{Vars: map[string]any{}},
 	}
 	result := task.Run(context.Background())
 
@@ -238,7 +241,7 @@ func TestNewTemplateRenderFactory_ValidArgs(t *testing.T) {
 	require.NoError(t, os.WriteFile(source, []byte(tmplContent), 0o644))
 
 	cfg := TemplateRenderConfig{
-		Vars:    map[string]string{"Name": "Test"},
+		Vars:    map[string]any{"Name": "Test"},
 		OS:      "arch",
 		Profile: "work",
 	}
@@ -268,7 +271,7 @@ func TestNewTemplateRenderFactory_ValidArgs(t *testing.T) {
 }
 
 func TestNewTemplateRenderFactory_MultipleTemplates(t *testing.T) {
-	cfg := TemplateRenderConfig{Vars: map[string]string{}}
+	cfg := TemplateRenderConfig{Vars: map[string]any{}}
 	factory := NewTemplateRenderFactory(cfg)
 
 	args := []any{
@@ -282,7 +285,7 @@ func TestNewTemplateRenderFactory_MultipleTemplates(t *testing.T) {
 }
 
 func TestNewTemplateRenderFactory_InvalidArgs(t *testing.T) {
-	cfg := TemplateRenderConfig{Vars: map[string]string{}}
+	cfg := TemplateRenderConfig{Vars: map[string]any{}}
 	factory := NewTemplateRenderFactory(cfg)
 
 	tests := []struct {
@@ -332,7 +335,7 @@ func TestNewTemplateRenderFactory_InvalidArgs(t *testing.T) {
 }
 
 func TestNewTemplateRenderFactory_ErrorIndices(t *testing.T) {
-	cfg := TemplateRenderConfig{Vars: map[string]string{}}
+	cfg := TemplateRenderConfig{Vars: map[string]any{}}
 	factory := NewTemplateRenderFactory(cfg)
 
 	tests := []struct {
@@ -436,7 +439,7 @@ email = {{.Vars.Email}}`
 		Source: source,
 		Target: target,
 		Context: TemplateContext{
-			Vars: map[string]string{
+			Vars: map[string]any{
 				"Username": "alice",
 				"Email":    "alice@example.com",
 			},
@@ -456,3 +459,133 @@ email = {{.Vars.Email}}`
 	assert.Contains(t, string(content), "user = alice")
 	assert.Contains(t, string(content), "email = alice@example.com")
 }
+
+func TestTemplateRender_Check(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config.tmpl")
+	require.NoError(t, os.WriteFile(source, []byte("Hello {{.Vars.Name}}!"), 0o644))
+
+	tests := []struct {
+		name        string
+		existing    string
+		wantChanges []string
+	}{
+		{name: "in sync when output matches", existing: "Hello World!"},
+		{name: "drift when output differs", existing: "Hello Moon!", wantChanges: []string{"update {target}"}},
+		{name: "drift when output is missing", wantChanges: []string{"create {target}"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := filepath.Join(dir, fmt.Sprintf("config%d", i))
+			if tt.existing != "" {
+				require.NoError(t, os.WriteFile(target, []byte(tt.existing), 0o644))
+			}
+			task := &TemplateRender{
+				Source:  source,
+				Target:  target,
+				Context: TemplateContext{Vars: map[string]any{"Name": "World"}},
+			}
+
+			result := task.Check(context.Background())
+
+			require.NoError(t, result.Error)
+			var want []string
+			for _, c := range tt.wantChanges {
+				want = append(want, strings.ReplaceAll(c, "{target}", target))
+			}
+			assert.Equal(t, want, result.Changes)
+			if tt.existing != "" {
+				content, err := os.ReadFile(target)
+				require.NoError(t, err)
+				assert.Equal(t, tt.existing, string(content), "check must not write the output")
+			}
+		})
+	}
+}
+
+func TestTemplateRender_Check_InvalidTemplate(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config.tmpl")
+	require.NoError(t, os.WriteFile(source, []byte("{{.Broken"), 0o644))
+
+	result := (&TemplateRender{Source: source, Target: filepath.Join(dir, "out")}).Check(context.Background())
+
+	require.Error(t, result.Error)
+	assert.Contains(t, result.Error.Error(), "parse template")
+}
+
+func TestTemplateRender_Diff(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "gitconfig.tmpl")
+	target := filepath.Join(dir, "gitconfig")
+	require.NoError(t, os.WriteFile(source, []byte("[user]\n\tname = {{.Vars.Name}}\n"), 0o644))
+	require.NoError(t, os.WriteFile(target, []byte("[user]\n\tname = Old\n[alias]\n\tco = checkout\n"), 0o644))
+
+	task := &TemplateRender{
+		Source:  source,
+		Target:  target,
+		Context: TemplateContext{Vars: map[string]any{"Name": "New"}},
+	}
+
+	diff, err := task.Diff()
+
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- "+target)
+	assert.Contains(t, diff, "-\tname = Old")
+	assert.Contains(t, diff, "+\tname = New")
+	assert.Contains(t, diff, "-\tco = checkout", "hand edits that would be lost show up as removals")
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "co = checkout", "diff must not write the target")
+}
+
+func TestTemplateRender_BacksUpOverwrittenTarget(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config.tmpl")
+	target := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(source, []byte("new"), 0o644))
+	require.NoError(t, os.WriteFile(target, []byte("hand edited"), 0o644))
+
+	b := backup.New(filepath.Join(dir, "backups"), "run1")
+	ctx := backup.WithBackup(context.Background(), b)
+
+	result := (&TemplateRender{Source: source, Target: target}).Run(ctx)
+
+	require.Equal(t, StatusDone, result.Status)
+	m, err := backup.Load(b.Dir())
+	require.NoError(t, err)
+	require.Len(t, m.Entries, 1)
+	assert.Equal(t, target, m.Entries[0].Path)
+	saved, err := os.ReadFile(filepath.Join(b.Dir(), m.Entries[0].File))
+	require.NoError(t, err)
+	assert.Equal(t, "hand edited", string(saved))
+}
+
+func TestTemplateRender_TypedVariables(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config.tmpl")
+	target := filepath.Join(dir, "config")
+
+	tmpl := `{{ if .Vars.Debug }}debug = true
+{{ end }}workers = {{ .Vars.Workers }}{{ if gt .Vars.Workers 2 }} # many{{ end }}
+{{ range .Vars.Langs }}lang = {{ . }}
+{{ end }}`
+	require.NoError(t, os.WriteFile(source, []byte(tmpl), 0o644))
+
+	task := &TemplateRender{
+		Source: source,
+		Target: target,
+		Context: TemplateContext{
+			Vars: map[string]any{"Debug": true, "Workers": 4, "Langs": []any{"go", "zig"}},
+		},
+	}
+
+	result := task.Run(context.Background())
+	require.Equal(t, StatusDone, result.Status, result.Error)
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "debug = true\nworkers = 4 # many\nlang = go\nlang = zig\n", string(content))
+}
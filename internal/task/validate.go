@@ -0,0 +1,159 @@
+package task
+
+import (
+	"booster/internal/config"
+	"booster/internal/pathutil"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Validator is implemented by tasks that can tell without running that they
+// cannot work as configured.
+type Validator interface {
+	Validate() error
+}
+
+func Validate(t Task) error {
+	if v, ok := t.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// Validate keeps going after a task fails to build. Errors in the graph
+// itself, such as cycles, end validation early.
+func (b *Builder) Validate(tasks []config.Task) []error {
+	exprs, err := compileExprs(tasks)
+	if err != nil {
+		return []error{err}
+	}
+
+	deps, err := resolveDependencies(tasks, exprs)
+	if err != nil {
+		return []error{err}
+	}
+	if _, err := topoOrder(tasks, deps); err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for i, ct := range tasks {
+		nodes, err := b.create(i, ct, exprs[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, n := range nodes {
+			if err := Validate(n.Task); err != nil {
+				errs = append(errs, taskError(i, ct, argPos(ct, err), err))
+			}
+		}
+	}
+	return errs
+}
+
+func (t *ConditionalTask) Validate() error {
+	return Validate(t.wrapped)
+}
+
+func (t *SymlinkCreate) Validate() error {
+	if _, err := os.Stat(pathutil.Expand(t.Source)); err != nil {
+		return fmt.Errorf("source does not exist: %s", t.Source)
+	}
+	return nil
+}
+
+func (t *TemplateRender) Validate() error {
+	source := pathutil.Expand(t.Source)
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("source does not exist: %s", t.Source)
+		}
+		return fmt.Errorf("read template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(source)).Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("parse template: %w", err)
+	}
+
+	for _, tree := range tmpl.Templates() {
+		var undefined error
+		walkTemplate(tree.Root, func(node parse.Node, ident []string) {
+			if undefined != nil || len(ident) < 2 || ident[0] != "Vars" {
+				return
+			}
+			if _, ok := t.Context.Vars[ident[1]]; ok {
+				return
+			}
+			line, col := templatePos(string(content), int(node.Position()))
+			pos := config.Position{Path: source, File: t.Source, Line: line, Column: col}
+			undefined = config.ErrorAt(pos, fmt.Errorf("undefined variable %q", ident[1]))
+		})
+		if undefined != nil {
+			return undefined
+		}
+	}
+	return nil
+}
+
+// A leading $ is dropped so that $.Vars.X and .Vars.X read the same.
+func walkTemplate(node parse.Node, fn func(node parse.Node, ident []string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkTemplate(c, fn)
+		}
+	case *parse.ActionNode:
+		walkTemplate(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkTemplate(c, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkTemplate(arg, fn)
+		}
+	case *parse.FieldNode:
+		fn(n, n.Ident)
+	case *parse.VariableNode:
+		if len(n.Ident) > 0 && n.Ident[0] == "$" {
+			fn(n, n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		walkTemplate(n.Node, fn)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkTemplate(n.Pipe, fn)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, fn func(node parse.Node, ident []string)) {
+	walkTemplate(n.Pipe, fn)
+	walkTemplate(n.List, fn)
+	walkTemplate(n.ElseList, fn)
+}
+
+func templatePos(text string, offset int) (line, col int) {
+	before := text[:offset]
+	line = 1 + strings.Count(before, "\n")
+	col = offset - strings.LastIndex(before, "\n")
+	return line, col
+}
@@ -0,0 +1,87 @@
+package task
+
+import (
+	"booster/internal/condition"
+	"booster/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymlinkCreate_Validate(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	require.NoError(t, os.WriteFile(source, nil, 0o644))
+
+	assert.NoError(t, (&SymlinkCreate{Source: source, Target: "t"}).Validate())
+	assert.EqualError(t, (&SymlinkCreate{Source: "missing", Target: "t"}).Validate(), "source does not exist: missing")
+}
+
+func TestTemplateRender_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string
+	}{
+		{name: "defined variables", template: "{{ .Vars.Name }} {{ if .Vars.Email }}{{ $.Vars.Email }}{{ end }}"},
+		{name: "system fields", template: "{{ .System.OS }}"},
+		{name: "undefined variable", template: "a\nb {{ .Vars.Missing }}", wantErr: `:2:11: undefined variable "Missing"`},
+		{name: "undefined variable in a branch", template: "{{ range .Vars.Name }}{{ $.Vars.Other }}{{ end }}", wantErr: `undefined variable "Other"`},
+		{name: "parse error", template: "{{ .Vars.Name ", wantErr: "parse template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := filepath.Join(t.TempDir(), "file.tmpl")
+			require.NoError(t, os.WriteFile(source, []byte(tt.template), 0o644))
+
+			task := &TemplateRender{
+				Source:  source,
+				Target:  "out",
+				Context: TemplateContext{Vars: map[string]any{"Name": "", "Email": ""}},
+			}
+			err := task.Validate()
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestTemplateRender_ValidateMissingSource(t *testing.T) {
+	err := (&TemplateRender{Source: "missing.tmpl"}).Validate()
+
+	assert.EqualError(t, err, "source does not exist: missing.tmpl")
+}
+
+func TestBuilder_Validate_ReportsEveryTask(t *testing.T) {
+	builder := DefaultBuilder(condition.Context{OS: "linux"})
+
+	errs := builder.Validate([]config.Task{
+		{Action: "dir.create", Args: "not a list"},
+		{Action: "dir.create", Args: []any{"ok"}},
+		{Action: "symlink.create", Args: []any{map[string]any{"source": "missing", "target": "t"}}, When: &config.When{OS: []string{"darwin"}}},
+		{Action: "unknown"},
+	})
+
+	require.Len(t, errs, 3)
+	assert.ErrorContains(t, errs[0], "task 1 (dir.create): args must be a list of paths")
+	assert.ErrorContains(t, errs[1], "task 3 (symlink.create): source does not exist: missing")
+	assert.ErrorContains(t, errs[2], `task 4: unknown action "unknown"`)
+}
+
+func TestBuilder_Validate_StopsAtGraphErrors(t *testing.T) {
+	errs := NewBuilder().Validate([]config.Task{
+		{Action: "dir.create", Args: []any{"a"}, DependsOn: config.StringOrSlice{"missing"}},
+		{Action: "unknown"},
+	})
+
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], `depends_on references unknown task "missing"`)
+}
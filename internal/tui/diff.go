@@ -0,0 +1,50 @@
+package tui
+
+import "strings"
+
+// renderLogLines colours the lines of unified diffs written by tasks run with
+// --diff.
+func renderLogLines(lines []string) string {
+	var s strings.Builder
+	inDiff := false
+	for i, line := range lines {
+		if i > 0 {
+			s.WriteString("\n")
+		}
+
+		if strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ") {
+			inDiff = true
+		} else if inDiff && !isDiffLine(line) {
+			inDiff = false
+		}
+
+		if !inDiff {
+			s.WriteString(line)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			s.WriteString(diffHeaderStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			s.WriteString(diffHunkStyle.Render(line))
+		case strings.HasPrefix(line, "+"):
+			s.WriteString(diffAddStyle.Render(line))
+		case strings.HasPrefix(line, "-"):
+			s.WriteString(diffRemoveStyle.Render(line))
+		default:
+			s.WriteString(line)
+		}
+	}
+	return s.String()
+}
+
+func isDiffLine(line string) bool {
+	if line == "" {
+		return false
+	}
+	switch line[0] {
+	case ' ', '+', '-', '@', '\\':
+		return true
+	}
+	return false
+}
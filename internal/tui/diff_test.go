@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderLogLines_KeepsText(t *testing.T) {
+	lines := []string{
+		"rendering gitconfig",
+		"--- ~/.gitconfig",
+		"+++ ~/.gitconfig",
+		"@@ -1,2 +1,2 @@",
+		" [user]",
+		"-  name = Old",
+		"+  name = New",
+		"done",
+		"-- not a diff line",
+	}
+
+	assert.Equal(t, strings.Join(lines, "\n"), renderLogLines(lines))
+}
+
+func TestIsDiffLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{" context", true},
+		{"+added", true},
+		{"-removed", true},
+		{"@@ -1 +1 @@", true},
+		{`\ No newline at end of file`, true},
+		{"", false},
+		{"installing neovim", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			assert.Equal(t, tt.want, isDiffLine(tt.line))
+		})
+	}
+}
@@ -15,6 +15,14 @@ type FailureInfo struct {
 	Error    error
 	Output   string
 	Duration time.Duration
+	Attempts int
+}
+
+func (f FailureInfo) title() string {
+	if f.Attempts > 1 {
+		return fmt.Sprintf("✗ %s (%d attempts)", f.TaskName, f.Attempts)
+	}
+	return "✗ " + f.TaskName
 }
 
 func RenderFailure(info FailureInfo, width int) string {
@@ -30,7 +38,7 @@ func RenderFailure(info FailureInfo, width int) string {
 	content.WriteString(header)
 	content.WriteString("\n")
 
-	taskLine := "✗ " + info.TaskName
+	taskLine := info.title()
 	content.WriteString(failureTaskStyle.Render(truncateLine(taskLine, innerWidth)))
 	content.WriteString("\n\n")
 
@@ -127,7 +135,7 @@ func RenderFailureSummary(failures []FailureInfo, width int) string {
 	content.WriteString("\n\n")
 
 	for i, failure := range failures {
-		taskLine := "✗ " + failure.TaskName
+		taskLine := failure.title()
 		content.WriteString(failureTaskStyle.Render(taskLine))
 		content.WriteString("\n")
 
@@ -344,6 +344,21 @@ func TestRenderFailureSummary(t *testing.T) {
 				"not found",
 			},
 		},
+		{
+			name: "failure after retries",
+			failures: []FailureInfo{
+				{
+					TaskName: "Install package",
+					Error:    errors.New("timeout"),
+					Attempts: 3,
+				},
+			},
+			width: 60,
+			wantText: []string{
+				"✗ Install package (3 attempts)",
+				"timeout",
+			},
+		},
 		{
 			name: "multiple failures",
 			failures: []FailureInfo{
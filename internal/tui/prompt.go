@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 )
@@ -24,29 +25,15 @@ func (p *PromptCollector) WithInput(r io.Reader) *PromptCollector {
 	return p
 }
 
-func (p *PromptCollector) Collect(defs []variable.Definition) (map[string]string, error) {
+func (p *PromptCollector) Collect(defs []variable.Definition) (map[string]any, error) {
 	if len(defs) == 0 {
-		return make(map[string]string), nil
+		return make(map[string]any), nil
 	}
 
-	values := make([]string, len(defs))
+	fields := make([]huh.Field, len(defs))
+	values := make([]func() any, len(defs))
 	for i, def := range defs {
-		values[i] = def.Default
-	}
-
-	var fields []huh.Field
-	for i, def := range defs {
-		prompt := def.Prompt
-		if prompt == "" {
-			prompt = "Enter " + def.Name
-		}
-
-		input := huh.NewInput().
-			Title(prompt).
-			Value(&values[i]).
-			Placeholder(def.Default)
-
-		fields = append(fields, input)
+		fields[i], values[i] = promptField(def)
 	}
 
 	form := huh.NewForm(
@@ -64,14 +51,89 @@ func (p *PromptCollector) Collect(defs []variable.Definition) (map[string]string
 		return nil, fmt.Errorf("prompt cancelled: %w", err)
 	}
 
-	result := make(map[string]string)
+	result := make(map[string]any)
 	for i, def := range defs {
-		result[def.Name] = values[i]
+		result[def.Name] = values[i]()
 	}
 
 	return result, nil
 }
 
+func promptField(def variable.Definition) (huh.Field, func() any) {
+	prompt := def.Prompt
+	if prompt == "" {
+		prompt = "Enter " + def.Name
+	}
+	// The resolver has checked the definition, so the default is valid.
+	defaultValue, _ := def.DefaultValue()
+
+	switch def.Kind() {
+	case variable.TypeBool:
+		value, _ := defaultValue.(bool)
+		return huh.NewConfirm().
+			Title(prompt).
+			Value(&value), func() any { return value }
+	case variable.TypeChoice:
+		value, _ := defaultValue.(string)
+		return huh.NewSelect[string]().
+			Title(prompt).
+			Options(huh.NewOptions(def.Options...)...).
+			Value(&value), func() any { return value }
+	case variable.TypeList:
+		var value []string
+		if items, ok := defaultValue.([]any); ok {
+			for _, item := range items {
+				value = append(value, fmt.Sprint(item))
+			}
+		}
+		if len(def.Options) > 0 {
+			return huh.NewMultiSelect[string]().
+				Title(prompt).
+				Options(huh.NewOptions(def.Options...)...).
+				Value(&value).
+				Validate(func(items []string) error {
+					_, err := def.Convert(items)
+					return err
+				}), func() any { return listValue(value) }
+		}
+		text := strings.Join(value, ", ")
+		return textField(def, prompt, &text), func() any { return text }
+	}
+
+	var text string
+	if def.Default != nil {
+		text = fmt.Sprint(defaultValue)
+	}
+	return textField(def, prompt, &text), func() any { return text }
+}
+
+// Leaving a text field empty keeps the default. Secrets are masked and their
+// default is not shown.
+func textField(def variable.Definition, prompt string, value *string) huh.Field {
+	input := huh.NewInput().
+		Title(prompt).
+		Value(value).
+		Validate(func(s string) error {
+			if s == "" && def.Default != nil {
+				return nil
+			}
+			_, err := def.Convert(s)
+			return err
+		})
+	if def.Secret {
+		return input.EchoMode(huh.EchoModePassword)
+	}
+	return input.Placeholder(*value)
+}
+
+func listValue(items []string) []any {
+	list := make([]any, len(items))
+	for i, item := range items {
+		list[i] = item
+	}
+	return list
+}
+
 type HuhPrompter struct {
 	input      io.Reader
 	accessible bool
@@ -88,11 +150,16 @@ func (p *HuhPrompter) WithInput(r io.Reader) *HuhPrompter {
 }
 
 func (p *HuhPrompter) Prompt(ctx context.Context, promptText string) (string, error) {
-	var value string
+	return p.prompt(huh.NewInput().Title(promptText))
+}
 
-	input := huh.NewInput().
-		Title(promptText).
-		Value(&value)
+func (p *HuhPrompter) PromptSecret(ctx context.Context, promptText string) (string, error) {
+	return p.prompt(huh.NewInput().Title(promptText).EchoMode(huh.EchoModePassword))
+}
+
+func (p *HuhPrompter) prompt(input *huh.Input) (string, error) {
+	var value string
+	input.Value(&value)
 
 	form := huh.NewForm(
 		huh.NewGroup(input),
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"booster/internal/variable"
+	"io"
 	"strings"
 	"testing"
 
@@ -65,3 +66,75 @@ func TestPromptCollector_UsesDefaultWhenEmpty(t *testing.T) {
 
 	assert.Equal(t, "DefaultName", result["Name"])
 }
+
+func TestPromptCollector_TypedFields(t *testing.T) {
+	// Confirm: y. Select: option 2. Multi-select: toggle 1 and 3, then 0 to
+	// finish. Int input: 8.
+	input := &lineReader{lines: []string{"y", "2", "1", "3", "0", "8"}}
+
+	collector := NewPromptCollector().WithInput(input)
+
+	defs := []variable.Definition{
+		{Name: "Debug", Type: variable.TypeBool},
+		{Name: "Shell", Type: variable.TypeChoice, Options: []string{"zsh", "fish"}},
+		{Name: "Langs", Type: variable.TypeList, Options: []string{"go", "rust", "zig"}},
+		{Name: "Workers", Type: variable.TypeInt},
+	}
+
+	result, err := collector.Collect(defs)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"Debug":   true,
+		"Shell":   "fish",
+		"Langs":   []any{"go", "zig"},
+		"Workers": "8",
+	}, result)
+}
+
+func TestPromptCollector_StartsAtTypedDefaults(t *testing.T) {
+	input := strings.NewReader("\n\n")
+
+	collector := NewPromptCollector().WithInput(input)
+
+	defs := []variable.Definition{
+		{Name: "Debug", Type: variable.TypeBool, Default: true},
+		{Name: "Shell", Type: variable.TypeChoice, Options: []string{"zsh", "fish"}, Default: "fish"},
+	}
+
+	result, err := collector.Collect(defs)
+
+	require.NoError(t, err)
+	assert.Equal(t, true, result["Debug"])
+	assert.Equal(t, "fish", result["Shell"])
+}
+
+func TestPromptCollector_RejectsInvalidInput(t *testing.T) {
+	input := &lineReader{lines: []string{"lots", "12"}}
+
+	collector := NewPromptCollector().WithInput(input)
+
+	defs := []variable.Definition{
+		{Name: "Workers", Type: variable.TypeInt},
+	}
+
+	result, err := collector.Collect(defs)
+
+	require.NoError(t, err)
+	assert.Equal(t, "12", result["Workers"])
+}
+
+// lineReader returns one line per read. Accessible fields each read through
+// their own buffer, which would otherwise take the input of later fields.
+type lineReader struct {
+	lines []string
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	if len(r.lines) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.lines[0]+"\n")
+	r.lines = r.lines[1:]
+	return n, nil
+}
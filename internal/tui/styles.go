@@ -66,6 +66,18 @@ var (
 			Foreground(gray).
 			PaddingLeft(2)
 
+	diffHeaderStyle = lipgloss.NewStyle().
+			Bold(true)
+
+	diffHunkStyle = lipgloss.NewStyle().
+			Foreground(cyan)
+
+	diffAddStyle = lipgloss.NewStyle().
+			Foreground(green)
+
+	diffRemoveStyle = lipgloss.NewStyle().
+			Foreground(red)
+
 	progressFilledStyle = lipgloss.NewStyle().
 				Foreground(cyan)
 
@@ -18,6 +18,7 @@ type SummaryData struct {
 	Elapsed time.Duration
 
 	SlowestTasks []TaskTiming
+	RetriedTasks []TaskAttempts
 }
 
 type TaskTiming struct {
@@ -25,6 +26,12 @@ type TaskTiming struct {
 	Duration time.Duration
 }
 
+type TaskAttempts struct {
+	Name     string
+	Attempts int
+	Failed   bool
+}
+
 func RenderSummary(data SummaryData, width int) string {
 	var b strings.Builder
 
@@ -39,6 +46,11 @@ func RenderSummary(data SummaryData, width int) string {
 		b.WriteString(renderSlowestTasks(data.SlowestTasks))
 	}
 
+	if len(data.RetriedTasks) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(renderRetriedTasks(data.RetriedTasks))
+	}
+
 	return b.String()
 }
 
@@ -56,6 +68,11 @@ func RenderFailedSummary(data SummaryData, width int) string {
 		b.WriteString(renderSlowestTasks(data.SlowestTasks))
 	}
 
+	if len(data.RetriedTasks) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(renderRetriedTasks(data.RetriedTasks))
+	}
+
 	return b.String()
 }
 
@@ -157,6 +174,30 @@ func renderSlowestTasks(tasks []TaskTiming) string {
 	return b.String()
 }
 
+func renderRetriedTasks(tasks []TaskAttempts) string {
+	var b strings.Builder
+
+	b.WriteString(summaryStatStyle.Render("  Retried Tasks"))
+	b.WriteString("\n")
+	b.WriteString(summaryStatStyle.Render("  " + strings.Repeat("─", 41)))
+	b.WriteString("\n")
+
+	for i, task := range tasks {
+		style := doneStyle
+		if task.Failed {
+			style = failedStyle
+		}
+		b.WriteString(fmt.Sprintf("     %s   %s",
+			style.Render(fmt.Sprintf("%2d attempts", task.Attempts)),
+			summaryStatStyle.Render(task.Name)))
+		if i < len(tasks)-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Second {
 		return "0s"
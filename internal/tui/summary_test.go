@@ -329,6 +329,33 @@ func TestRenderSlowestTasks(t *testing.T) {
 	assert.Contains(t, result, "23", "Should show second task duration")
 }
 
+func TestRenderRetriedTasks(t *testing.T) {
+	tasks := []TaskAttempts{
+		{Name: "pkg: yay", Attempts: 2},
+		{Name: "mise: node@22", Attempts: 4, Failed: true},
+	}
+
+	result := renderRetriedTasks(tasks)
+
+	assert.Contains(t, result, "Retried Tasks")
+	assert.Contains(t, result, "2 attempts")
+	assert.Contains(t, result, "pkg: yay")
+	assert.Contains(t, result, "4 attempts")
+	assert.Contains(t, result, "mise: node@22")
+}
+
+func TestRenderSummary_ShowsRetriedTasks(t *testing.T) {
+	data := SummaryData{
+		Done:         1,
+		Total:        1,
+		RetriedTasks: []TaskAttempts{{Name: "flaky download", Attempts: 3}},
+	}
+
+	assert.Contains(t, RenderSummary(data, 60), "flaky download")
+	assert.Contains(t, RenderFailedSummary(data, 60), "flaky download")
+	assert.NotContains(t, RenderSummary(SummaryData{Total: 1}, 60), "Retried Tasks")
+}
+
 func TestRenderSlowestTasks_OnlyOne(t *testing.T) {
 	tasks := []TaskTiming{
 		{Name: "single task", Duration: 30 * time.Second},
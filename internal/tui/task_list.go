@@ -2,6 +2,7 @@ package tui
 
 import (
 	"booster/internal/executor"
+	"booster/internal/redact"
 	"booster/internal/task"
 	"strings"
 
@@ -23,6 +24,7 @@ type TaskListModel struct {
 	width       int
 	height      int
 	compactMode bool
+	redactor    *redact.Redactor
 }
 
 func NewTaskList(exec *executor.Executor) *TaskListModel {
@@ -156,22 +158,40 @@ func (t *TaskListModel) renderTaskLines() string {
 			switch result.Status {
 			case task.StatusDone:
 				suffix := formatElapsedCompact(result.Duration)
-				taskLine := renderTaskWithLeader(prefix+"✓ ", tsk.Name(), suffix, t.width)
+				taskLine := renderTaskWithLeader(prefix+"✓ ", t.redactor.String(tsk.Name()), suffix, t.width)
 				line = doneStyle.Render(taskLine)
 			case task.StatusSkipped:
 				label := "exists"
-				if strings.HasPrefix(result.Message, "condition not met:") {
+				switch {
+				case strings.HasPrefix(result.Message, "condition not met:"),
+					strings.HasPrefix(result.Message, "dependency failed:"):
 					label = "skipped"
+				case result.Message == "completed in previous run":
+					label = "resumed"
+				case result.Message == task.MessageFilteredByTag:
+					label = "filtered"
 				}
-				taskLine := renderTaskWithLeader(prefix+"○ ", tsk.Name(), label, t.width)
+				taskLine := renderTaskWithLeader(prefix+"○ ", t.redactor.String(tsk.Name()), label, t.width)
 				line = skippedStyle.Render(taskLine)
 			case task.StatusFailed:
-				line = failedStyle.Render(prefix + "✗ " + tsk.Name())
+				if t.exec.ErrorIgnored(i) {
+					taskLine := renderTaskWithLeader(prefix+"✗ ", t.redactor.String(tsk.Name()), "ignored", t.width)
+					line = failedStyle.Render(taskLine)
+				} else {
+					line = failedStyle.Render(prefix + "✗ " + t.redactor.String(tsk.Name()))
+				}
+			case task.StatusTimedOut, task.StatusCancelled:
+				label := result.Status.String()
+				if t.exec.ErrorIgnored(i) {
+					label += ", ignored"
+				}
+				taskLine := renderTaskWithLeader(prefix+"✗ ", t.redactor.String(tsk.Name()), label, t.width)
+				line = failedStyle.Render(taskLine)
 			}
-		} else if i == current && !stopped {
-			line = runningStyle.Render(prefix + "→ " + tsk.Name() + " " + t.spinner.View())
+		} else if t.exec.IsRunning(i) || (i == current && !stopped) {
+			line = runningStyle.Render(prefix + "→ " + t.redactor.String(tsk.Name()) + " " + t.spinner.View())
 		} else {
-			line = pendingStyle.Render(prefix + "  " + tsk.Name())
+			line = pendingStyle.Render(prefix + "  " + t.redactor.String(tsk.Name()))
 		}
 
 		if isSelected && !t.compactMode {
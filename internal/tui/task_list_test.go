@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"booster/internal/config"
 	"booster/internal/executor"
 	"booster/internal/task"
 	"context"
@@ -366,3 +367,61 @@ func selectionIsOnTask(view, taskName string) bool {
 	}
 	return false
 }
+
+func TestTaskListModel_View_SkippedAndIgnoredLabels(t *testing.T) {
+	builder := task.NewBuilder().Register("mock", func(args any) ([]task.Task, error) {
+		m := args.(map[string]any)
+		return []task.Task{newMockTaskWithMessage(m["name"].(string), m["status"].(task.Status), m["message"].(string))}, nil
+	})
+	g, err := builder.BuildGraph([]config.Task{
+		{Action: "mock", Args: map[string]any{"name": "flaky_task", "status": task.StatusFailed, "message": ""}, IgnoreErrors: true},
+		{Action: "mock", Args: map[string]any{"name": "blocked_task", "status": task.StatusSkipped, "message": "dependency failed: x"}},
+		{Action: "mock", Args: map[string]any{"name": "exists_task", "status": task.StatusSkipped, "message": "already exists"}},
+		{Action: "mock", Args: map[string]any{"name": "tagged_task", "status": task.StatusSkipped, "message": task.MessageFilteredByTag}},
+	})
+	require.NoError(t, err)
+
+	exec := executor.NewGraph(g)
+	for range 4 {
+		exec.RunNext(context.Background())
+	}
+
+	model := NewTaskList(exec)
+	model.SetSize(60, 15)
+
+	for line := range strings.SplitSeq(model.View(), "\n") {
+		switch {
+		case strings.Contains(line, "flaky_task"):
+			assert.Contains(t, line, "ignored")
+		case strings.Contains(line, "blocked_task"):
+			assert.Contains(t, line, "skipped")
+		case strings.Contains(line, "exists_task"):
+			assert.Contains(t, line, "exists")
+		case strings.Contains(line, "tagged_task"):
+			assert.Contains(t, line, "filtered")
+		}
+	}
+}
+
+func TestTaskListModel_View_TimedOutAndCancelledLabels(t *testing.T) {
+	tasks := []task.Task{
+		newMockTaskWithMessage("slow_task", task.StatusTimedOut, ""),
+		newMockTaskWithMessage("stopped_task", task.StatusCancelled, ""),
+	}
+	exec := executor.New(tasks, executor.WithKeepGoing(true))
+	for range 2 {
+		exec.RunNext(context.Background())
+	}
+
+	model := NewTaskList(exec)
+	model.SetSize(60, 15)
+
+	for line := range strings.SplitSeq(model.View(), "\n") {
+		switch {
+		case strings.Contains(line, "slow_task"):
+			assert.Contains(t, line, "timed out")
+		case strings.Contains(line, "stopped_task"):
+			assert.Contains(t, line, "cancelled")
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"booster/internal/coordinator"
 	"booster/internal/executor"
 	"booster/internal/logstream"
+	"booster/internal/redact"
 	"booster/internal/task"
 	"context"
 	"fmt"
@@ -51,22 +52,33 @@ type Model struct {
 	outputViewport viewport.Model
 	logViewport    viewport.Model
 
-	logCh        <-chan string
-	logWriter    *logstream.ChannelWriter
+	logChs       map[int]<-chan string
 	focusedPanel FocusPanel
 
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	redactor *redact.Redactor
+
 	debugFile *os.File
 }
 
 func New(tasks []task.Task) Model {
-	exec := executor.New(tasks)
+	return NewWithExecutor(executor.New(tasks))
+}
+
+func NewWithExecutor(exec *executor.Executor) Model {
 	tl := NewTaskList(exec)
 	tl.SetCompactMode(true)
 	tl.SetSize(80, exec.Total())
 
+	ctx, cancel := context.WithCancel(context.Background())
 	m := Model{
+		ctx:             ctx,
+		cancel:          cancel,
 		exec:            exec,
 		coord:           coordinator.New(),
+		logChs:          make(map[int]<-chan string),
 		showLogs:        true,
 		focusedPanel:    FocusTaskList,
 		taskList:        tl,
@@ -82,6 +94,12 @@ func New(tasks []task.Task) Model {
 	return m
 }
 
+func (m Model) WithRedactor(r *redact.Redactor) Model {
+	m.redactor = r
+	m.taskList.redactor = r
+	return m
+}
+
 func (m Model) debugLog(format string, args ...any) {
 	if m.debugFile != nil {
 		fmt.Fprintf(m.debugFile, format+"\n", args...)
@@ -89,6 +107,9 @@ func (m Model) debugLog(format string, args ...any) {
 }
 
 func (m *Model) Close() {
+	if m.cancel != nil {
+		m.cancel()
+	}
 	if m.debugFile != nil {
 		m.debugFile.Close()
 		m.debugFile = nil
@@ -108,14 +129,18 @@ func (m Model) Init() tea.Cmd {
 type startTaskMsg struct{}
 
 type taskDoneMsg struct {
+	index  int
 	result task.Result
 }
 
 type logLineMsg struct {
-	line string
+	index int
+	line  string
 }
 
-type logDoneMsg struct{}
+type logDoneMsg struct {
+	index int
+}
 
 type spinnerTickMsg struct{}
 
@@ -193,7 +218,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 
 			case "q", "ctrl+c":
-				return m, tea.Quit
+				return m, m.quit()
 
 			case "enter":
 				if m.exec.Stopped() {
@@ -205,7 +230,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg.String() {
 		case "q", "ctrl+c":
-			return m, tea.Quit
+			return m, m.quit()
 		case "enter":
 			if m.exec.Stopped() {
 				return m, tea.Quit
@@ -229,24 +254,35 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case startTaskMsg:
 
-		logWriter, logCh, cmd := m.startTask()
-		m.logWriter = logWriter
-		m.logCh = logCh
+		started := m.exec.StartReady()
+		if len(started) == 0 {
+			return m, nil
+		}
+		if m.logChs == nil {
+			m.logChs = make(map[int]<-chan string)
+		}
 
-		m.coord.StartTask(m.exec.Current())
+		cmds := make([]tea.Cmd, 0, len(started))
+		for _, idx := range started {
+			_, logCh, cmd := m.startTask(idx)
+			m.logChs[idx] = logCh
+			m.coord.StartTask(idx)
+			cmds = append(cmds, cmd)
+		}
 
 		if m.isTwoColumnRunning() {
 			m.logViewport = viewport.New(
 				m.layout.RightWidth-panelBorderWidth,
 				m.layout.Height-logPanelOverhead,
 			)
-			m.logViewport.SetContent("")
+			m.updateLogViewportForTask(m.logTaskIndex())
+			m.logViewport.GotoBottom()
 
 			taskViewportHeight := max(m.layout.Height-taskPanelOverhead, 3)
 			m.taskList.SetSize(m.layout.LeftWidth-taskPanelPadding, taskViewportHeight)
 		}
 
-		return m, cmd
+		return m, tea.Batch(cmds...)
 
 	case spinnerTickMsg:
 		_ = m.taskList.Update(msg)
@@ -258,35 +294,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case logLineMsg:
 
-		m.coord.AddLogLine(msg.line)
+		// Lines are redacted before they are wrapped, which could split
+		// a secret.
+		m.coord.AddLogLine(msg.index, m.redactor.String(msg.line))
 
-		if m.isTwoColumnRunning() {
+		if m.isTwoColumnRunning() && msg.index == m.logTaskIndex() {
 			wasAtBottom := m.logViewport.AtBottom()
-			m.logViewport.SetContent(strings.Join(m.coord.CurrentLogs(), "\n"))
+			m.logViewport.SetContent(renderLogLines(m.coord.LogsFor(msg.index)))
 			if wasAtBottom {
 				m.logViewport.GotoBottom()
 			}
 		}
 
-		return m, listenForLogs(m.logCh)
+		return m, listenForLogs(msg.index, m.logChs[msg.index])
 
 	case logDoneMsg:
 
-		if completeMsg := m.coord.LogsDone(); completeMsg != nil {
-			return m.completeTask(completeMsg.Result)
+		if completeMsg := m.coord.LogsDone(msg.index); completeMsg != nil {
+			return m.completeTask(completeMsg.TaskIndex, completeMsg.Result)
 		}
 		return m, nil
 
 	case taskDoneMsg:
 
-		if completeMsg := m.coord.TaskDone(msg.result); completeMsg != nil {
-			return m.completeTask(completeMsg.Result)
+		if completeMsg := m.coord.TaskDone(msg.index, msg.result); completeMsg != nil {
+			return m.completeTask(completeMsg.TaskIndex, completeMsg.Result)
 		}
 		return m, nil
 
 	case TaskSelectedMsg:
 		m.selectedTaskIdx = msg.Index
-		if m.exec.Stopped() {
+		if m.exec.Stopped() || m.exec.IsRunning(msg.Index) {
 			m.updateLogViewportForTask(msg.Index)
 		}
 		return m, nil
@@ -344,6 +382,8 @@ func (m Model) View() string {
 		content = m.renderSingleColumn()
 	}
 
+	content = m.redactor.String(content)
+
 	if m.width > 0 && m.height > 0 {
 		return appContainerStyle.Render(content)
 	}
@@ -379,21 +419,9 @@ func (m Model) renderSingleColumn() string {
 	s.WriteString(m.taskList.View())
 	s.WriteString("\n")
 
-	var failedTask *FailureInfo
-	for i, t := range tasks {
-		r := m.exec.ResultAt(i)
-		if r.Status == task.StatusFailed {
-			failedTask = &FailureInfo{
-				TaskName: t.Name(),
-				Error:    r.Error,
-				Output:   r.Output,
-				Duration: r.Duration,
-			}
-			break
-		}
-	}
+	failures := m.collectFailures()
 
-	currentLogs := m.coord.CurrentLogs()
+	currentLogs := m.coord.LogsFor(m.logTaskIndex())
 	if !stopped && len(currentLogs) > 0 {
 		s.WriteString("\n")
 		s.WriteString(logHeaderStyle.Render("─── logs ───"))
@@ -417,13 +445,17 @@ func (m Model) renderSingleColumn() string {
 	if stopped {
 		summary := m.exec.Summary()
 
-		if failedTask != nil {
+		if len(failures) > 0 {
 			s.WriteString("\n")
 			failWidth := m.width
 			if failWidth < 40 {
 				failWidth = 60
 			}
-			s.WriteString(RenderFailure(*failedTask, failWidth))
+			if len(failures) == 1 {
+				s.WriteString(RenderFailure(failures[0], failWidth))
+			} else {
+				s.WriteString(RenderFailureSummary(failures, failWidth))
+			}
 		}
 
 		s.WriteString("\n")
@@ -468,6 +500,28 @@ func (m Model) renderSingleColumn() string {
 	return s.String()
 }
 
+func (m Model) collectFailures() []FailureInfo {
+	var failures []FailureInfo
+	for i, t := range m.exec.Tasks() {
+		r := m.exec.ResultAt(i)
+		if !r.Status.IsFailure() {
+			continue
+		}
+		name := t.Name()
+		if m.exec.ErrorIgnored(i) {
+			name += " (ignored)"
+		}
+		failures = append(failures, FailureInfo{
+			TaskName: m.redactor.String(name),
+			Error:    m.redactor.Error(r.Error),
+			Output:   m.redactor.String(r.Output),
+			Duration: r.Duration,
+			Attempts: r.Attempts,
+		})
+	}
+	return failures
+}
+
 func (m Model) renderTwoColumn(layout Layout) string {
 	leftBorderColor := UnfocusedBorderColor
 	rightBorderColor := UnfocusedBorderColor
@@ -512,14 +566,8 @@ func (m Model) renderTwoColumn(layout Layout) string {
 		}
 
 		var taskName string
-		if m.exec.Stopped() {
-			if m.selectedTaskIdx < len(m.exec.Tasks()) {
-				taskName = m.exec.Tasks()[m.selectedTaskIdx].Name()
-			}
-		} else {
-			if m.exec.Current() < len(m.exec.Tasks()) {
-				taskName = m.exec.Tasks()[m.exec.Current()].Name()
-			}
+		if idx := m.logTaskIndex(); idx < len(m.exec.Tasks()) {
+			taskName = m.redactor.String(m.exec.Tasks()[idx].Name())
 		}
 
 		logTitle := taskName
@@ -613,18 +661,26 @@ func (m Model) isTwoColumn() bool {
 	return m.layout.IsTwoColumn()
 }
 
-func (m Model) getDisplayLogs() []string {
-	if m.exec.Stopped() {
-		return m.coord.LogsFor(m.selectedTaskIdx)
+// While tasks are running, logTaskIndex prefers the selected task if it is one
+// of them, otherwise the first running task.
+func (m Model) logTaskIndex() int {
+	if m.exec.Stopped() || m.exec.IsRunning(m.selectedTaskIdx) {
+		return m.selectedTaskIdx
 	}
+	if running := m.exec.Running(); len(running) > 0 {
+		return running[0]
+	}
+	return m.exec.Current()
+}
 
-	return m.coord.CurrentLogs()
+func (m Model) getDisplayLogs() []string {
+	return m.coord.LogsFor(m.logTaskIndex())
 }
 
 func (m *Model) updateLogViewportForTask(idx int) {
 	logs := m.coord.LogsFor(idx)
 	if len(logs) > 0 {
-		m.logViewport.SetContent(strings.Join(logs, "\n"))
+		m.logViewport.SetContent(renderLogLines(logs))
 	} else {
 		m.logViewport.SetContent("")
 	}
@@ -651,11 +707,19 @@ func (m Model) buildSummaryData() SummaryData {
 	tasks := m.exec.Tasks()
 
 	var timings []TaskTiming
+	var retried []TaskAttempts
 	for i, t := range tasks {
 		r := m.exec.ResultAt(i)
+		if r.Attempts > 1 {
+			retried = append(retried, TaskAttempts{
+				Name:     m.redactor.String(t.Name()),
+				Attempts: r.Attempts,
+				Failed:   r.Status.IsFailure(),
+			})
+		}
 		if r.Duration > 0 && r.Status == task.StatusDone {
 			timings = append(timings, TaskTiming{
-				Name:     t.Name(),
+				Name:     m.redactor.String(t.Name()),
 				Duration: r.Duration,
 			})
 		}
@@ -672,34 +736,34 @@ func (m Model) buildSummaryData() SummaryData {
 	return SummaryData{
 		Done:         summary.Done,
 		Skipped:      summary.Skipped,
-		Failed:       summary.Failed,
+		Failed:       summary.Failed + summary.Cancelled,
 		Total:        m.exec.Total(),
 		Elapsed:      m.exec.ElapsedTime(),
 		SlowestTasks: timings,
+		RetriedTasks: retried,
 	}
 }
 
-func (m Model) startTask() (*logstream.ChannelWriter, <-chan string, tea.Cmd) {
+func (m Model) startTask(idx int) (*logstream.ChannelWriter, <-chan string, tea.Cmd) {
 	logWriter, logCh := logstream.NewChannelWriter(100)
 
 	cmd := tea.Batch(
-		runTask(m.exec, logWriter),
-		listenForLogs(logCh),
+		runTask(m.runContext(), m.exec, idx, logWriter),
+		listenForLogs(idx, logCh),
 		m.taskList.SpinnerTick(),
 	)
 
 	return logWriter, logCh, cmd
 }
 
-func (m Model) completeTask(result task.Result) (Model, tea.Cmd) {
-	if result.Status == task.StatusFailed {
-		m.exec.Abort()
+func (m Model) completeTask(idx int, _ task.Result) (Model, tea.Cmd) {
+	delete(m.logChs, idx)
 
+	if m.exec.Stopped() {
 		m.initLogViewportForHistory()
 		return m, nil
 	}
-	if m.exec.Stopped() {
-		m.initLogViewportForHistory()
+	if m.exec.Aborted() {
 		return m, nil
 	}
 
@@ -714,16 +778,33 @@ func (m Model) completeTask(result task.Result) (Model, tea.Cmd) {
 	)
 }
 
-func runTask(exec *executor.Executor, logWriter *logstream.ChannelWriter) tea.Cmd {
+// quit cancels the tasks still running so their processes do not outlive the
+// UI.
+func (m Model) quit() tea.Cmd {
+	m.exec.Abort()
+	if m.cancel != nil {
+		m.cancel()
+	}
+	return tea.Quit
+}
+
+func (m Model) runContext() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+func runTask(ctx context.Context, exec *executor.Executor, idx int, logWriter *logstream.ChannelWriter) tea.Cmd {
 	return func() tea.Msg {
-		ctx := logstream.WithWriter(context.Background(), logWriter)
-		result, _ := exec.RunNext(ctx)
+		ctx := logstream.WithWriter(ctx, logWriter)
+		result := exec.Run(ctx, idx)
 		logWriter.Close()
-		return taskDoneMsg{result: result}
+		return taskDoneMsg{index: idx, result: result}
 	}
 }
 
-func listenForLogs(ch <-chan string) tea.Cmd {
+func listenForLogs(idx int, ch <-chan string) tea.Cmd {
 	if ch == nil {
 		return nil
 	}
@@ -731,9 +812,9 @@ func listenForLogs(ch <-chan string) tea.Cmd {
 	return func() tea.Msg {
 		line, ok := <-ch
 		if !ok {
-			return logDoneMsg{}
+			return logDoneMsg{index: idx}
 		}
-		return logLineMsg{line: line}
+		return logLineMsg{index: idx, line: line}
 	}
 }
 
@@ -754,9 +835,9 @@ func (m Model) createOutputViewport() viewport.Model {
 		r := m.exec.ResultAt(i)
 		if r.Output != "" {
 			content.WriteString("\n")
-			content.WriteString(outputTaskStyle.Render(t.Name()))
+			content.WriteString(outputTaskStyle.Render(m.redactor.String(t.Name())))
 			content.WriteString("\n")
-			content.WriteString(outputContentStyle.Render(strings.TrimSpace(r.Output)))
+			content.WriteString(outputContentStyle.Render(m.redactor.String(strings.TrimSpace(r.Output))))
 			content.WriteString("\n")
 		}
 	}
@@ -778,13 +859,7 @@ func (m Model) createOutputViewport() viewport.Model {
 func (m Model) renderEmptyLogContent() string {
 	var s strings.Builder
 
-	var taskIdx int
-	if m.exec.Stopped() {
-		taskIdx = m.selectedTaskIdx
-	} else {
-		taskIdx = m.exec.Current()
-	}
-
+	taskIdx := m.logTaskIndex()
 	if taskIdx >= len(m.exec.Tasks()) {
 		return "Waiting for output..."
 	}
@@ -792,7 +867,7 @@ func (m Model) renderEmptyLogContent() string {
 	t := m.exec.Tasks()[taskIdx]
 	result := m.exec.ResultAt(taskIdx)
 
-	s.WriteString(lipgloss.NewStyle().Bold(true).Render(t.Name()))
+	s.WriteString(lipgloss.NewStyle().Bold(true).Render(m.redactor.String(t.Name())))
 	s.WriteString("\n\n")
 
 	switch result.Status {
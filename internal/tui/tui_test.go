@@ -4,6 +4,7 @@ import (
 	"booster/internal/coordinator"
 	"booster/internal/executor"
 	"booster/internal/logstream"
+	"booster/internal/redact"
 	"booster/internal/task"
 	"context"
 	"errors"
@@ -55,13 +56,13 @@ func newMockTaskWithMessage(name string, status task.Status, message string) *mo
 	}
 }
 
-func completeTaskViaMessages(t *testing.T, model Model, result task.Result) Model {
+func completeTaskViaMessages(t *testing.T, model Model, idx int, result task.Result) Model {
 	t.Helper()
 
-	newModel, _ := model.Update(logDoneMsg{})
+	newModel, _ := model.Update(logDoneMsg{index: idx})
 	model = newModel.(Model)
 
-	newModel, _ = model.Update(taskDoneMsg{result: result})
+	newModel, _ = model.Update(taskDoneMsg{index: idx, result: result})
 	return newModel.(Model)
 }
 
@@ -218,6 +219,38 @@ func TestUpdate_KeyHandling(t *testing.T) {
 	})
 }
 
+type blockingTask struct {
+	name string
+}
+
+func (b *blockingTask) Name() string    { return b.name }
+func (b *blockingTask) NeedsSudo() bool { return false }
+func (b *blockingTask) Run(ctx context.Context) task.Result {
+	<-ctx.Done()
+	return task.Result{Status: task.StatusFailed, Error: ctx.Err()}
+}
+
+func TestUpdate_QuitCancelsRunningTasks(t *testing.T) {
+	model := New([]task.Task{
+		&blockingTask{name: "slow"},
+		newMockTask("next", task.StatusDone, "", nil),
+	})
+	require.Equal(t, []int{0}, model.exec.StartReady())
+
+	logWriter, _ := logstream.NewChannelWriter(10)
+	done := make(chan tea.Msg)
+	go func() { done <- runTask(model.runContext(), model.exec, 0, logWriter)() }()
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+
+	msg := (<-done).(taskDoneMsg)
+	assert.Equal(t, task.StatusCancelled, msg.result.Status)
+	assert.True(t, model.exec.Aborted(), "quitting should stop scheduling new tasks")
+	assert.Equal(t, task.StatusPending, model.exec.ResultAt(1).Status)
+}
+
 func TestUpdate_TaskDoneMsg(t *testing.T) {
 	tasks := []task.Task{
 		newMockTask("task1", task.StatusDone, "", nil),
@@ -271,7 +304,7 @@ func TestUpdate_TaskFailure_StopsExecution(t *testing.T) {
 
 	model.coord.StartTask(1)
 
-	model = completeTaskViaMessages(t, model, result2)
+	model = completeTaskViaMessages(t, model, 1, result2)
 
 	assert.True(t, model.exec.Stopped(), "Executor should be stopped")
 	assert.False(t, model.exec.Done(), "Should NOT be done (task3 didn't run)")
@@ -663,23 +696,88 @@ func TestStartTask(t *testing.T) {
 		newMockTask("task1", task.StatusDone, "output", nil),
 	}
 	model := New(tasks)
+	require.Equal(t, []int{0}, model.exec.StartReady())
 
-	logWriter, logCh, cmd := model.startTask()
+	logWriter, logCh, cmd := model.startTask(0)
 	require.NotNil(t, logWriter, "startTask should return a logWriter")
 	require.NotNil(t, logCh, "startTask should return a logCh")
 	require.NotNil(t, cmd, "startTask should return a command")
 
-	taskCmd := runTask(model.exec, logWriter)
+	taskCmd := runTask(context.Background(), model.exec, 0, logWriter)
 	msg := taskCmd()
 
 	taskMsg, ok := msg.(taskDoneMsg)
 	require.True(t, ok, "Should return taskDoneMsg")
+	assert.Equal(t, 0, taskMsg.index)
 	assert.Equal(t, task.StatusDone, taskMsg.result.Status, "Result should have done status")
 
 	_, ok = <-logCh
 	assert.False(t, ok, "Log channel should be closed after task")
 }
 
+func TestStartTaskMsg_StartsIndependentTasksInParallel(t *testing.T) {
+	tasks := []task.Task{
+		newMockTask("task1", task.StatusDone, "", nil),
+		newMockTask("task2", task.StatusDone, "", nil),
+		newMockTask("task3", task.StatusDone, "", nil),
+	}
+	model := NewWithExecutor(executor.New(tasks, executor.WithJobs(2)))
+
+	newModel, cmd := model.Update(startTaskMsg{})
+	model = newModel.(Model)
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, []int{0, 1}, model.exec.Running())
+	assert.Equal(t, []int{0, 1}, model.coord.Active())
+
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 log"})
+	model = newModel.(Model)
+	newModel, _ = model.Update(logLineMsg{index: 0, line: "task1 log"})
+	model = newModel.(Model)
+
+	assert.Equal(t, []string{"task1 log"}, model.coord.LogsFor(0))
+	assert.Equal(t, []string{"task2 log"}, model.coord.LogsFor(1))
+
+	result := model.exec.Run(context.Background(), 1)
+	model = completeTaskViaMessages(t, model, 1, result)
+
+	assert.Equal(t, []int{0}, model.coord.Active())
+	assert.False(t, model.exec.Stopped())
+
+	newModel, _ = model.Update(startTaskMsg{})
+	model = newModel.(Model)
+
+	assert.Equal(t, []int{0, 2}, model.exec.Running())
+}
+
+func TestCompleteTask_FailureWaitsForRunningTasks(t *testing.T) {
+	tasks := []task.Task{
+		newMockTask("task1", task.StatusFailed, "", errors.New("failure")),
+		newMockTask("task2", task.StatusDone, "", nil),
+		newMockTask("task3", task.StatusDone, "", nil),
+	}
+	model := NewWithExecutor(executor.New(tasks, executor.WithJobs(2)))
+
+	newModel, _ := model.Update(startTaskMsg{})
+	model = newModel.(Model)
+	require.Equal(t, []int{0, 1}, model.exec.Running())
+
+	result := model.exec.Run(context.Background(), 0)
+	model = completeTaskViaMessages(t, model, 0, result)
+
+	assert.False(t, model.exec.Stopped(), "should wait for task2 to finish")
+
+	newModel, _ = model.Update(startTaskMsg{})
+	model = newModel.(Model)
+	assert.Equal(t, []int{1}, model.exec.Running(), "no new tasks start after a failure")
+
+	result = model.exec.Run(context.Background(), 1)
+	model = completeTaskViaMessages(t, model, 1, result)
+
+	assert.True(t, model.exec.Stopped())
+	assert.Equal(t, task.StatusPending, model.exec.ResultAt(2).Status)
+}
+
 func TestIntegration_FullTaskFlow(t *testing.T) {
 	tasks := []task.Task{
 		newMockTask("task1", task.StatusDone, "output1", nil),
@@ -696,21 +794,21 @@ func TestIntegration_FullTaskFlow(t *testing.T) {
 	assert.Equal(t, task.StatusDone, result1.Status)
 
 	model.coord.StartTask(0)
-	model = completeTaskViaMessages(t, model, result1)
+	model = completeTaskViaMessages(t, model, 0, result1)
 
 	result2, ok := model.exec.RunNext(context.Background())
 	require.True(t, ok, "Task2 should run")
 	assert.Equal(t, task.StatusSkipped, result2.Status)
 
 	model.coord.StartTask(1)
-	model = completeTaskViaMessages(t, model, result2)
+	model = completeTaskViaMessages(t, model, 1, result2)
 
 	result3, ok := model.exec.RunNext(context.Background())
 	require.True(t, ok, "Task3 should run")
 	assert.Equal(t, task.StatusFailed, result3.Status)
 
 	model.coord.StartTask(2)
-	model = completeTaskViaMessages(t, model, result3)
+	model = completeTaskViaMessages(t, model, 2, result3)
 
 	assert.True(t, model.exec.Stopped(), "Executor should be stopped")
 
@@ -721,6 +819,49 @@ func TestIntegration_FullTaskFlow(t *testing.T) {
 	assert.Contains(t, view, "✗ task3", "Should show failed task")
 }
 
+func TestView_KeepGoingListsAllFailures(t *testing.T) {
+	tasks := []task.Task{
+		newMockTask("first failure", task.StatusFailed, "", errors.New("boom one")),
+		newMockTask("ok task", task.StatusDone, "", nil),
+		newMockTask("second failure", task.StatusFailed, "", errors.New("boom two")),
+	}
+	model := NewWithExecutor(executor.New(tasks, executor.WithKeepGoing(true)))
+
+	for range tasks {
+		_, _ = model.exec.RunNext(context.Background())
+	}
+	require.True(t, model.exec.Stopped())
+
+	view := model.View()
+
+	assert.Contains(t, view, "FAILURES (2)")
+	assert.Contains(t, view, "first failure")
+	assert.Contains(t, view, "boom one")
+	assert.Contains(t, view, "second failure")
+	assert.Contains(t, view, "boom two")
+	assert.Contains(t, view, "BOOSTER FAILED")
+}
+
+func TestView_WithRedactorMasksSecrets(t *testing.T) {
+	tasks := []task.Task{
+		newMockTask("login as alice:hunter2", task.StatusFailed, "sent hunter2", errors.New("hunter2 rejected")),
+	}
+	model := NewWithExecutor(executor.New(tasks)).WithRedactor(redact.New("hunter2"))
+
+	newModel, _ := model.Update(logLineMsg{index: 0, line: "using hunter2"})
+	model = newModel.(Model)
+	_, _ = model.exec.RunNext(context.Background())
+
+	view := model.View()
+
+	assert.NotContains(t, view, "hunter2")
+	assert.Contains(t, view, "alice:"+redact.Mask)
+	assert.Contains(t, view, redact.Mask+" rejected")
+	for _, line := range model.coord.LogsFor(0) {
+		assert.NotContains(t, line, "hunter2")
+	}
+}
+
 func TestView_MultipleTasksWithDifferentStatuses(t *testing.T) {
 	tasks := []task.Task{
 		newMockTask("done task", task.StatusDone, "", nil),
@@ -730,7 +871,7 @@ func TestView_MultipleTasksWithDifferentStatuses(t *testing.T) {
 		newMockTask("pending task 1", task.StatusDone, "", nil),
 		newMockTask("pending task 2", task.StatusDone, "", nil),
 	}
-	model := New(tasks)
+	model := NewWithExecutor(executor.New(tasks, executor.WithKeepGoing(true)))
 
 	_, _ = model.exec.RunNext(context.Background())
 	_, _ = model.exec.RunNext(context.Background())
@@ -797,12 +938,14 @@ func TestLogStreaming_Integration(t *testing.T) {
 
 	model := New([]task.Task{streamTask})
 
-	logWriter, logCh, cmd := model.startTask()
+	require.Equal(t, []int{0}, model.exec.StartReady())
+
+	logWriter, logCh, cmd := model.startTask(0)
 	require.NotNil(t, logWriter, "logWriter should be returned")
 	require.NotNil(t, logCh, "logCh should be returned")
 	require.NotNil(t, cmd, "startTask should return a command")
 
-	taskCmd := runTask(model.exec, logWriter)
+	taskCmd := runTask(context.Background(), model.exec, 0, logWriter)
 	taskMsg := taskCmd()
 
 	var receivedLines []string
@@ -831,24 +974,23 @@ func TestLogStreaming_IntegrationWithModelUpdate(t *testing.T) {
 	require.True(t, ok, "Task should run")
 	require.Equal(t, task.StatusDone, result.Status)
 
-	logWriter, logCh, cmd := model.startTask()
-	model.logWriter = logWriter
-	model.logCh = logCh
+	_, logCh, cmd := model.startTask(0)
+	model.logChs[0] = logCh
 	require.NotNil(t, cmd, "startTask should return a command")
 
 	newModel, cmd := model.Update(logLineMsg{line: "log line 1"})
 	model, ok2 := newModel.(Model)
 	require.True(t, ok2, "newModel should be Model type")
-	assert.Len(t, model.coord.CurrentLogs(), 1, "Should have 1 log line")
-	assert.Equal(t, "log line 1", model.coord.CurrentLogs()[0], "Should contain first log line")
+	assert.Len(t, model.coord.LogsFor(0), 1, "Should have 1 log line")
+	assert.Equal(t, "log line 1", model.coord.LogsFor(0)[0], "Should contain first log line")
 	assert.NotNil(t, cmd, "Should return listenForLogs command")
 
 	newModel, cmd = model.Update(logLineMsg{line: "log line 2"})
 	model, ok2 = newModel.(Model)
 	require.True(t, ok2, "newModel should be Model type")
-	assert.Len(t, model.coord.CurrentLogs(), 2, "Should have 2 log lines")
-	assert.Equal(t, "log line 1", model.coord.CurrentLogs()[0], "Should contain first log line")
-	assert.Equal(t, "log line 2", model.coord.CurrentLogs()[1], "Should contain second log line")
+	assert.Len(t, model.coord.LogsFor(0), 2, "Should have 2 log lines")
+	assert.Equal(t, "log line 1", model.coord.LogsFor(0)[0], "Should contain first log line")
+	assert.Equal(t, "log line 2", model.coord.LogsFor(0)[1], "Should contain second log line")
 	assert.NotNil(t, cmd, "Should return listenForLogs command")
 
 	newModel, cmd = model.Update(logDoneMsg{})
@@ -859,7 +1001,7 @@ func TestLogStreaming_IntegrationWithModelUpdate(t *testing.T) {
 	newModel, _ = model.Update(taskDoneMsg{result: result})
 	model, ok2 = newModel.(Model)
 	require.True(t, ok2, "newModel should be Model type")
-	assert.Empty(t, model.coord.CurrentLogs(), "Current log lines should be cleared after task completion")
+	assert.Empty(t, model.coord.Active(), "no task should be active")
 	assert.Len(t, model.coord.LogsFor(0), 2, "Log lines should be moved to history")
 }
 
@@ -872,7 +1014,7 @@ func TestLogStreaming_MaxLinesLimit(t *testing.T) {
 		model = newModel.(Model)
 	}
 
-	assert.Len(t, model.coord.CurrentLogs(), maxLogLines+5, "currentLogs should contain all lines")
+	assert.Len(t, model.coord.LogsFor(0), maxLogLines+5, "currentLogs should contain all lines")
 
 	view := model.View()
 
@@ -899,16 +1041,16 @@ func TestLogHistory_Persistence(t *testing.T) {
 	newModel, _ = model.Update(logLineMsg{line: "task1 log line 2"})
 	model = newModel.(Model)
 
-	assert.Len(t, model.coord.CurrentLogs(), 2, "currentLogs should have 2 lines")
-	assert.Equal(t, "task1 log line 1", model.coord.CurrentLogs()[0])
-	assert.Equal(t, "task1 log line 2", model.coord.CurrentLogs()[1])
+	assert.Len(t, model.coord.LogsFor(0), 2, "currentLogs should have 2 lines")
+	assert.Equal(t, "task1 log line 1", model.coord.LogsFor(0)[0])
+	assert.Equal(t, "task1 log line 2", model.coord.LogsFor(0)[1])
 
 	newModel, _ = model.Update(logDoneMsg{})
 	model = newModel.(Model)
 	newModel, _ = model.Update(taskDoneMsg{result: task.Result{Status: task.StatusDone}})
 	model = newModel.(Model)
 
-	assert.Empty(t, model.coord.CurrentLogs(), "currentLogs should be cleared after task completion")
+	assert.Empty(t, model.coord.Active(), "no task should be active")
 	assert.Len(t, model.coord.LogsFor(0), 2, "logHistory[0] should have 2 lines")
 	assert.Equal(t, "task1 log line 1", model.coord.LogsFor(0)[0])
 	assert.Equal(t, "task1 log line 2", model.coord.LogsFor(0)[1])
@@ -918,16 +1060,16 @@ func TestLogHistory_Persistence(t *testing.T) {
 
 	model.coord.StartTask(1)
 
-	newModel, _ = model.Update(logLineMsg{line: "task2 log line 1"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 log line 1"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logLineMsg{line: "task2 log line 2"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 log line 2"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logLineMsg{line: "task2 log line 3"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 log line 3"})
 	model = newModel.(Model)
 
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 1})
 	model = newModel.(Model)
-	newModel, _ = model.Update(taskDoneMsg{result: task.Result{Status: task.StatusDone}})
+	newModel, _ = model.Update(taskDoneMsg{index: 1, result: task.Result{Status: task.StatusDone}})
 	model = newModel.(Model)
 
 	assert.Len(t, model.coord.LogsFor(0), 2, "logHistory[0] should still have 2 lines")
@@ -941,9 +1083,9 @@ func TestLogHistory_Persistence(t *testing.T) {
 
 	model.coord.StartTask(2)
 
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 2})
 	model = newModel.(Model)
-	newModel, _ = model.Update(taskDoneMsg{result: task.Result{Status: task.StatusDone}})
+	newModel, _ = model.Update(taskDoneMsg{index: 2, result: task.Result{Status: task.StatusDone}})
 	model = newModel.(Model)
 
 	assert.Nil(t, model.coord.LogsFor(2), "logHistory[2] should be nil for task with no logs")
@@ -972,13 +1114,13 @@ func TestLogHistory_FailedTask(t *testing.T) {
 	_, _ = model.exec.RunNext(context.Background())
 
 	model.coord.StartTask(1)
-	newModel, _ = model.Update(logLineMsg{line: "task2 log 1"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 log 1"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logLineMsg{line: "task2 log 2"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 log 2"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 1})
 	model = newModel.(Model)
-	newModel, _ = model.Update(taskDoneMsg{result: task.Result{Status: task.StatusFailed}})
+	newModel, _ = model.Update(taskDoneMsg{index: 1, result: task.Result{Status: task.StatusFailed}})
 	model = newModel.(Model)
 
 	assert.Len(t, model.coord.LogsFor(0), 1, "logHistory[0] should have 1 line")
@@ -1011,17 +1153,17 @@ func TestLogTaskCoordination_TaskDoneBeforeLogDone(t *testing.T) {
 	model = newModel.(Model)
 
 	assert.Nil(t, cmd, "Should not return command until logs are done")
-	assert.Len(t, model.coord.CurrentLogs(), 2, "currentLogs should still have lines")
+	assert.Len(t, model.coord.LogsFor(0), 2, "currentLogs should still have lines")
 
 	newModel, _ = model.Update(logLineMsg{line: "log line 3"})
 	model = newModel.(Model)
-	assert.Len(t, model.coord.CurrentLogs(), 3, "currentLogs should have 3 lines now")
+	assert.Len(t, model.coord.LogsFor(0), 3, "currentLogs should have 3 lines now")
 
 	newModel, cmd = model.Update(logDoneMsg{})
 	model = newModel.(Model)
 
 	assert.NotNil(t, cmd, "Should return command to start next task")
-	assert.Empty(t, model.coord.CurrentLogs(), "currentLogs should be cleared")
+	assert.Empty(t, model.coord.Active(), "no task should be active")
 	assert.Len(t, model.coord.LogsFor(0), 3, "All 3 log lines should be in history")
 	assert.Equal(t, "log line 1", model.coord.LogsFor(0)[0])
 	assert.Equal(t, "log line 2", model.coord.LogsFor(0)[1])
@@ -1051,7 +1193,7 @@ func TestLogTaskCoordination_LogDoneBeforeTaskDone(t *testing.T) {
 	model = newModel.(Model)
 
 	assert.NotNil(t, cmd, "Should return command to start next task")
-	assert.Empty(t, model.coord.CurrentLogs(), "currentLogs should be cleared")
+	assert.Empty(t, model.coord.Active(), "no task should be active")
 	assert.Len(t, model.coord.LogsFor(0), 1, "Log should be in history")
 }
 
@@ -1196,7 +1338,7 @@ func TestShowLogs_PanelShowsPlaceholderWhenEmpty(t *testing.T) {
 
 	model.logViewport = viewport.New(model.layout.RightWidth-panelBorderWidth, model.layout.Height-logPanelOverhead)
 
-	assert.Empty(t, model.coord.CurrentLogs(), "currentLogs should be empty")
+	assert.Empty(t, model.coord.Active(), "no task should be active")
 	assert.Nil(t, model.coord.LogsFor(0), "logHistory should be empty")
 
 	view := model.View()
@@ -1232,15 +1374,15 @@ func TestShowLogs_DisplaysHistoryWhenStopped(t *testing.T) {
 	_, _ = model.exec.RunNext(context.Background())
 
 	model.coord.StartTask(1)
-	newModel, _ = model.Update(logLineMsg{line: "task2 log line 1"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 log line 1"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logLineMsg{line: "task2 log line 2"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 log line 2"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logLineMsg{line: "task2 log line 3"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 log line 3"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 1})
 	model = newModel.(Model)
-	newModel, _ = model.Update(taskDoneMsg{result: task.Result{Status: task.StatusDone}})
+	newModel, _ = model.Update(taskDoneMsg{index: 1, result: task.Result{Status: task.StatusDone}})
 	model = newModel.(Model)
 
 	model.taskList.Update(SetSelectionMsg{Index: 0})
@@ -1322,19 +1464,19 @@ func TestShowLogs_GetDisplayLogs(t *testing.T) {
 	_, _ = model.exec.RunNext(context.Background())
 
 	model.coord.StartTask(1)
-	newModel, _ = model.Update(logLineMsg{line: "task2 log"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 log"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 1})
 	model = newModel.(Model)
-	newModel, _ = model.Update(taskDoneMsg{result: task.Result{Status: task.StatusDone}})
+	newModel, _ = model.Update(taskDoneMsg{index: 1, result: task.Result{Status: task.StatusDone}})
 	model = newModel.(Model)
 
 	_, _ = model.exec.RunNext(context.Background())
 
 	model.coord.StartTask(2)
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 2})
 	model = newModel.(Model)
-	newModel, _ = model.Update(taskDoneMsg{result: task.Result{Status: task.StatusDone}})
+	newModel, _ = model.Update(taskDoneMsg{index: 2, result: task.Result{Status: task.StatusDone}})
 	model = newModel.(Model)
 
 	model.taskList.Update(SetSelectionMsg{Index: 0})
@@ -1620,23 +1762,23 @@ func TestIntegration_TaskSelectedMsgUpdatesLogViewport(t *testing.T) {
 	_, _ = model.exec.RunNext(context.Background())
 
 	model.coord.StartTask(1)
-	newModel, _ = model.Update(logLineMsg{line: "task2 output line A"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 output line A"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logLineMsg{line: "task2 output line B"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 output line B"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logLineMsg{line: "task2 output line C"})
+	newModel, _ = model.Update(logLineMsg{index: 1, line: "task2 output line C"})
 	model = newModel.(Model)
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 1})
 	model = newModel.(Model)
-	newModel, _ = model.Update(taskDoneMsg{result: task.Result{Status: task.StatusDone}})
+	newModel, _ = model.Update(taskDoneMsg{index: 1, result: task.Result{Status: task.StatusDone}})
 	model = newModel.(Model)
 
 	_, _ = model.exec.RunNext(context.Background())
 
 	model.coord.StartTask(2)
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 2})
 	model = newModel.(Model)
-	newModel, _ = model.Update(taskDoneMsg{result: task.Result{Status: task.StatusDone}})
+	newModel, _ = model.Update(taskDoneMsg{index: 2, result: task.Result{Status: task.StatusDone}})
 	model = newModel.(Model)
 
 	model.initLogViewportForHistory()
@@ -1692,27 +1834,27 @@ func TestIntegration_SelectionAutoAdvancesOnTaskComplete(t *testing.T) {
 	newModel, _ := model.Update(logDoneMsg{})
 	model = newModel.(Model)
 
-	model, _ = model.completeTask(task.Result{Status: task.StatusDone})
+	model, _ = model.completeTask(0, task.Result{Status: task.StatusDone})
 
 	assert.Equal(t, 1, model.taskList.Selected(), "selection should auto-advance to 1 after task1 completes")
 
 	_, _ = model.exec.RunNext(context.Background())
 
 	model.coord.StartTask(1)
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 1})
 	model = newModel.(Model)
 
-	model, _ = model.completeTask(task.Result{Status: task.StatusDone})
+	model, _ = model.completeTask(1, task.Result{Status: task.StatusDone})
 
 	assert.Equal(t, 2, model.taskList.Selected(), "selection should auto-advance to 2 after task2 completes")
 
 	_, _ = model.exec.RunNext(context.Background())
 
 	model.coord.StartTask(2)
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 2})
 	model = newModel.(Model)
 
-	model, _ = model.completeTask(task.Result{Status: task.StatusDone})
+	model, _ = model.completeTask(2, task.Result{Status: task.StatusDone})
 
 	assert.Equal(t, 2, model.taskList.Selected(), "selection should stay at 2 when at last task")
 }
@@ -1733,16 +1875,16 @@ func TestIntegration_SelectionNotAdvancedOnFailure(t *testing.T) {
 	model.coord.StartTask(0)
 	newModel, _ := model.Update(logDoneMsg{})
 	model = newModel.(Model)
-	model, _ = model.completeTask(task.Result{Status: task.StatusDone})
+	model, _ = model.completeTask(0, task.Result{Status: task.StatusDone})
 
 	assert.Equal(t, 1, model.taskList.Selected(), "selection should be at 1 after task1")
 
 	_, _ = model.exec.RunNext(context.Background())
 
 	model.coord.StartTask(1)
-	newModel, _ = model.Update(logDoneMsg{})
+	newModel, _ = model.Update(logDoneMsg{index: 1})
 	model = newModel.(Model)
-	model, _ = model.completeTask(task.Result{Status: task.StatusFailed, Error: errors.New("failure")})
+	model, _ = model.completeTask(1, task.Result{Status: task.StatusFailed, Error: errors.New("failure")})
 
 	assert.True(t, model.exec.Stopped(), "executor should be stopped after failure")
 	assert.Equal(t, 1, model.taskList.Selected(), "selection should stay at 1 on failure (no auto-advance)")
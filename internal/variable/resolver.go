@@ -1,17 +1,25 @@
 package variable
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"strings"
 )
 
+// A missing or empty value returned by Collect stands for the default.
 type PromptCollector interface {
-	Collect(defs []Definition) (map[string]string, error)
+	Collect(defs []Definition) (map[string]any, error)
 }
 
 type Resolver struct {
 	store     *FileStore
+	secrets   *SecretStore
+	legacy    *FileStore
 	collector PromptCollector
 	envLookup func(string) string
+	values    map[string]any
 }
 
 type ResolverOption func(*Resolver)
@@ -22,12 +30,37 @@ func WithEnvLookup(fn func(string) string) ResolverOption {
 	}
 }
 
+// Without a collector, variables with no value take their defaults, and
+// Resolve fails with a MissingError for those that have none, along with the
+// values it did resolve.
 func WithCollector(c PromptCollector) ResolverOption {
 	return func(r *Resolver) {
 		r.collector = c
 	}
 }
 
+// Values given for the run take precedence over the environment and the
+// stores, and are not stored.
+func WithValues(values map[string]any) ResolverOption {
+	return func(r *Resolver) {
+		r.values = values
+	}
+}
+
+func WithSecretStore(s *SecretStore) ResolverOption {
+	return func(r *Resolver) {
+		r.secrets = s
+	}
+}
+
+// WithLegacyStore migrates values from the store shared by every config
+// before values were kept per config.
+func WithLegacyStore(legacy *FileStore) ResolverOption {
+	return func(r *Resolver) {
+		r.legacy = legacy
+	}
+}
+
 func NewResolver(store *FileStore, opts ...ResolverOption) *Resolver {
 	r := &Resolver{
 		store:     store,
@@ -39,27 +72,45 @@ func NewResolver(store *FileStore, opts ...ResolverOption) *Resolver {
 	return r
 }
 
-func (r *Resolver) Resolve(defs []Definition) (map[string]string, error) {
+// Resolve takes a value from the values given for the run, else the
+// environment, else the stores, else the collector. Stored values that are no
+// longer valid for the definition are prompted for again.
+func (r *Resolver) Resolve(defs []Definition) (map[string]any, error) {
 	if len(defs) == 0 {
-		return make(map[string]string), nil
+		return make(map[string]any), nil
 	}
 
-	result := make(map[string]string)
-
-	stored, err := r.store.Load()
+	stored, err := r.load(defs)
 	if err != nil {
 		return nil, err
 	}
 
+	result := make(map[string]any)
 	var needsPrompt []Definition
 
 	for _, def := range defs {
-		if val := r.envLookup(def.Name); val != "" {
+		if raw, ok := r.values[def.Name]; ok {
+			val, err := def.Convert(raw)
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: %w", def.Name, err)
+			}
+			result[def.Name] = val
+			continue
+		}
+		if raw := r.envLookup(def.Name); raw != "" {
+			val, err := def.Convert(raw)
+			if err != nil {
+				return nil, fmt.Errorf("variable %s from environment: %w", def.Name, err)
+			}
 			result[def.Name] = val
 			continue
 		}
 
-		if val, ok := stored[def.Name]; ok {
+		raw, ok, err := stored.get(def)
+		if err != nil {
+			return nil, err
+		}
+		if val, err := def.Convert(raw); ok && err == nil {
 			result[def.Name] = val
 			continue
 		}
@@ -68,25 +119,371 @@ func (r *Resolver) Resolve(defs []Definition) (map[string]string, error) {
 	}
 
 	if len(needsPrompt) > 0 && r.collector != nil {
-		prompted, err := r.collector.Collect(needsPrompt)
-		if err != nil {
+		if err := r.collect(needsPrompt, stored, result); err != nil {
 			return nil, err
 		}
-
+	} else if len(needsPrompt) > 0 {
+		var missing []string
 		for _, def := range needsPrompt {
-			val := prompted[def.Name]
-			if val == "" && def.Default != "" {
-				val = def.Default
+			if def.Default == nil {
+				missing = append(missing, def.Name)
+				continue
+			}
+			val, err := def.DefaultValue()
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: %w", def.Name, err)
 			}
 			result[def.Name] = val
+		}
+		if len(missing) > 0 {
+			return result, &MissingError{Names: missing}
+		}
+	}
+
+	if err := stored.save(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type MissingError struct {
+	Names []string
+}
+
+func (e *MissingError) Error() string {
+	return "no value for " + strings.Join(e.Names, ", ")
+}
+
+type Source string
+
+const (
+	SourceEnv     Source = "env"
+	SourceStore   Source = "store"
+	SourceDefault Source = "default"
+	SourceNone    Source = "none"
+)
+
+type Value struct {
+	Definition
+	Value  any
+	Source Source
+}
 
-			stored[def.Name] = val
+// Lookup returns what Resolve would, without prompting; a variable with no
+// value but a default has the default.
+func (r *Resolver) Lookup(defs []Definition) ([]Value, error) {
+	stored, err := r.load(defs)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]Value, 0, len(defs))
+	for _, def := range defs {
+		v := Value{Definition: def, Source: SourceNone}
+		if env := r.envLookup(def.Name); env != "" {
+			val, err := def.Convert(env)
+			if err != nil {
+				return nil, fmt.Errorf("variable %s from environment: %w", def.Name, err)
+			}
+			v.Value, v.Source = val, SourceEnv
+			values = append(values, v)
+			continue
 		}
 
-		if err := r.store.Save(stored); err != nil {
+		raw, ok, err := stored.get(def)
+		if err != nil {
 			return nil, err
 		}
+		if val, err := def.Convert(raw); ok && err == nil {
+			v.Value, v.Source = val, SourceStore
+		} else if def.Default != nil {
+			val, err := def.DefaultValue()
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: %w", def.Name, err)
+			}
+			v.Value, v.Source = val, SourceDefault
+		}
+		values = append(values, v)
 	}
+	return values, stored.save()
+}
 
-	return result, nil
+func (r *Resolver) Set(def Definition, raw any) (any, error) {
+	if def.Secret && r.secrets == nil {
+		return nil, fmt.Errorf("variable %s is secret, but there is no secret store", def.Name)
+	}
+	stored, err := r.load([]Definition{def})
+	if err != nil {
+		return nil, err
+	}
+	val, err := def.Convert(raw)
+	if err != nil {
+		return nil, fmt.Errorf("variable %s: %w", def.Name, err)
+	}
+	if err := stored.set(def, val); err != nil {
+		return nil, err
+	}
+	return val, stored.save()
+}
+
+func (r *Resolver) Unset(def Definition) (bool, error) {
+	stored, err := r.load([]Definition{def})
+	if err != nil {
+		return false, err
+	}
+	removed, err := stored.unset(def)
+	if err != nil {
+		return false, err
+	}
+	return removed, stored.save()
+}
+
+func (r *Resolver) Reprompt(defs []Definition) (map[string]any, error) {
+	if r.collector == nil {
+		return nil, errors.New("cannot prompt for variables")
+	}
+	stored, err := r.load(defs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]any)
+	if err := r.collect(defs, stored, result); err != nil {
+		return nil, err
+	}
+	return result, stored.save()
+}
+
+func (r *Resolver) load(defs []Definition) (*storedValues, error) {
+	for _, def := range defs {
+		if err := def.Check(); err != nil {
+			return nil, fmt.Errorf("variable %s: %w", def.Name, err)
+		}
+	}
+
+	if err := r.migrate(defs); err != nil {
+		return nil, err
+	}
+
+	plain, err := r.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &storedValues{
+		store:   r.store,
+		plain:   plain,
+		secrets: &secretValues{store: r.secrets},
+	}, nil
+}
+
+func (r *Resolver) collect(defs []Definition, stored *storedValues, result map[string]any) error {
+	prompted, err := r.collector.Collect(defs)
+	if err != nil {
+		return err
+	}
+
+	for _, def := range defs {
+		raw := prompted[def.Name]
+		if s, ok := raw.(string); raw == nil || ok && s == "" && def.Default != nil {
+			raw, err = def.DefaultValue()
+			if err != nil {
+				return fmt.Errorf("variable %s: %w", def.Name, err)
+			}
+		}
+		val, err := def.Convert(raw)
+		if err != nil {
+			return fmt.Errorf("variable %s: %w", def.Name, err)
+		}
+		result[def.Name] = val
+		if err := stored.set(def, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrate leaves the values of other variables in the legacy store, as they
+// may belong to another config.
+func (r *Resolver) migrate(defs []Definition) error {
+	if r.legacy == nil || len(defs) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(r.store.Path()); !errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if _, err := os.Stat(r.legacy.Path()); err != nil {
+		return nil
+	}
+
+	old, err := r.legacy.Load()
+	if err != nil {
+		return fmt.Errorf("migrate values from %s: %w", r.legacy.Path(), err)
+	}
+	values := make(map[string]any)
+	secrets := make(map[string]any)
+	for _, def := range defs {
+		v, ok := old[def.Name]
+		switch {
+		case !ok:
+		case def.Secret:
+			secrets[def.Name] = v
+		default:
+			values[def.Name] = v
+		}
+	}
+	if len(values) == 0 && len(secrets) == 0 {
+		return nil
+	}
+
+	// Secrets never go into the plaintext store, and are left behind if
+	// there is no secret store to move them to.
+	if len(secrets) > 0 && r.secrets != nil {
+		stored, err := r.secrets.Load()
+		if err != nil {
+			return err
+		}
+		for name, v := range secrets {
+			if _, ok := stored[name]; !ok {
+				stored[name] = v
+			}
+		}
+		if err := r.secrets.Save(stored); err != nil {
+			return fmt.Errorf("migrate values to %s: %w", r.secrets.Path(), err)
+		}
+	}
+	// The store is created even if it holds no values, so the migration is
+	// not repeated.
+	if err := r.store.Save(values); err != nil {
+		return fmt.Errorf("migrate values to %s: %w", r.store.Path(), err)
+	}
+	return nil
+}
+
+type storedValues struct {
+	store   *FileStore
+	plain   map[string]any
+	changed bool
+	secrets *secretValues
+}
+
+// A secret value found in the plaintext store, from before the variable was
+// secret, is moved to the secret store.
+func (s *storedValues) get(def Definition) (any, bool, error) {
+	if !def.Secret {
+		v, ok := s.plain[def.Name]
+		return v, ok, nil
+	}
+
+	v, ok, err := s.secrets.get(def.Name)
+	if err != nil {
+		return nil, false, err
+	}
+	if plain, inStore := s.plain[def.Name]; inStore {
+		delete(s.plain, def.Name)
+		s.changed = true
+		if !ok {
+			v, ok = plain, true
+			s.secrets.set(def.Name, plain)
+		}
+	}
+	return v, ok, nil
+}
+
+func (s *storedValues) set(def Definition, value any) error {
+	if !def.Secret {
+		s.plain[def.Name] = value
+		s.changed = true
+		return nil
+	}
+	if _, _, err := s.secrets.get(def.Name); err != nil {
+		return err
+	}
+	if _, ok := s.plain[def.Name]; ok {
+		delete(s.plain, def.Name)
+		s.changed = true
+	}
+	s.secrets.set(def.Name, value)
+	return nil
+}
+
+func (s *storedValues) unset(def Definition) (bool, error) {
+	_, removed := s.plain[def.Name]
+	if removed {
+		delete(s.plain, def.Name)
+		s.changed = true
+	}
+	if def.Secret {
+		_, ok, err := s.secrets.get(def.Name)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			s.secrets.unset(def.Name)
+			removed = true
+		}
+	}
+	return removed, nil
+}
+
+// Secrets are saved first, so a value moved out of the plaintext store is not
+// lost if they cannot be.
+func (s *storedValues) save() error {
+	if err := s.secrets.save(); err != nil {
+		return err
+	}
+	if !s.changed {
+		return nil
+	}
+	if err := s.store.Save(s.plain); err != nil {
+		return err
+	}
+	s.changed = false
+	return nil
+}
+
+// secretValues loads the secret store the first time a secret is needed, so
+// the key is only asked for when there are secrets.
+type secretValues struct {
+	store   *SecretStore
+	values  map[string]any
+	changed bool
+}
+
+func (s *secretValues) get(name string) (any, bool, error) {
+	if s.store == nil {
+		return nil, false, nil
+	}
+	if s.values == nil {
+		values, err := s.store.Load()
+		if err != nil {
+			return nil, false, err
+		}
+		s.values = values
+	}
+	v, ok := s.values[name]
+	return v, ok, nil
+}
+
+func (s *secretValues) set(name string, value any) {
+	if s.store == nil {
+		return
+	}
+	s.values[name] = value
+	s.changed = true
+}
+
+func (s *secretValues) unset(name string) {
+	delete(s.values, name)
+	s.changed = true
+}
+
+func (s *secretValues) save() error {
+	if !s.changed {
+		return nil
+	}
+	if err := s.store.Save(s.values); err != nil {
+		return err
+	}
+	s.changed = false
+	return nil
 }
@@ -10,12 +10,12 @@ import (
 )
 
 type mockCollector struct {
-	values map[string]string
+	values map[string]any
 	err    error
 	called bool
 }
 
-func (m *mockCollector) Collect(defs []Definition) (map[string]string, error) {
+func (m *mockCollector) Collect(defs []Definition) (map[string]any, error) {
 	m.called = true
 	if m.err != nil {
 		return nil, m.err
@@ -26,70 +26,70 @@ func (m *mockCollector) Collect(defs []Definition) (map[string]string, error) {
 func TestResolver_Resolve(t *testing.T) {
 	tests := []struct {
 		name          string
-		storedValues  map[string]string
+		storedValues  map[string]any
 		envLookup     func(string) string
-		collectorVals map[string]string
+		collectorVals map[string]any
 		collectorErr  error
 		defs          []Definition
-		wantResolved  map[string]string
+		wantResolved  map[string]any
 		wantPrompted  bool
-		wantSaved     map[string]string
+		wantSaved     map[string]any
 		wantErr       string
 	}{
 		{
 			name:         "env takes precedence over stored and prompted values",
-			storedValues: map[string]string{"Name": "stored-value"},
+			storedValues: map[string]any{"Name": "stored-value"},
 			envLookup: func(key string) string {
 				if key == "Name" {
 					return "env-value"
 				}
 				return ""
 			},
-			collectorVals: map[string]string{"Name": "prompted-value"},
+			collectorVals: map[string]any{"Name": "prompted-value"},
 			defs:          []Definition{{Name: "Name", Prompt: "Your name"}},
-			wantResolved:  map[string]string{"Name": "env-value"},
+			wantResolved:  map[string]any{"Name": "env-value"},
 			wantPrompted:  false,
-			wantSaved:     map[string]string{"Name": "stored-value"},
+			wantSaved:     map[string]any{"Name": "stored-value"},
 		},
 		{
 			name:          "uses stored value when no env var is set",
-			storedValues:  map[string]string{"Name": "stored-value"},
+			storedValues:  map[string]any{"Name": "stored-value"},
 			envLookup:     func(key string) string { return "" },
-			collectorVals: map[string]string{"Name": "prompted-value"},
+			collectorVals: map[string]any{"Name": "prompted-value"},
 			defs:          []Definition{{Name: "Name", Prompt: "Your name"}},
-			wantResolved:  map[string]string{"Name": "stored-value"},
+			wantResolved:  map[string]any{"Name": "stored-value"},
 			wantPrompted:  false,
-			wantSaved:     map[string]string{"Name": "stored-value"},
+			wantSaved:     map[string]any{"Name": "stored-value"},
 		},
 		{
 			name:          "prompts when value is missing from env and store",
 			storedValues:  nil,
 			envLookup:     func(key string) string { return "" },
-			collectorVals: map[string]string{"Name": "prompted-value"},
+			collectorVals: map[string]any{"Name": "prompted-value"},
 			defs:          []Definition{{Name: "Name", Prompt: "Your name"}},
-			wantResolved:  map[string]string{"Name": "prompted-value"},
+			wantResolved:  map[string]any{"Name": "prompted-value"},
 			wantPrompted:  true,
-			wantSaved:     map[string]string{"Name": "prompted-value"},
+			wantSaved:     map[string]any{"Name": "prompted-value"},
 		},
 		{
 			name:          "applies default when collector returns empty string",
 			storedValues:  nil,
 			envLookup:     func(key string) string { return "" },
-			collectorVals: map[string]string{"Email": ""},
+			collectorVals: map[string]any{"Email": ""},
 			defs:          []Definition{{Name: "Email", Prompt: "Your email", Default: "default@example.com"}},
-			wantResolved:  map[string]string{"Email": "default@example.com"},
+			wantResolved:  map[string]any{"Email": "default@example.com"},
 			wantPrompted:  true,
-			wantSaved:     map[string]string{"Email": "default@example.com"},
+			wantSaved:     map[string]any{"Email": "default@example.com"},
 		},
 		{
 			name:          "saves prompted values to store",
 			storedValues:  nil,
 			envLookup:     func(key string) string { return "" },
-			collectorVals: map[string]string{"Name": "Alice"},
+			collectorVals: map[string]any{"Name": "Alice"},
 			defs:          []Definition{{Name: "Name", Prompt: "Your name"}},
-			wantResolved:  map[string]string{"Name": "Alice"},
+			wantResolved:  map[string]any{"Name": "Alice"},
 			wantPrompted:  true,
-			wantSaved:     map[string]string{"Name": "Alice"},
+			wantSaved:     map[string]any{"Name": "Alice"},
 		},
 		{
 			name:         "does not save env values to store",
@@ -100,11 +100,11 @@ func TestResolver_Resolve(t *testing.T) {
 				}
 				return ""
 			},
-			collectorVals: map[string]string{},
+			collectorVals: map[string]any{},
 			defs:          []Definition{{Name: "Name", Prompt: "Your name"}},
-			wantResolved:  map[string]string{"Name": "from-env"},
+			wantResolved:  map[string]any{"Name": "from-env"},
 			wantPrompted:  false,
-			wantSaved:     map[string]string{},
+			wantSaved:     map[string]any{},
 		},
 		{
 			name:          "returns error when collector fails",
@@ -119,11 +119,11 @@ func TestResolver_Resolve(t *testing.T) {
 			name:          "handles empty definitions without error",
 			storedValues:  nil,
 			envLookup:     func(key string) string { return "" },
-			collectorVals: map[string]string{},
+			collectorVals: map[string]any{},
 			defs:          nil,
-			wantResolved:  map[string]string{},
+			wantResolved:  map[string]any{},
 			wantPrompted:  false,
-			wantSaved:     map[string]string{},
+			wantSaved:     map[string]any{},
 		},
 	}
 
@@ -166,9 +166,9 @@ func TestResolver_MultipleVariables(t *testing.T) {
 	dir := t.TempDir()
 	store := NewFileStore(filepath.Join(dir, "values.yaml"))
 
-	require.NoError(t, store.Save(map[string]string{"Email": "stored@example.com"}))
+	require.NoError(t, store.Save(map[string]any{"Email": "stored@example.com"}))
 
-	collector := &mockCollector{values: map[string]string{"Editor": "vim"}}
+	collector := &mockCollector{values: map[string]any{"Editor": "vim"}}
 
 	resolver := NewResolver(store,
 		WithEnvLookup(func(key string) string {
@@ -192,3 +192,403 @@ func TestResolver_MultipleVariables(t *testing.T) {
 	assert.Equal(t, "stored@example.com", resolved["Email"])
 	assert.Equal(t, "vim", resolved["Editor"])
 }
+
+func TestResolver_TypedValues(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(filepath.Join(dir, "values.yaml"))
+	// Stored before the variables had types.
+	require.NoError(t, store.Save(map[string]any{"Workers": "4", "Shell": "bash"}))
+
+	collector := &mockCollector{values: map[string]any{"Shell": "fish", "Langs": []any{"go"}}}
+	resolver := NewResolver(store,
+		WithEnvLookup(func(key string) string {
+			if key == "Debug" {
+				return "true"
+			}
+			return ""
+		}),
+		WithCollector(collector),
+	)
+
+	defs := []Definition{
+		{Name: "Debug", Type: TypeBool},
+		{Name: "Workers", Type: TypeInt},
+		{Name: "Shell", Type: TypeChoice, Options: []string{"zsh", "fish"}},
+		{Name: "Langs", Type: TypeList},
+		{Name: "Editor", Type: TypeChoice, Options: []string{"vim", "emacs"}, Default: "vim"},
+	}
+	resolved, err := resolver.Resolve(defs)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"Debug":   true,
+		"Workers": 4,
+		"Shell":   "fish",
+		"Langs":   []any{"go"},
+		"Editor":  "vim",
+	}, resolved)
+
+	// bash is no longer a valid shell, so it was prompted for again.
+	saved, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "fish", saved["Shell"])
+	assert.Equal(t, []any{"go"}, saved["Langs"])
+	assert.Equal(t, "vim", saved["Editor"])
+}
+
+func TestResolver_InvalidValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		def       Definition
+		env       string
+		collected any
+		wantErr   string
+	}{
+		{
+			name:    "invalid definition",
+			def:     Definition{Name: "Shell", Type: TypeChoice},
+			wantErr: "variable Shell: choice needs options",
+		},
+		{
+			name:    "invalid environment value",
+			def:     Definition{Name: "Workers", Type: TypeInt},
+			env:     "lots",
+			wantErr: `variable Workers from environment: "lots" is not an int`,
+		},
+		{
+			name:      "invalid collected value",
+			def:       Definition{Name: "Workers", Type: TypeInt, Max: intPtr(8)},
+			collected: "9",
+			wantErr:   "variable Workers: must be at most 8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewFileStore(filepath.Join(t.TempDir(), "values.yaml"))
+			resolver := NewResolver(store,
+				WithEnvLookup(func(string) string { return tt.env }),
+				WithCollector(&mockCollector{values: map[string]any{tt.def.Name: tt.collected}}),
+			)
+
+			_, err := resolver.Resolve([]Definition{tt.def})
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestResolver_SecretsAreStoredEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(filepath.Join(dir, "values.yaml"))
+	// Token was stored in plaintext before it was made secret.
+	require.NoError(t, store.Save(map[string]any{"Token": "old-token", "Name": "Alice"}))
+	secrets := NewSecretStore(filepath.Join(dir, "secrets.age"), testPassphraseKey("pass"))
+
+	collector := &mockCollector{values: map[string]any{"APIKey": "hunter2"}}
+	resolver := NewResolver(store,
+		WithEnvLookup(func(string) string { return "" }),
+		WithCollector(collector),
+		WithSecretStore(secrets),
+	)
+
+	defs := []Definition{
+		{Name: "Name"},
+		{Name: "Token", Secret: true},
+		{Name: "APIKey", Secret: true},
+	}
+	resolved, err := resolver.Resolve(defs)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Name": "Alice", "Token": "old-token", "APIKey": "hunter2"}, resolved)
+
+	plain, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Name": "Alice"}, plain)
+
+	encrypted, err := secrets.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Token": "old-token", "APIKey": "hunter2"}, encrypted)
+
+	// The next run reads both secrets without prompting.
+	collector.called = false
+	resolved, err = resolver.Resolve(defs)
+	require.NoError(t, err)
+	assert.False(t, collector.called)
+	assert.Equal(t, "hunter2", resolved["APIKey"])
+}
+
+func TestResolver_SecretsWithoutSecretStoreAreNotKept(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "values.yaml"))
+	collector := &mockCollector{values: map[string]any{"APIKey": "hunter2"}}
+	resolver := NewResolver(store,
+		WithEnvLookup(func(string) string { return "" }),
+		WithCollector(collector),
+	)
+
+	resolved, err := resolver.Resolve([]Definition{{Name: "APIKey", Secret: true}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", resolved["APIKey"])
+	plain, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, plain)
+}
+
+func TestResolver_MigratesLegacyStore(t *testing.T) {
+	dir := t.TempDir()
+	legacy := NewFileStore(filepath.Join(dir, "values.yaml"))
+	require.NoError(t, legacy.Save(map[string]any{"Name": "Alice", "Other": "x", "Token": "plain"}))
+
+	store := NewFileStore(filepath.Join(dir, "values", "dotfiles.yaml"))
+	secrets := NewSecretStore(filepath.Join(dir, "secrets", "dotfiles.age"), testPassphraseKey("pass"))
+	collector := &mockCollector{}
+	resolver := NewResolver(store,
+		WithEnvLookup(func(string) string { return "" }),
+		WithCollector(collector),
+		WithSecretStore(secrets),
+		WithLegacyStore(legacy),
+	)
+
+	resolved, err := resolver.Resolve([]Definition{
+		{Name: "Name"},
+		{Name: "Token", Secret: true},
+	})
+
+	require.NoError(t, err)
+	assert.False(t, collector.called)
+	assert.Equal(t, map[string]any{"Name": "Alice", "Token": "plain"}, resolved)
+
+	plain, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Name": "Alice"}, plain, "only this config's variables are migrated")
+	encrypted, err := secrets.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Token": "plain"}, encrypted, "migrated secrets move to the secret store")
+
+	old, err := legacy.Load()
+	require.NoError(t, err)
+	assert.Len(t, old, 3, "the legacy store is left for other configs")
+}
+
+func TestResolver_MigratesSecretsOnlyToSecretStore(t *testing.T) {
+	dir := t.TempDir()
+	legacy := NewFileStore(filepath.Join(dir, "values.yaml"))
+	require.NoError(t, legacy.Save(map[string]any{"Token": "hunter2", "Key": "k3y"}))
+	store := NewFileStore(filepath.Join(dir, "values", "dotfiles.yaml"))
+	secrets := NewSecretStore(filepath.Join(dir, "secrets", "dotfiles.age"), testPassphraseKey("pass"))
+	resolver := NewResolver(store,
+		WithEnvLookup(func(name string) string {
+			if name == "Token" {
+				return "from-env"
+			}
+			return ""
+		}),
+		WithValues(map[string]any{"Key": "from-set"}),
+		WithSecretStore(secrets),
+		WithLegacyStore(legacy),
+	)
+
+	resolved, err := resolver.Resolve([]Definition{{Name: "Token", Secret: true}, {Name: "Key", Secret: true}})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Token": "from-env", "Key": "from-set"}, resolved)
+	plain, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, plain, "secrets are never written in plaintext")
+	encrypted, err := secrets.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Token": "hunter2", "Key": "k3y"}, encrypted)
+}
+
+func TestResolver_MigratesOnlyOnFirstUse(t *testing.T) {
+	dir := t.TempDir()
+	legacy := NewFileStore(filepath.Join(dir, "values.yaml"))
+	require.NoError(t, legacy.Save(map[string]any{"Name": "Alice", "Email": "alice@example.com"}))
+	store := NewFileStore(filepath.Join(dir, "values", "dotfiles.yaml"))
+	require.NoError(t, store.Save(map[string]any{"Name": "Bob"}))
+
+	collector := &mockCollector{values: map[string]any{"Email": "bob@example.com"}}
+	resolver := NewResolver(store,
+		WithEnvLookup(func(string) string { return "" }),
+		WithCollector(collector),
+		WithLegacyStore(legacy),
+	)
+
+	resolved, err := resolver.Resolve([]Definition{{Name: "Name"}, {Name: "Email"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Name": "Bob", "Email": "bob@example.com"}, resolved)
+}
+
+func TestResolver_Lookup(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(filepath.Join(dir, "values.yaml"))
+	require.NoError(t, store.Save(map[string]any{"Name": "Alice", "Shell": "bash", "Port": "many"}))
+	secrets := NewSecretStore(filepath.Join(dir, "secrets.age"), testPassphraseKey("pass"))
+	require.NoError(t, secrets.Save(map[string]any{"Token": "hunter2"}))
+
+	collector := &mockCollector{}
+	resolver := NewResolver(store,
+		WithEnvLookup(func(name string) string {
+			if name == "Shell" {
+				return "zsh"
+			}
+			return ""
+		}),
+		WithCollector(collector),
+		WithSecretStore(secrets),
+	)
+
+	values, err := resolver.Lookup([]Definition{
+		{Name: "Name"},
+		{Name: "Shell"},
+		{Name: "Token", Secret: true},
+		{Name: "Port", Type: TypeInt, Default: "22"},
+		{Name: "Email"},
+	})
+
+	require.NoError(t, err)
+	assert.False(t, collector.called)
+	got := make(map[string][2]any)
+	for _, v := range values {
+		got[v.Name] = [2]any{v.Value, v.Source}
+	}
+	assert.Equal(t, map[string][2]any{
+		"Name":  {"Alice", SourceStore},
+		"Shell": {"zsh", SourceEnv},
+		"Token": {"hunter2", SourceStore},
+		"Port":  {22, SourceDefault},
+		"Email": {nil, SourceNone},
+	}, got)
+}
+
+func TestResolver_Lookup_EnvSkipsSecretStore(t *testing.T) {
+	dir := t.TempDir()
+	secrets := NewSecretStore(filepath.Join(dir, "secrets.age"), testPassphraseKey("pass"))
+	require.NoError(t, secrets.Save(map[string]any{"Token": "hunter2"}))
+
+	asked := false
+	key := NewPassphraseKey(func() (string, error) {
+		asked = true
+		return "pass", nil
+	})
+	resolver := NewResolver(NewFileStore(filepath.Join(dir, "values.yaml")),
+		WithEnvLookup(func(string) string { return "from-env" }),
+		WithSecretStore(NewSecretStore(secrets.Path(), key)),
+	)
+
+	values, err := resolver.Lookup([]Definition{{Name: "Token", Secret: true}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", values[0].Value)
+	assert.Equal(t, SourceEnv, values[0].Source)
+	assert.False(t, asked, "the passphrase is not asked for a secret set in the environment")
+}
+
+func TestResolver_SetAndUnset(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(filepath.Join(dir, "values.yaml"))
+	require.NoError(t, store.Save(map[string]any{"Token": "plain"}))
+	secrets := NewSecretStore(filepath.Join(dir, "secrets.age"), testPassphraseKey("pass"))
+	resolver := NewResolver(store, WithSecretStore(secrets))
+
+	workers := Definition{Name: "Workers", Type: TypeInt, Max: intPtr(8)}
+	token := Definition{Name: "Token", Secret: true}
+
+	val, err := resolver.Set(workers, "4")
+	require.NoError(t, err)
+	assert.Equal(t, 4, val)
+	_, err = resolver.Set(workers, "9")
+	assert.EqualError(t, err, "variable Workers: must be at most 8")
+	_, err = resolver.Set(token, "hunter2")
+	require.NoError(t, err)
+
+	plain, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Workers": 4}, plain, "the secret is no longer kept in plaintext")
+	encrypted, err := secrets.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Token": "hunter2"}, encrypted)
+
+	removed, err := resolver.Unset(token)
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = resolver.Unset(token)
+	require.NoError(t, err)
+	assert.False(t, removed)
+	encrypted, err = secrets.Load()
+	require.NoError(t, err)
+	assert.Empty(t, encrypted)
+
+	_, err = NewResolver(store).Set(token, "x")
+	assert.EqualError(t, err, "variable Token is secret, but there is no secret store")
+}
+
+func TestResolver_Reprompt(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "values.yaml"))
+	require.NoError(t, store.Save(map[string]any{"Name": "Alice", "Email": "alice@example.com"}))
+	collector := &mockCollector{values: map[string]any{"Name": "Bob"}}
+	resolver := NewResolver(store,
+		WithEnvLookup(func(string) string { return "Env" }),
+		WithCollector(collector),
+	)
+
+	values, err := resolver.Reprompt([]Definition{{Name: "Name"}})
+
+	require.NoError(t, err)
+	assert.True(t, collector.called, "stored and environment values are asked for again")
+	assert.Equal(t, map[string]any{"Name": "Bob"}, values)
+	stored, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Name": "Bob", "Email": "alice@example.com"}, stored)
+
+	_, err = NewResolver(store).Reprompt([]Definition{{Name: "Name"}})
+	assert.EqualError(t, err, "cannot prompt for variables")
+}
+
+func TestResolver_WithValues(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "values.yaml"))
+	require.NoError(t, store.Save(map[string]any{"Name": "Alice"}))
+	resolver := NewResolver(store,
+		WithEnvLookup(func(string) string { return "Env" }),
+		WithValues(map[string]any{"Name": "Bob", "Workers": "4", "Unused": "x"}),
+	)
+
+	resolved, err := resolver.Resolve([]Definition{{Name: "Name"}, {Name: "Workers", Type: TypeInt}, {Name: "Shell"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Name": "Bob", "Workers": 4, "Shell": "Env"}, resolved)
+	stored, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Name": "Alice"}, stored, "values for the run are not stored")
+
+	_, err = resolver.Resolve([]Definition{{Name: "Workers", Type: TypeBool}})
+	assert.EqualError(t, err, `variable Workers: "4" is not a bool`)
+}
+
+func TestResolver_WithoutCollectorListsMissingValues(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "values.yaml"))
+	require.NoError(t, store.Save(map[string]any{"Port": "many"}))
+	resolver := NewResolver(store, WithEnvLookup(func(string) string { return "" }))
+
+	resolved, err := resolver.Resolve([]Definition{{Name: "Editor", Default: "vim"}})
+	require.NoError(t, err)
+	assert.Equal(t, "vim", resolved["Editor"], "defaults are used without prompting")
+	stored, err := store.Load()
+	require.NoError(t, err)
+	assert.NotContains(t, stored, "Editor")
+
+	resolved, err = resolver.Resolve([]Definition{
+		{Name: "Name"},
+		{Name: "Editor", Default: "vim"},
+		{Name: "Port", Type: TypeInt},
+		{Name: "Email"},
+	})
+
+	var missing *MissingError
+	require.ErrorAs(t, err, &missing)
+	assert.Equal(t, []string{"Name", "Port", "Email"}, missing.Names)
+	assert.EqualError(t, err, "no value for Name, Port, Email")
+	assert.Equal(t, map[string]any{"Editor": "vim"}, resolved, "resolved values come with the error")
+}
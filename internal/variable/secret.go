@@ -0,0 +1,184 @@
+package variable
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"gopkg.in/yaml.v3"
+)
+
+type SecretKey interface {
+	Recipient() (age.Recipient, error)
+	Identity() (age.Identity, error)
+}
+
+// PassphraseKey asks for the passphrase the first time the key is used.
+type PassphraseKey struct {
+	ask        func() (string, error)
+	passphrase string
+	// Zero means age's default; tests lower it.
+	workFactor int
+}
+
+func NewPassphraseKey(ask func() (string, error)) *PassphraseKey {
+	return &PassphraseKey{ask: ask}
+}
+
+func (k *PassphraseKey) get() (string, error) {
+	if k.passphrase != "" {
+		return k.passphrase, nil
+	}
+	passphrase, err := k.ask()
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("passphrase cannot be empty")
+	}
+	k.passphrase = passphrase
+	return passphrase, nil
+}
+
+func (k *PassphraseKey) Recipient() (age.Recipient, error) {
+	passphrase, err := k.get()
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if k.workFactor > 0 {
+		r.SetWorkFactor(k.workFactor)
+	}
+	return r, nil
+}
+
+func (k *PassphraseKey) Identity() (age.Identity, error) {
+	passphrase, err := k.get()
+	if err != nil {
+		return nil, err
+	}
+	return age.NewScryptIdentity(passphrase)
+}
+
+// IdentityKey encrypts to the first X25519 identity in the file.
+type IdentityKey struct {
+	path string
+}
+
+func NewIdentityKey(path string) *IdentityKey {
+	return &IdentityKey{path: path}
+}
+
+func (k *IdentityKey) identity() (*age.X25519Identity, error) {
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return nil, fmt.Errorf("read identity: %w", err)
+	}
+	ids, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse identity %s: %w", k.path, err)
+	}
+	for _, id := range ids {
+		if x, ok := id.(*age.X25519Identity); ok {
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no X25519 identity", k.path)
+}
+
+func (k *IdentityKey) Recipient() (age.Recipient, error) {
+	id, err := k.identity()
+	if err != nil {
+		return nil, err
+	}
+	return id.Recipient(), nil
+}
+
+func (k *IdentityKey) Identity() (age.Identity, error) {
+	return k.identity()
+}
+
+// SecretStore only uses the key when the file exists or values are saved.
+type SecretStore struct {
+	path string
+	key  SecretKey
+}
+
+func NewSecretStore(path string, key SecretKey) *SecretStore {
+	return &SecretStore{path: path, key: key}
+}
+
+func (s *SecretStore) Path() string {
+	return s.path
+}
+
+func (s *SecretStore) Load() (map[string]any, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]any), nil
+		}
+		return nil, err
+	}
+
+	id, err := s.key.Identity()
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), id)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) || errors.Is(err, age.ErrIncorrectIdentity) {
+			return nil, fmt.Errorf("decrypt %s: wrong passphrase or identity", s.path)
+		}
+		return nil, fmt.Errorf("decrypt %s: %w", s.path, err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", s.path, err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(plain, &values); err != nil {
+		return nil, err
+	}
+	if values == nil {
+		return make(map[string]any), nil
+	}
+	return values, nil
+}
+
+func (s *SecretStore) Save(values map[string]any) error {
+	recipient, err := s.key.Recipient()
+	if err != nil {
+		return err
+	}
+	plain, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return fmt.Errorf("encrypt secrets: %w", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		return fmt.Errorf("encrypt secrets: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("encrypt secrets: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, buf.Bytes(), 0o600)
+}
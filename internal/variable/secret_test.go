@@ -0,0 +1,104 @@
+package variable
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPassphraseKey returns a passphrase key that is cheap to derive.
+func testPassphraseKey(passphrase string) *PassphraseKey {
+	k := NewPassphraseKey(func() (string, error) { return passphrase, nil })
+	k.workFactor = 10
+	return k
+}
+
+func TestSecretStore_PassphraseRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets", "secrets.age")
+	store := NewSecretStore(path, testPassphraseKey("correct horse"))
+
+	require.NoError(t, store.Save(map[string]any{"Token": "hunter2"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := NewSecretStore(path, testPassphraseKey("correct horse")).Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Token": "hunter2"}, loaded)
+}
+
+func TestSecretStore_WrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.age")
+	require.NoError(t, NewSecretStore(path, testPassphraseKey("right")).Save(map[string]any{"Token": "x"}))
+
+	_, err := NewSecretStore(path, testPassphraseKey("wrong")).Load()
+
+	assert.EqualError(t, err, "decrypt "+path+": wrong passphrase or identity")
+}
+
+func TestSecretStore_IdentityRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	idPath := filepath.Join(dir, "key.txt")
+	require.NoError(t, os.WriteFile(idPath, []byte("# created: today\n"+id.String()+"\n"), 0o600))
+
+	path := filepath.Join(dir, "secrets.age")
+	store := NewSecretStore(path, NewIdentityKey(idPath))
+	require.NoError(t, store.Save(map[string]any{"Token": "hunter2", "Keys": []any{"a", "b"}}))
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Token": "hunter2", "Keys": []any{"a", "b"}}, loaded)
+
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(idPath, []byte(other.String()+"\n"), 0o600))
+	_, err = store.Load()
+	assert.ErrorContains(t, err, "wrong passphrase or identity")
+}
+
+func TestSecretStore_MissingFileNeedsNoKey(t *testing.T) {
+	key := NewPassphraseKey(func() (string, error) {
+		return "", errors.New("should not be asked")
+	})
+	store := NewSecretStore(filepath.Join(t.TempDir(), "secrets.age"), key)
+
+	values, err := store.Load()
+
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func TestPassphraseKey_AsksOnce(t *testing.T) {
+	asked := 0
+	key := NewPassphraseKey(func() (string, error) {
+		asked++
+		return "pass", nil
+	})
+	key.workFactor = 10
+
+	_, err := key.Recipient()
+	require.NoError(t, err)
+	_, err = key.Identity()
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, asked)
+}
+
+func TestPassphraseKey_RejectsEmpty(t *testing.T) {
+	key := NewPassphraseKey(func() (string, error) { return "", nil })
+
+	_, err := key.Recipient()
+
+	assert.EqualError(t, err, "passphrase cannot be empty")
+}
@@ -0,0 +1,216 @@
+package variable
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type Type string
+
+const (
+	TypeString Type = "string"
+	TypeBool   Type = "bool"
+	TypeInt    Type = "int"
+	TypeChoice Type = "choice"
+	TypeList   Type = "list"
+)
+
+var Types = []Type{TypeString, TypeBool, TypeInt, TypeChoice, TypeList}
+
+func (d Definition) Kind() Type {
+	if d.Type == "" {
+		return TypeString
+	}
+	return d.Type
+}
+
+func (d Definition) Check() error {
+	kind := d.Kind()
+	if !slices.Contains(Types, kind) {
+		return fmt.Errorf("unknown type %q", d.Type)
+	}
+
+	switch {
+	case kind == TypeChoice && len(d.Options) == 0:
+		return errors.New("choice needs options")
+	case len(d.Options) > 0 && kind != TypeChoice && kind != TypeList:
+		return fmt.Errorf("options are not allowed for type %s", kind)
+	case d.Pattern != "" && kind != TypeString && kind != TypeList:
+		return fmt.Errorf("pattern is not allowed for type %s", kind)
+	case (d.Min != nil || d.Max != nil) && (kind == TypeBool || kind == TypeChoice):
+		return fmt.Errorf("min and max are not allowed for type %s", kind)
+	case d.Min != nil && d.Max != nil && *d.Min > *d.Max:
+		return fmt.Errorf("min %d is greater than max %d", *d.Min, *d.Max)
+	case d.Secret && (kind == TypeBool || kind == TypeInt):
+		// Masking "true" or a short number would hide it everywhere in the output.
+		return fmt.Errorf("secret is not allowed for type %s", kind)
+	}
+
+	if _, err := regexp.Compile(d.Pattern); err != nil {
+		return fmt.Errorf("pattern: %w", err)
+	}
+	if d.Default != nil {
+		if _, err := d.Convert(d.Default); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	return nil
+}
+
+// DefaultValue returns the zero value of the type if there is no default.
+func (d Definition) DefaultValue() (any, error) {
+	if d.Default != nil {
+		return d.Convert(d.Default)
+	}
+	switch d.Kind() {
+	case TypeBool:
+		return false, nil
+	case TypeInt:
+		return 0, nil
+	case TypeList:
+		return []any{}, nil
+	}
+	return "", nil
+}
+
+// Convert takes a string, as read from the environment or a prompt, or a
+// value decoded from YAML. Lists become a []any of strings; a string given
+// for a list is split on commas.
+func (d Definition) Convert(raw any) (any, error) {
+	switch d.Kind() {
+	case TypeBool:
+		return convertBool(raw)
+	case TypeInt:
+		n, err := convertInt(raw)
+		if err != nil {
+			return nil, err
+		}
+		return n, d.checkBounds(n, "")
+	case TypeList:
+		items, err := convertList(raw)
+		if err != nil {
+			return nil, err
+		}
+		list := make([]any, len(items))
+		for i, item := range items {
+			if err := d.checkString(item); err != nil {
+				return nil, fmt.Errorf("item %d: %w", i+1, err)
+			}
+			list[i] = item
+		}
+		return list, d.checkBounds(len(items), " items")
+	}
+
+	s, err := convertString(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.checkString(s); err != nil {
+		return nil, err
+	}
+	if d.Kind() == TypeString {
+		return s, d.checkBounds(utf8.RuneCountInString(s), " characters")
+	}
+	return s, nil
+}
+
+func (d Definition) checkString(s string) error {
+	if len(d.Options) > 0 && !slices.Contains(d.Options, s) {
+		return fmt.Errorf("%q is not one of %s", s, strings.Join(d.Options, ", "))
+	}
+	if d.Pattern != "" {
+		re, err := regexp.Compile(d.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern: %w", err)
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf("%q does not match pattern %s", s, d.Pattern)
+		}
+	}
+	return nil
+}
+
+func (d Definition) checkBounds(n int, unit string) error {
+	if d.Min != nil && n < *d.Min {
+		return fmt.Errorf("must be at least %d%s", *d.Min, unit)
+	}
+	if d.Max != nil && n > *d.Max {
+		return fmt.Errorf("must be at most %d%s", *d.Max, unit)
+	}
+	return nil
+}
+
+func convertString(raw any) (string, error) {
+	switch v := raw.(type) {
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		// An unquoted YAML scalar, such as a default of 8080.
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("expected a string, got %T", raw)
+}
+
+func convertBool(raw any) (bool, error) {
+	switch v := raw.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return false, fmt.Errorf("%q is not a bool", v)
+		}
+		return b, nil
+	}
+	return false, fmt.Errorf("expected a bool, got %T", raw)
+}
+
+func convertInt(raw any) (int, error) {
+	switch v := raw.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("%q is not an int", v)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("expected an int, got %T", raw)
+}
+
+func convertList(raw any) ([]string, error) {
+	switch v := raw.(type) {
+	case []string:
+		return v, nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := convertString(item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i+1, err)
+			}
+			items[i] = s
+		}
+		return items, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		items := strings.Split(v, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("expected a list, got %T", raw)
+}
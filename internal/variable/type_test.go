@@ -0,0 +1,109 @@
+package variable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestDefinition_Convert(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     Definition
+		raw     any
+		want    any
+		wantErr string
+	}{
+		{name: "untyped is a string", def: Definition{}, raw: "hello", want: "hello"},
+		{name: "string from a YAML number", def: Definition{Type: TypeString}, raw: 8080, want: "8080"},
+		{name: "string matches pattern", def: Definition{Pattern: `^[a-z]+$`}, raw: "abc", want: "abc"},
+		{name: "string breaks pattern", def: Definition{Pattern: `^[a-z]+$`}, raw: "ABC", wantErr: `"ABC" does not match pattern ^[a-z]+$`},
+		{name: "string too short", def: Definition{Min: intPtr(3)}, raw: "ab", wantErr: "must be at least 3 characters"},
+		{name: "bool from a string", def: Definition{Type: TypeBool}, raw: "true", want: true},
+		{name: "bool from YAML", def: Definition{Type: TypeBool}, raw: false, want: false},
+		{name: "bool from garbage", def: Definition{Type: TypeBool}, raw: "maybe", wantErr: `"maybe" is not a bool`},
+		{name: "int from a string", def: Definition{Type: TypeInt}, raw: " 42 ", want: 42},
+		{name: "int from YAML", def: Definition{Type: TypeInt}, raw: 7, want: 7},
+		{name: "int not a number", def: Definition{Type: TypeInt}, raw: "many", wantErr: `"many" is not an int`},
+		{name: "int above max", def: Definition{Type: TypeInt, Max: intPtr(10)}, raw: 11, wantErr: "must be at most 10"},
+		{name: "int from a list", def: Definition{Type: TypeInt}, raw: []any{1}, wantErr: "expected an int, got []interface {}"},
+		{name: "choice among options", def: Definition{Type: TypeChoice, Options: []string{"zsh", "fish"}}, raw: "fish", want: "fish"},
+		{name: "choice not an option", def: Definition{Type: TypeChoice, Options: []string{"zsh", "fish"}}, raw: "bash", wantErr: `"bash" is not one of zsh, fish`},
+		{name: "list from YAML", def: Definition{Type: TypeList}, raw: []any{"a", "b"}, want: []any{"a", "b"}},
+		{name: "list from a string", def: Definition{Type: TypeList}, raw: "a, b,c", want: []any{"a", "b", "c"}},
+		{name: "list from an empty string", def: Definition{Type: TypeList}, raw: "", want: []any{}},
+		{name: "list item not an option", def: Definition{Type: TypeList, Options: []string{"a"}}, raw: []string{"a", "b"}, wantErr: `item 2: "b" is not one of a`},
+		{name: "list too long", def: Definition{Type: TypeList, Max: intPtr(1)}, raw: "a,b", wantErr: "must be at most 1 items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.def.Convert(tt.raw)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDefinition_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     Definition
+		wantErr string
+	}{
+		{name: "plain string", def: Definition{Default: "x"}},
+		{name: "typed with valid default", def: Definition{Type: TypeInt, Default: 3, Min: intPtr(1), Max: intPtr(5)}},
+		{name: "unknown type", def: Definition{Type: "float"}, wantErr: `unknown type "float"`},
+		{name: "choice without options", def: Definition{Type: TypeChoice}, wantErr: "choice needs options"},
+		{name: "options on an int", def: Definition{Type: TypeInt, Options: []string{"1"}}, wantErr: "options are not allowed for type int"},
+		{name: "pattern on a bool", def: Definition{Type: TypeBool, Pattern: "x"}, wantErr: "pattern is not allowed for type bool"},
+		{name: "bounds on a choice", def: Definition{Type: TypeChoice, Options: []string{"a"}, Min: intPtr(1)}, wantErr: "min and max are not allowed for type choice"},
+		{name: "min above max", def: Definition{Type: TypeInt, Min: intPtr(5), Max: intPtr(1)}, wantErr: "min 5 is greater than max 1"},
+		{name: "secret int", def: Definition{Type: TypeInt, Secret: true}, wantErr: "secret is not allowed for type int"},
+		{name: "secret list", def: Definition{Type: TypeList, Secret: true}},
+		{name: "bad pattern", def: Definition{Pattern: "("}, wantErr: "pattern: error parsing regexp: missing closing ): `(`"},
+		{name: "invalid default", def: Definition{Type: TypeBool, Default: "sometimes"}, wantErr: `default: "sometimes" is not a bool`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.def.Check()
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDefinition_DefaultValue(t *testing.T) {
+	tests := []struct {
+		def  Definition
+		want any
+	}{
+		{def: Definition{}, want: ""},
+		{def: Definition{Type: TypeBool}, want: false},
+		{def: Definition{Type: TypeInt}, want: 0},
+		{def: Definition{Type: TypeList}, want: []any{}},
+		{def: Definition{Type: TypeInt, Default: "3"}, want: 3},
+		{def: Definition{Type: TypeList, Default: []any{"a"}}, want: []any{"a"}},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.def.DefaultValue()
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
@@ -10,7 +10,15 @@ import (
 type Definition struct {
 	Name    string
 	Prompt  string
-	Default string
+	Type    Type
+	Default any
+
+	Options []string
+	Pattern string
+	Min     *int
+	Max     *int
+
+	Secret bool
 }
 
 type FileStore struct {
@@ -25,27 +33,29 @@ func (s *FileStore) Path() string {
 	return s.path
 }
 
-func (s *FileStore) Load() (map[string]string, error) {
+// Values stored before variables had types are strings; Definition.Convert
+// turns them into values of the variable's type.
+func (s *FileStore) Load() (map[string]any, error) {
 	data, err := os.ReadFile(s.path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return make(map[string]string), nil
+			return make(map[string]any), nil
 		}
 		return nil, err
 	}
 
-	var values map[string]string
+	var values map[string]any
 	if err := yaml.Unmarshal(data, &values); err != nil {
 		return nil, err
 	}
 
 	if values == nil {
-		return make(map[string]string), nil
+		return make(map[string]any), nil
 	}
 	return values, nil
 }
 
-func (s *FileStore) Save(values map[string]string) error {
+func (s *FileStore) Save(values map[string]any) error {
 	dir := filepath.Dir(s.path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return err
@@ -56,5 +66,5 @@ func (s *FileStore) Save(values map[string]string) error {
 		return err
 	}
 
-	return os.WriteFile(s.path, data, 0o644)
+	return os.WriteFile(s.path, data, 0o600)
 }
@@ -25,7 +25,7 @@ func TestFileStore_Save_CreatesParentDirectory(t *testing.T) {
 	path := filepath.Join(dir, "subdir", "nested", "values.yaml")
 
 	store := NewFileStore(path)
-	err := store.Save(map[string]string{"Name": "Alice"})
+	err := store.Save(map[string]any{"Name": "Alice"})
 
 	require.NoError(t, err)
 
@@ -39,7 +39,7 @@ func TestFileStore_RoundTrip(t *testing.T) {
 
 	store := NewFileStore(path)
 
-	original := map[string]string{
+	original := map[string]any{
 		"Name":  "Alice",
 		"Email": "alice@example.com",
 	}
@@ -58,10 +58,10 @@ func TestFileStore_Save_OverwritesExisting(t *testing.T) {
 
 	store := NewFileStore(path)
 
-	err := store.Save(map[string]string{"Name": "Alice"})
+	err := store.Save(map[string]any{"Name": "Alice"})
 	require.NoError(t, err)
 
-	err = store.Save(map[string]string{"Name": "Bob", "Email": "bob@example.com"})
+	err = store.Save(map[string]any{"Name": "Bob", "Email": "bob@example.com"})
 	require.NoError(t, err)
 
 	loaded, err := store.Load()
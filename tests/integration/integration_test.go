@@ -256,7 +256,7 @@ func TestVariableResolution_ReusesStoredValues(t *testing.T) {
 
 	storePath := filepath.Join(dir, "values.yaml")
 	store := variable.NewFileStore(storePath)
-	require.NoError(t, store.Save(map[string]string{
+	require.NoError(t, store.Save(map[string]any{
 		"Name":  "Bob",
 		"Email": "bob@example.com",
 	}))